@@ -0,0 +1,30 @@
+package http
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/saitofun/qkit/kit/httptransport/httpx"
+	"github.com/saitofun/qkit/kit/kit"
+	"github.com/saitofun/qkit/kit/metrics"
+)
+
+// MetricsRouter exposes metrics.Default in prometheus text format
+var MetricsRouter = kit.NewRouter(&Metrics{})
+
+type Metrics struct{ httpx.MethodGet }
+
+func (Metrics) Path() string { return "/metrics" }
+
+func (Metrics) Output(ctx context.Context) (interface{}, error) {
+	text := &MetricsText{}
+	if _, err := metrics.Default.WriteTo(&text.Buffer); err != nil {
+		return nil, err
+	}
+	return text, nil
+}
+
+// MetricsText metrics in prometheus text format
+type MetricsText struct{ bytes.Buffer }
+
+func (MetricsText) ContentType() string { return httpx.MIME_PLAIN_TEXT + "; version=0.0.4" }
@@ -0,0 +1,21 @@
+package http_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	. "github.com/saitofun/qkit/conf/http"
+	"github.com/saitofun/qkit/kit/metrics"
+)
+
+func TestMetrics(t *testing.T) {
+	metrics.Default.Counter("test_metrics_total", "").Inc()
+
+	v, err := (&Metrics{}).Output(bgctx)
+	NewWithT(t).Expect(err).To(BeNil())
+
+	text := v.(*MetricsText)
+	NewWithT(t).Expect(text.ContentType()).To(Equal("text/plain; version=0.0.4"))
+	NewWithT(t).Expect(text.String()).To(ContainSubstring("# TYPE test_metrics_total counter\ntest_metrics_total 1\n"))
+}
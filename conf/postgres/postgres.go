@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/saitofun/qkit/base/types"
@@ -15,15 +16,29 @@ import (
 type Endpoint struct {
 	Master   types.Endpoint
 	Slave    types.Endpoint
+	Replicas []types.Endpoint
 	Database *sqlx.Database `env:"-"`
 	Retry    *retry.Retry
+	// ReplicaPolicy policy of picking replica for routing queries
+	ReplicaPolicy sqlx.ReplicaPolicy
+	// ReplicaProbeInterval interval of probing health of slave and replicas,
+	// default 10 seconds
+	ReplicaProbeInterval types.Duration
 
 	Extensions      []string
 	PoolSize        int
 	ConnMaxLifetime types.Duration
+	// SlowQueryThreshold statements cost longer are logged as slow queries,
+	// disabled if zero
+	SlowQueryThreshold types.Duration
+	// ExplainSlowQuery if plans of slow queries are logged
+	ExplainSlowQuery bool
 
 	*sqlx.DB `env:"-"`
-	slave    *sqlx.DB `env:"-"`
+	slave    *sqlx.Replica   `env:"-"`
+	routing  *sqlx.RoutingDB `env:"-"`
+	// cancel stops probing of slave and replicas
+	cancel context.CancelFunc `env:"-"`
 }
 
 var _ types.DefaultSetter = (*Endpoint)(nil)
@@ -38,12 +53,10 @@ func (e Endpoint) LivenessCheck() map[string]string {
 		s[e.Master.Host()] = "ok"
 	}
 
-	if e.slave != nil {
-		_, err := e.slave.ExecContext(context.Background(), "SELECT 1")
-		if err != nil {
-			s[e.Slave.Host()] = err.Error()
-		} else {
-			s[e.Slave.Host()] = "ok"
+	if e.routing != nil {
+		// slave and replicas are checked by routing, unhealthy ones are skipped
+		for host, status := range e.routing.LivenessCheck() {
+			s[host] = status
 		}
 	}
 
@@ -67,54 +80,129 @@ func (e *Endpoint) SetDefault() {
 	if e.Retry == nil {
 		e.Retry = retry.Default
 	}
+	if e.ReplicaProbeInterval == 0 {
+		e.ReplicaProbeInterval = types.Duration(10 * time.Second)
+	}
 }
 
+// UseSlave returns slave if it is healthy, otherwise returns master
 func (e Endpoint) UseSlave() sqlx.DBExecutor {
-	if e.slave == nil {
+	if e.slave == nil || !e.slave.Healthy() {
 		return e.DB
 	}
-	return e.slave
+	return e.slave.DB
 }
 
-func (e *Endpoint) conn(master, readonly bool) error {
-	url := e.masterURL()
-	if !master {
-		url = e.slaveURL()
+// Routing returns executor sends queries to slave and replicas, and sends
+// writes and transactions to master. see sqlx.RoutingDB
+func (e Endpoint) Routing() sqlx.DBExecutor {
+	if e.routing == nil {
+		return e.DB
 	}
+	return e.routing
+}
+
+// Listen listens channel of master, see postgres.Connector.Listen
+func (e *Endpoint) Listen(ctx context.Context, channel string) (<-chan postgres.Notification, error) {
+	connector := e.connector(e.masterURL(), true)
+	connector.DBName = e.Database.Name
+	return connector.Listen(ctx, channel)
+}
+
+// Notify sends payload to listeners of channel by master. to notify in
+// transaction, exec postgres.Notify by executor of the transaction
+func (e *Endpoint) Notify(channel, payload string) error {
+	_, err := e.DB.Exec(postgres.Notify(channel, payload))
+	return err
+}
+
+func (e *Endpoint) connector(url string, readonly bool) *postgres.Connector {
 	connector := &postgres.Connector{
-		Host:  url,
-		Extra: e.Master.Param.Encode(),
+		Host:               url,
+		Extra:              e.Master.Param.Encode(),
+		SlowQueryThreshold: e.SlowQueryThreshold.Duration(),
+		ExplainSlowQuery:   e.ExplainSlowQuery,
 	}
 	if !readonly {
 		connector.Extensions = e.Extensions
 	}
-	db := e.Database.OpenDB(connector)
+	return connector
+}
+
+// open opens db of url, connections are established lazily
+func (e *Endpoint) open(url string, readonly bool) *sqlx.DB {
+	db := e.Database.OpenDB(e.connector(url, readonly))
 	db.SetMaxOpenConns(e.PoolSize)
 	db.SetMaxIdleConns(e.PoolSize / 2)
 	db.SetConnMaxLifetime(e.ConnMaxLifetime.Duration())
+	return db
+}
 
+func ping(db *sqlx.DB) error {
 	_, err := db.ExecContext(context.Background(), "SELECT 1")
-	if err == nil {
-		if master {
-			e.DB = db
-		} else {
-			e.slave = db
-		}
-	}
 	return err
 }
 
+// replica opens replica of ep, it is pinged once without retry so startup is
+// not blocked, and registered unhealthy if not connected instead of failing
+// Init, then routed once probed healthy
+func (e *Endpoint) replica(ep types.Endpoint, readonly bool) *sqlx.Replica {
+	r := &sqlx.Replica{Name: ep.Host(), DB: e.open(e.replicaURL(ep), readonly)}
+	r.SetHealthy(ping(r.DB) == nil)
+	return r
+}
+
 func (e *Endpoint) Init() {
 	// cover default database name
 	if len(e.Master.Base) > 0 {
 		e.Database.Name = e.Master.Base
 	}
 	// must try master
-	must.NoError(e.Retry.Do(func() error { return e.conn(true, false) }))
-	// try slave if config
+	e.DB = e.open(e.masterURL(), false)
+	must.NoError(e.Retry.Do(func() error { return ping(e.DB) }))
+
+	// slave and replicas unreachable are skipped until probed healthy
+	replicas := make([]*sqlx.Replica, 0, len(e.Replicas)+1)
 	if !e.Slave.IsZero() {
-		must.NoError(e.Retry.Do(func() error { return e.conn(false, false) }))
+		e.slave = e.replica(e.Slave, false)
+		replicas = append(replicas, e.slave)
+	}
+	for i := range e.Replicas {
+		replicas = append(replicas, e.replica(e.Replicas[i], true))
+	}
+	if len(replicas) > 0 {
+		e.routing = sqlx.NewRoutingDB(e.DB, e.ReplicaPolicy, replicas...)
+		ctx, cancel := context.WithCancel(context.Background())
+		e.cancel = cancel
+		go e.routing.Probe(ctx, e.ReplicaProbeInterval.Duration())
+	}
+}
+
+// Close stops probing of slave and replicas and closes connections of master,
+// slave and replicas
+func (e *Endpoint) Close() error {
+	if e.cancel != nil {
+		e.cancel()
+		e.cancel = nil
+	}
+	var dbs []*sqlx.DB
+	if e.DB != nil {
+		dbs = append(dbs, e.DB)
+	}
+	if e.routing != nil {
+		for _, r := range e.routing.Replicas() {
+			dbs = append(dbs, r.DB)
+		}
 	}
+	var err error
+	for _, db := range dbs {
+		if c, ok := db.SqlExecutor.(io.Closer); ok {
+			if closeErr := c.Close(); closeErr != nil && err == nil {
+				err = closeErr
+			}
+		}
+	}
+	return err
 }
 
 func (e Endpoint) masterURL() string {
@@ -125,12 +213,12 @@ func (e Endpoint) masterURL() string {
 	return fmt.Sprintf("postgres://%s%s@%s", e.Master.Username, passwd, e.Master.Host())
 }
 
-func (e Endpoint) slaveURL() string {
+func (e Endpoint) replicaURL(replica types.Endpoint) string {
 	passwd := e.Master.Password
 	if passwd != "" {
 		passwd = ":" + passwd
 	}
-	return fmt.Sprintf("postgres://%s%s@%s", e.Master.Username, passwd, e.Slave.Host())
+	return fmt.Sprintf("postgres://%s%s@%s", e.Master.Username, passwd, replica.Host())
 }
 
 func (e Endpoint) Name() string { return "pgcli" }
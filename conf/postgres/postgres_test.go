@@ -3,11 +3,17 @@ package postgres_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 
+	"github.com/saitofun/qkit/base/types"
 	"github.com/saitofun/qkit/conf/postgres"
+	"github.com/saitofun/qkit/kit/sqlx"
+	"github.com/saitofun/qkit/kit/sqlx/builder"
+	driver "github.com/saitofun/qkit/kit/sqlx/driver/postgres"
 	"github.com/saitofun/qkit/testutil/postgrestestutil"
+	"github.com/saitofun/qkit/x/misc/retry"
 )
 
 func TestPostgres(t *testing.T) {
@@ -42,4 +48,61 @@ func TestPostgres(t *testing.T) {
 			pg.Slave.Host():  "ok",
 		}),
 	)
+	{
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		notifications, err := pg.Listen(ctx, "events")
+		NewWithT(t).Expect(err).To(BeNil())
+
+		err = sqlx.NewTasks(pg).With(func(db sqlx.DBExecutor) error {
+			_, err := db.Exec(driver.Notify("events", "committed"))
+			return err
+		}).Do()
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(pg.Notify("events", "direct")).To(BeNil())
+
+		for _, payload := range []string{"committed", "direct"} {
+			n := <-notifications
+			NewWithT(t).Expect(n.Channel).To(Equal("events"))
+			NewWithT(t).Expect(n.Payload).To(Equal(payload))
+		}
+
+		// terminate connection of listener, reconnected event is sent
+		_, err = pg.Exec(builder.Expr(
+			`SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE query = 'LISTEN "events"';`,
+		))
+		NewWithT(t).Expect(err).To(BeNil())
+
+		select {
+		case n := <-notifications:
+			NewWithT(t).Expect(n.Channel).To(Equal("events"))
+			NewWithT(t).Expect(n.Reconnected).To(BeTrue())
+		case <-time.After(30 * time.Second):
+			t.Fatal("reconnected event not received")
+		}
+	}
+}
+
+func TestPostgres_UnreachableReplica(t *testing.T) {
+	unreachable := types.Endpoint{Hostname: "127.0.0.1", Port: 1}
+
+	pg := &postgres.Endpoint{
+		Master:   postgrestestutil.Endpoint.Master,
+		Database: postgrestestutil.Endpoint.Database,
+		Replicas: []types.Endpoint{unreachable},
+		Retry:    &retry.Retry{Repeats: 1, Interval: types.Duration(time.Millisecond)},
+	}
+	pg.SetDefault()
+	pg.Init()
+
+	statuses := pg.LivenessCheck()
+	NewWithT(t).Expect(statuses[pg.Master.Host()]).To(Equal("ok"))
+	NewWithT(t).Expect(statuses[unreachable.Host()]).NotTo(Equal("ok"))
+
+	row, err := pg.Routing().QueryContext(context.Background(), "SELECT 1")
+	NewWithT(t).Expect(err).To(BeNil())
+	_ = row.Close()
+
+	NewWithT(t).Expect(pg.Close()).To(BeNil())
 }
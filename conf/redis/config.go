@@ -2,8 +2,11 @@ package redis
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
 
 	"github.com/saitofun/qkit/base/types"
 )
@@ -24,6 +27,76 @@ type Config struct {
 	pool           *redis.Pool
 }
 
-func (r *Config) Get() Conn { return nil }
+var _ types.DefaultSetter = (*Config)(nil)
 
-func (r *Config) GetContext(ctx context.Context) (Conn, error) { return nil, nil }
+func (r *Config) SetDefault() {
+	if r.Protocol == "" {
+		r.Protocol = "tcp"
+	}
+	if r.Host == "" {
+		r.Host = "127.0.0.1"
+	}
+	if r.Port == 0 {
+		r.Port = 6379
+	}
+	if r.ConnectTimeout == 0 {
+		r.ConnectTimeout = types.Duration(10 * time.Second)
+	}
+	if r.WriteTimeout == 0 {
+		r.WriteTimeout = types.Duration(10 * time.Second)
+	}
+	if r.ReadTimeout == 0 {
+		r.ReadTimeout = types.Duration(10 * time.Second)
+	}
+	if r.IdleTimeout == 0 {
+		r.IdleTimeout = types.Duration(240 * time.Second)
+	}
+	if r.MaxActive == 0 {
+		r.MaxActive = 5
+	}
+	if r.MaxIdle == 0 {
+		r.MaxIdle = 3
+	}
+}
+
+func (r *Config) Init() {
+	if r.pool == nil {
+		r.init()
+	}
+}
+
+func (r *Config) init() {
+	dial := func() (redis.Conn, error) {
+		return redis.Dial(
+			r.Protocol,
+			fmt.Sprintf("%s:%d", r.Host, r.Port),
+			redis.DialConnectTimeout(r.ConnectTimeout.Duration()),
+			redis.DialWriteTimeout(r.WriteTimeout.Duration()),
+			redis.DialReadTimeout(r.ReadTimeout.Duration()),
+			redis.DialPassword(r.Password.String()),
+			redis.DialDatabase(r.DB),
+		)
+	}
+
+	r.pool = &redis.Pool{
+		Dial:        dial,
+		MaxIdle:     r.MaxIdle,
+		MaxActive:   r.MaxActive,
+		IdleTimeout: r.IdleTimeout.Duration(),
+		Wait:        r.Wait,
+	}
+}
+
+func (r *Config) Get() Conn {
+	if r.pool != nil {
+		return r.pool.Get()
+	}
+	return nil
+}
+
+func (r *Config) GetContext(ctx context.Context) (Conn, error) {
+	if r.pool != nil {
+		return r.pool.GetContext(ctx)
+	}
+	return nil, errors.New("redis pool not initialized")
+}
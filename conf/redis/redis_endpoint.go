@@ -1 +1,64 @@
 package redis
+
+import (
+	"context"
+	"fmt"
+)
+
+type Endpoint struct {
+	Config
+	Key string
+}
+
+var _ Operator = (*Endpoint)(nil)
+
+func (e *Endpoint) SetDefault() {
+	e.Config.SetDefault()
+	if e.Key == "" {
+		e.Key = "qkit"
+	}
+}
+
+func (e *Endpoint) LivenessCheck() map[string]string {
+	s := map[string]string{}
+	host := fmt.Sprintf("%s:%d", e.Host, e.Port)
+
+	if _, err := e.Exec(Command("PING")); err != nil {
+		s[host] = err.Error()
+	} else {
+		s[host] = "ok"
+	}
+	return s
+}
+
+func (e *Endpoint) Name() string { return "redis-cli" }
+
+func (e *Endpoint) Prefix(key string) string { return e.Key + ":" + key }
+
+func (e *Endpoint) Exec(cmd *Cmd, others ...*Cmd) (interface{}, error) {
+	return e.ExecContext(context.Background(), cmd, others...)
+}
+
+// ExecContext executes cmd, if others given, all commands are executed in a
+// MULTI/EXEC transaction and the replies are returned as []interface{}
+func (e *Endpoint) ExecContext(ctx context.Context, cmd *Cmd, others ...*Cmd) (interface{}, error) {
+	c, err := e.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	if len(others) == 0 {
+		return c.Do(cmd.Name, cmd.Args...)
+	}
+
+	if err = c.Send("MULTI"); err != nil {
+		return nil, err
+	}
+	for _, cmd := range append([]*Cmd{cmd}, others...) {
+		if err = c.Send(cmd.Name, cmd.Args...); err != nil {
+			return nil, err
+		}
+	}
+	return c.Do("EXEC")
+}
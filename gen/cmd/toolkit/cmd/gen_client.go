@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/saitofun/qkit/kit/httpcligen"
+	"github.com/saitofun/qkit/kit/swaggergen"
+	"github.com/saitofun/qkit/x/pkgx"
+)
+
+func init() {
+	routers := make([]string, 0)
+
+	cmd := &cobra.Command{
+		Use:   "client SERVICE_NAME [SPEC_FILE_OR_URL]",
+		Short: "generate http client from openapi spec or routes registered on root router",
+		Long: `generate http client from openapi spec, or from routes registered on root
+routers of package in current directory if spec is not given. routers are
+scanned from sources like 'gen openapi', a *kit.Router created at runtime could
+only be used by httpcligen.NewFromRouter in go code`,
+		Args: cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			cwd, _ := os.Getwd()
+
+			if len(args) == 2 {
+				g, err := httpcligen.NewFromSpec(args[0], args[1])
+				if err != nil {
+					panic(err)
+				}
+				g.Output(cwd)
+				return
+			}
+
+			run("client", func(pkg *pkgx.Pkg) Generator {
+				g := swaggergen.New(pkg)
+				g.Scan(routers...)
+				return httpcligen.New(args[0], g.Doc())
+			}, routers...)
+		},
+	}
+	cmd.Flags().StringSliceVarP(&routers, "router", "r", nil, "root routers scanned if spec is not given, default RootRouter")
+
+	Gen.AddCommand(cmd)
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/saitofun/qkit/kit/swaggergen"
+	"github.com/saitofun/qkit/x/pkgx"
+)
+
+func init() {
+	cmd := &cobra.Command{
+		Use:     "openapi [ROOT_ROUTER...]",
+		Aliases: []string{"swagger", "oas"},
+		Short:   "generate openapi 3.0 document from routes registered on root router",
+		Run: func(cmd *cobra.Command, args []string) {
+			run("openapi", func(pkg *pkgx.Pkg) Generator {
+				g := swaggergen.New(pkg)
+				g.Scan(args...)
+				return g
+			}, args...)
+		},
+	}
+
+	Gen.AddCommand(cmd)
+}
@@ -20,6 +20,7 @@ func init() {
 	command.AddCommand(cmd.Gen)
 	command.AddCommand(cmd.Patch)
 	command.AddCommand(cmd.Format)
+	command.AddCommand(cmd.Migrate)
 }
 
 func main() {
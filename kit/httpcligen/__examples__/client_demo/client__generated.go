@@ -0,0 +1,118 @@
+// This is a generated source file. DO NOT EDIT
+// Source: client_demo/client__generated.go
+
+package client_demo
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/saitofun/qkit/kit/kit"
+)
+
+type Interface interface {
+	Context() context.Context
+	WithContext(context.Context) Interface
+	OpenAPI(req *OpenAPI, metas ...kit.Metadata) (*bytes.Buffer, kit.Metadata, error)
+	DownloadFile(req *DownloadFile, metas ...kit.Metadata) (*bytes.Buffer, kit.Metadata, error)
+	ShowImage(req *ShowImage, metas ...kit.Metadata) (*bytes.Buffer, kit.Metadata, error)
+	Cookie(req *Cookie, metas ...kit.Metadata) (*bytes.Buffer, kit.Metadata, error)
+	FormMultipartWithFile(req *FormMultipartWithFile, metas ...kit.Metadata) (kit.Metadata, error)
+	FormMultipartWithFiles(req *FormMultipartWithFiles, metas ...kit.Metadata) (kit.Metadata, error)
+	FormURLEncoded(req *FormURLEncoded, metas ...kit.Metadata) (kit.Metadata, error)
+	Proxy(req *Proxy, metas ...kit.Metadata) (*IPInfo, kit.Metadata, error)
+	Redirect(req *Redirect, metas ...kit.Metadata) (kit.Metadata, error)
+	RedirectWhenError(req *RedirectWhenError, metas ...kit.Metadata) (kit.Metadata, error)
+	HealthCheck(req *HealthCheck, metas ...kit.Metadata) (kit.Metadata, error)
+	Create(req *Create, metas ...kit.Metadata) (*Data, kit.Metadata, error)
+	RemoveByID(req *RemoveByID, metas ...kit.Metadata) (kit.Metadata, error)
+	GetByID(req *GetByID, metas ...kit.Metadata) (*Data, kit.Metadata, error)
+	UpdateByID(req *UpdateByID, metas ...kit.Metadata) (kit.Metadata, error)
+	ProxyV2(req *ProxyV2, metas ...kit.Metadata) (*bytes.Buffer, kit.Metadata, error)
+}
+
+func NewClientDemo(c kit.Client) *ClientDemo {
+	return &ClientDemo{Client: c}
+}
+
+type ClientDemo struct {
+	Client kit.Client
+	ctx    context.Context
+}
+
+func (c *ClientDemo) Context() context.Context {
+	if c.ctx != nil {
+		return c.ctx
+	}
+	return context.Background()
+}
+
+func (c *ClientDemo) WithContext(ctx context.Context) Interface {
+	cc := new(ClientDemo)
+	cc.Client, cc.ctx = c.Client, ctx
+	return cc
+}
+
+func (c *ClientDemo) OpenAPI(req *OpenAPI, metas ...kit.Metadata) (*bytes.Buffer, kit.Metadata, error) {
+	return req.InvokeContext(c.Context(), c.Client, metas...)
+}
+
+func (c *ClientDemo) DownloadFile(req *DownloadFile, metas ...kit.Metadata) (*bytes.Buffer, kit.Metadata, error) {
+	return req.InvokeContext(c.Context(), c.Client, metas...)
+}
+
+func (c *ClientDemo) ShowImage(req *ShowImage, metas ...kit.Metadata) (*bytes.Buffer, kit.Metadata, error) {
+	return req.InvokeContext(c.Context(), c.Client, metas...)
+}
+
+func (c *ClientDemo) Cookie(req *Cookie, metas ...kit.Metadata) (*bytes.Buffer, kit.Metadata, error) {
+	return req.InvokeContext(c.Context(), c.Client, metas...)
+}
+
+func (c *ClientDemo) FormMultipartWithFile(req *FormMultipartWithFile, metas ...kit.Metadata) (kit.Metadata, error) {
+	return req.InvokeContext(c.Context(), c.Client, metas...)
+}
+
+func (c *ClientDemo) FormMultipartWithFiles(req *FormMultipartWithFiles, metas ...kit.Metadata) (kit.Metadata, error) {
+	return req.InvokeContext(c.Context(), c.Client, metas...)
+}
+
+func (c *ClientDemo) FormURLEncoded(req *FormURLEncoded, metas ...kit.Metadata) (kit.Metadata, error) {
+	return req.InvokeContext(c.Context(), c.Client, metas...)
+}
+
+func (c *ClientDemo) Proxy(req *Proxy, metas ...kit.Metadata) (*IPInfo, kit.Metadata, error) {
+	return req.InvokeContext(c.Context(), c.Client, metas...)
+}
+
+func (c *ClientDemo) Redirect(req *Redirect, metas ...kit.Metadata) (kit.Metadata, error) {
+	return req.InvokeContext(c.Context(), c.Client, metas...)
+}
+
+func (c *ClientDemo) RedirectWhenError(req *RedirectWhenError, metas ...kit.Metadata) (kit.Metadata, error) {
+	return req.InvokeContext(c.Context(), c.Client, metas...)
+}
+
+func (c *ClientDemo) HealthCheck(req *HealthCheck, metas ...kit.Metadata) (kit.Metadata, error) {
+	return req.InvokeContext(c.Context(), c.Client, metas...)
+}
+
+func (c *ClientDemo) Create(req *Create, metas ...kit.Metadata) (*Data, kit.Metadata, error) {
+	return req.InvokeContext(c.Context(), c.Client, metas...)
+}
+
+func (c *ClientDemo) RemoveByID(req *RemoveByID, metas ...kit.Metadata) (kit.Metadata, error) {
+	return req.InvokeContext(c.Context(), c.Client, metas...)
+}
+
+func (c *ClientDemo) GetByID(req *GetByID, metas ...kit.Metadata) (*Data, kit.Metadata, error) {
+	return req.InvokeContext(c.Context(), c.Client, metas...)
+}
+
+func (c *ClientDemo) UpdateByID(req *UpdateByID, metas ...kit.Metadata) (kit.Metadata, error) {
+	return req.InvokeContext(c.Context(), c.Client, metas...)
+}
+
+func (c *ClientDemo) ProxyV2(req *ProxyV2, metas ...kit.Metadata) (*bytes.Buffer, kit.Metadata, error) {
+	return req.InvokeContext(c.Context(), c.Client, metas...)
+}
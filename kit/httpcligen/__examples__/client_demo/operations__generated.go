@@ -0,0 +1,494 @@
+// This is a generated source file. DO NOT EDIT
+// Source: client_demo/operations__generated.go
+
+package client_demo
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+
+	"github.com/saitofun/qkit/kit/kit"
+	"github.com/saitofun/qkit/kit/statusx"
+)
+
+// OpenAPI
+type OpenAPI struct {
+}
+
+func (o *OpenAPI) Path() string {
+	return "/demo"
+}
+
+func (o *OpenAPI) Method() string {
+	return "GET"
+}
+
+func (o *OpenAPI) Do(ctx context.Context, c kit.Client, metas ...kit.Metadata) kit.Result {
+	return c.Do(ctx, o, metas...)
+}
+
+func (o *OpenAPI) InvokeContext(ctx context.Context, c kit.Client, metas ...kit.Metadata) (*bytes.Buffer, kit.Metadata, error) {
+	rsp := bytes.NewBuffer(nil)
+	meta, err := o.Do(ctx, c, metas...).Into(rsp)
+	if err != nil {
+		return nil, meta, statusx.FromErr(err)
+	}
+	return rsp, meta, nil
+}
+
+func (o *OpenAPI) Invoke(c kit.Client, metas ...kit.Metadata) (*bytes.Buffer, kit.Metadata, error) {
+	return o.InvokeContext(context.Background(), c, metas...)
+}
+
+// DownloadFile
+type DownloadFile struct {
+}
+
+func (o *DownloadFile) Path() string {
+	return "/demo/binary/files"
+}
+
+func (o *DownloadFile) Method() string {
+	return "GET"
+}
+
+func (o *DownloadFile) Do(ctx context.Context, c kit.Client, metas ...kit.Metadata) kit.Result {
+	return c.Do(ctx, o, metas...)
+}
+
+func (o *DownloadFile) InvokeContext(ctx context.Context, c kit.Client, metas ...kit.Metadata) (*bytes.Buffer, kit.Metadata, error) {
+	rsp := bytes.NewBuffer(nil)
+	meta, err := o.Do(ctx, c, metas...).Into(rsp)
+	if err != nil {
+		return nil, meta, statusx.FromErr(err)
+	}
+	return rsp, meta, nil
+}
+
+func (o *DownloadFile) Invoke(c kit.Client, metas ...kit.Metadata) (*bytes.Buffer, kit.Metadata, error) {
+	return o.InvokeContext(context.Background(), c, metas...)
+}
+
+// ShowImage
+type ShowImage struct {
+}
+
+func (o *ShowImage) Path() string {
+	return "/demo/binary/images"
+}
+
+func (o *ShowImage) Method() string {
+	return "GET"
+}
+
+func (o *ShowImage) Do(ctx context.Context, c kit.Client, metas ...kit.Metadata) kit.Result {
+	return c.Do(ctx, o, metas...)
+}
+
+func (o *ShowImage) InvokeContext(ctx context.Context, c kit.Client, metas ...kit.Metadata) (*bytes.Buffer, kit.Metadata, error) {
+	rsp := bytes.NewBuffer(nil)
+	meta, err := o.Do(ctx, c, metas...).Into(rsp)
+	if err != nil {
+		return nil, meta, statusx.FromErr(err)
+	}
+	return rsp, meta, nil
+}
+
+func (o *ShowImage) Invoke(c kit.Client, metas ...kit.Metadata) (*bytes.Buffer, kit.Metadata, error) {
+	return o.InvokeContext(context.Background(), c, metas...)
+}
+
+// Cookie
+type Cookie struct {
+	Token string `in:"cookie" name:"token,omitempty"`
+}
+
+func (o *Cookie) Path() string {
+	return "/demo/cookie"
+}
+
+func (o *Cookie) Method() string {
+	return "POST"
+}
+
+func (o *Cookie) Do(ctx context.Context, c kit.Client, metas ...kit.Metadata) kit.Result {
+	return c.Do(ctx, o, metas...)
+}
+
+func (o *Cookie) InvokeContext(ctx context.Context, c kit.Client, metas ...kit.Metadata) (*bytes.Buffer, kit.Metadata, error) {
+	rsp := bytes.NewBuffer(nil)
+	meta, err := o.Do(ctx, c, metas...).Into(rsp)
+	if err != nil {
+		return nil, meta, statusx.FromErr(err)
+	}
+	return rsp, meta, nil
+}
+
+func (o *Cookie) Invoke(c kit.Client, metas ...kit.Metadata) (*bytes.Buffer, kit.Metadata, error) {
+	return o.InvokeContext(context.Background(), c, metas...)
+}
+
+// FormMultipartWithFile
+type FormMultipartWithFile struct {
+	Data struct {
+		Data   *Data                 `name:"data,omitempty"`
+		File   *multipart.FileHeader `name:"file,omitempty"`
+		Map    map[string]int64      `name:"map,omitempty"`
+		Slice  []string              `name:"slice,omitempty"`
+		String string                `name:"string,omitempty"`
+	} `in:"body" mime:"multipart/form-data"`
+}
+
+func (o *FormMultipartWithFile) Path() string {
+	return "/demo/forms/multipart"
+}
+
+func (o *FormMultipartWithFile) Method() string {
+	return "POST"
+}
+
+func (o *FormMultipartWithFile) Do(ctx context.Context, c kit.Client, metas ...kit.Metadata) kit.Result {
+	return c.Do(ctx, o, metas...)
+}
+
+func (o *FormMultipartWithFile) InvokeContext(ctx context.Context, c kit.Client, metas ...kit.Metadata) (kit.Metadata, error) {
+	meta, err := o.Do(ctx, c, metas...).Into(nil)
+	if err != nil {
+		return meta, statusx.FromErr(err)
+	}
+	return meta, nil
+}
+
+func (o *FormMultipartWithFile) Invoke(c kit.Client, metas ...kit.Metadata) (kit.Metadata, error) {
+	return o.InvokeContext(context.Background(), c, metas...)
+}
+
+// FormMultipartWithFiles
+type FormMultipartWithFiles struct {
+	Data struct {
+		Files []*multipart.FileHeader `name:"files"`
+	} `in:"body" mime:"multipart/form-data"`
+}
+
+func (o *FormMultipartWithFiles) Path() string {
+	return "/demo/forms/multipart-with-files"
+}
+
+func (o *FormMultipartWithFiles) Method() string {
+	return "POST"
+}
+
+func (o *FormMultipartWithFiles) Do(ctx context.Context, c kit.Client, metas ...kit.Metadata) kit.Result {
+	return c.Do(ctx, o, metas...)
+}
+
+func (o *FormMultipartWithFiles) InvokeContext(ctx context.Context, c kit.Client, metas ...kit.Metadata) (kit.Metadata, error) {
+	meta, err := o.Do(ctx, c, metas...).Into(nil)
+	if err != nil {
+		return meta, statusx.FromErr(err)
+	}
+	return meta, nil
+}
+
+func (o *FormMultipartWithFiles) Invoke(c kit.Client, metas ...kit.Metadata) (kit.Metadata, error) {
+	return o.InvokeContext(context.Background(), c, metas...)
+}
+
+// FormURLEncoded
+type FormURLEncoded struct {
+	Data struct {
+		Data   Data     `name:"data"`
+		Slice  []string `name:"slice"`
+		String string   `name:"string"`
+	} `in:"body" mime:"application/x-www-form-urlencoded"`
+}
+
+func (o *FormURLEncoded) Path() string {
+	return "/demo/forms/urlencoded"
+}
+
+func (o *FormURLEncoded) Method() string {
+	return "POST"
+}
+
+func (o *FormURLEncoded) Do(ctx context.Context, c kit.Client, metas ...kit.Metadata) kit.Result {
+	return c.Do(ctx, o, metas...)
+}
+
+func (o *FormURLEncoded) InvokeContext(ctx context.Context, c kit.Client, metas ...kit.Metadata) (kit.Metadata, error) {
+	meta, err := o.Do(ctx, c, metas...).Into(nil)
+	if err != nil {
+		return meta, statusx.FromErr(err)
+	}
+	return meta, nil
+}
+
+func (o *FormURLEncoded) Invoke(c kit.Client, metas ...kit.Metadata) (kit.Metadata, error) {
+	return o.InvokeContext(context.Background(), c, metas...)
+}
+
+// Proxy
+type Proxy struct {
+}
+
+func (o *Proxy) Path() string {
+	return "/demo/proxy"
+}
+
+func (o *Proxy) Method() string {
+	return "GET"
+}
+
+func (o *Proxy) Do(ctx context.Context, c kit.Client, metas ...kit.Metadata) kit.Result {
+	return c.Do(ctx, o, metas...)
+}
+
+func (o *Proxy) InvokeContext(ctx context.Context, c kit.Client, metas ...kit.Metadata) (*IPInfo, kit.Metadata, error) {
+	rsp := new(IPInfo)
+	meta, err := o.Do(ctx, c, metas...).Into(rsp)
+	if err != nil {
+		return nil, meta, statusx.FromErr(err)
+	}
+	return rsp, meta, nil
+}
+
+func (o *Proxy) Invoke(c kit.Client, metas ...kit.Metadata) (*IPInfo, kit.Metadata, error) {
+	return o.InvokeContext(context.Background(), c, metas...)
+}
+
+// Redirect
+type Redirect struct {
+}
+
+func (o *Redirect) Path() string {
+	return "/demo/redirect"
+}
+
+func (o *Redirect) Method() string {
+	return "GET"
+}
+
+func (o *Redirect) Do(ctx context.Context, c kit.Client, metas ...kit.Metadata) kit.Result {
+	return c.Do(ctx, o, metas...)
+}
+
+func (o *Redirect) InvokeContext(ctx context.Context, c kit.Client, metas ...kit.Metadata) (kit.Metadata, error) {
+	meta, err := o.Do(ctx, c, metas...).Into(nil)
+	if err != nil {
+		return meta, statusx.FromErr(err)
+	}
+	return meta, nil
+}
+
+func (o *Redirect) Invoke(c kit.Client, metas ...kit.Metadata) (kit.Metadata, error) {
+	return o.InvokeContext(context.Background(), c, metas...)
+}
+
+// RedirectWhenError
+type RedirectWhenError struct {
+}
+
+func (o *RedirectWhenError) Path() string {
+	return "/demo/redirect"
+}
+
+func (o *RedirectWhenError) Method() string {
+	return "POST"
+}
+
+func (o *RedirectWhenError) Do(ctx context.Context, c kit.Client, metas ...kit.Metadata) kit.Result {
+	return c.Do(ctx, o, metas...)
+}
+
+func (o *RedirectWhenError) InvokeContext(ctx context.Context, c kit.Client, metas ...kit.Metadata) (kit.Metadata, error) {
+	meta, err := o.Do(ctx, c, metas...).Into(nil)
+	if err != nil {
+		return meta, statusx.FromErr(err)
+	}
+	return meta, nil
+}
+
+func (o *RedirectWhenError) Invoke(c kit.Client, metas ...kit.Metadata) (kit.Metadata, error) {
+	return o.InvokeContext(context.Background(), c, metas...)
+}
+
+// HealthCheck
+type HealthCheck struct {
+	PullPolicy string `in:"query" name:"pullPolicy,omitempty"`
+}
+
+func (o *HealthCheck) Path() string {
+	return "/demo/restful"
+}
+
+func (o *HealthCheck) Method() string {
+	return "HEAD"
+}
+
+func (o *HealthCheck) Do(ctx context.Context, c kit.Client, metas ...kit.Metadata) kit.Result {
+	return c.Do(ctx, o, metas...)
+}
+
+func (o *HealthCheck) InvokeContext(ctx context.Context, c kit.Client, metas ...kit.Metadata) (kit.Metadata, error) {
+	meta, err := o.Do(ctx, c, metas...).Into(nil)
+	if err != nil {
+		return meta, statusx.FromErr(err)
+	}
+	return meta, nil
+}
+
+func (o *HealthCheck) Invoke(c kit.Client, metas ...kit.Metadata) (kit.Metadata, error) {
+	return o.InvokeContext(context.Background(), c, metas...)
+}
+
+// Create
+type Create struct {
+	Data Data `in:"body"`
+}
+
+func (o *Create) Path() string {
+	return "/demo/restful"
+}
+
+func (o *Create) Method() string {
+	return "POST"
+}
+
+func (o *Create) Do(ctx context.Context, c kit.Client, metas ...kit.Metadata) kit.Result {
+	return c.Do(ctx, o, metas...)
+}
+
+func (o *Create) InvokeContext(ctx context.Context, c kit.Client, metas ...kit.Metadata) (*Data, kit.Metadata, error) {
+	rsp := new(Data)
+	meta, err := o.Do(ctx, c, metas...).Into(rsp)
+	if err != nil {
+		return nil, meta, statusx.FromErr(err)
+	}
+	return rsp, meta, nil
+}
+
+func (o *Create) Invoke(c kit.Client, metas ...kit.Metadata) (*Data, kit.Metadata, error) {
+	return o.InvokeContext(context.Background(), c, metas...)
+}
+
+// RemoveByID
+type RemoveByID struct {
+	ID string `in:"path" name:"id"`
+}
+
+func (o *RemoveByID) Path() string {
+	return "/demo/restful/:id"
+}
+
+func (o *RemoveByID) Method() string {
+	return "DELETE"
+}
+
+func (o *RemoveByID) Do(ctx context.Context, c kit.Client, metas ...kit.Metadata) kit.Result {
+	return c.Do(ctx, o, metas...)
+}
+
+func (o *RemoveByID) InvokeContext(ctx context.Context, c kit.Client, metas ...kit.Metadata) (kit.Metadata, error) {
+	meta, err := o.Do(ctx, c, metas...).Into(nil)
+	if err != nil {
+		return meta, statusx.FromErr(err)
+	}
+	return meta, nil
+}
+
+func (o *RemoveByID) Invoke(c kit.Client, metas ...kit.Metadata) (kit.Metadata, error) {
+	return o.InvokeContext(context.Background(), c, metas...)
+}
+
+// GetByID
+type GetByID struct {
+	ID       string   `in:"path" name:"id"`
+	Protocol Protocol `in:"query" name:"protocol,omitempty"`
+	Name     string   `in:"query" name:"name,omitempty"`
+	Label    []string `in:"query" name:"label,omitempty"`
+}
+
+func (o *GetByID) Path() string {
+	return "/demo/restful/:id"
+}
+
+func (o *GetByID) Method() string {
+	return "GET"
+}
+
+func (o *GetByID) Do(ctx context.Context, c kit.Client, metas ...kit.Metadata) kit.Result {
+	return c.Do(ctx, o, metas...)
+}
+
+func (o *GetByID) InvokeContext(ctx context.Context, c kit.Client, metas ...kit.Metadata) (*Data, kit.Metadata, error) {
+	rsp := new(Data)
+	meta, err := o.Do(ctx, c, metas...).Into(rsp)
+	if err != nil {
+		return nil, meta, statusx.FromErr(err)
+	}
+	return rsp, meta, nil
+}
+
+func (o *GetByID) Invoke(c kit.Client, metas ...kit.Metadata) (*Data, kit.Metadata, error) {
+	return o.InvokeContext(context.Background(), c, metas...)
+}
+
+// UpdateByID
+type UpdateByID struct {
+	ID   string `in:"path" name:"id"`
+	Data Data   `in:"body"`
+}
+
+func (o *UpdateByID) Path() string {
+	return "/demo/restful/:id"
+}
+
+func (o *UpdateByID) Method() string {
+	return "PUT"
+}
+
+func (o *UpdateByID) Do(ctx context.Context, c kit.Client, metas ...kit.Metadata) kit.Result {
+	return c.Do(ctx, o, metas...)
+}
+
+func (o *UpdateByID) InvokeContext(ctx context.Context, c kit.Client, metas ...kit.Metadata) (kit.Metadata, error) {
+	meta, err := o.Do(ctx, c, metas...).Into(nil)
+	if err != nil {
+		return meta, statusx.FromErr(err)
+	}
+	return meta, nil
+}
+
+func (o *UpdateByID) Invoke(c kit.Client, metas ...kit.Metadata) (kit.Metadata, error) {
+	return o.InvokeContext(context.Background(), c, metas...)
+}
+
+// ProxyV2
+type ProxyV2 struct {
+}
+
+func (o *ProxyV2) Path() string {
+	return "/demo/v2/proxy"
+}
+
+func (o *ProxyV2) Method() string {
+	return "GET"
+}
+
+func (o *ProxyV2) Do(ctx context.Context, c kit.Client, metas ...kit.Metadata) kit.Result {
+	return c.Do(ctx, o, metas...)
+}
+
+func (o *ProxyV2) InvokeContext(ctx context.Context, c kit.Client, metas ...kit.Metadata) (*bytes.Buffer, kit.Metadata, error) {
+	rsp := bytes.NewBuffer(nil)
+	meta, err := o.Do(ctx, c, metas...).Into(rsp)
+	if err != nil {
+		return nil, meta, statusx.FromErr(err)
+	}
+	return rsp, meta, nil
+}
+
+func (o *ProxyV2) Invoke(c kit.Client, metas ...kit.Metadata) (*bytes.Buffer, kit.Metadata, error) {
+	return o.InvokeContext(context.Background(), c, metas...)
+}
@@ -0,0 +1,46 @@
+// This is a generated source file. DO NOT EDIT
+// Source: client_demo/types__generated.go
+
+package client_demo
+
+type Data struct {
+	ID        string   `json:"id"`
+	Label     string   `json:"label"`
+	Protocol  Protocol `json:"protocol,omitempty"`
+	PtrString string   `json:"ptrString,omitempty"`
+	SubData   *SubData `json:"subData,omitempty"`
+}
+
+type ErrorField struct {
+	Field string `json:"field"`
+	In    string `json:"in"`
+	Msg   string `json:"msg"`
+}
+
+type IPInfo struct {
+	Country     string `json:"country"`
+	CountryCode string `json:"countryCode"`
+}
+
+type Protocol string
+
+const (
+	PROTOCOL__HTTP  Protocol = "HTTP"
+	PROTOCOL__HTTPS Protocol = "HTTPS"
+	PROTOCOL__TCP   Protocol = "TCP"
+)
+
+type StatusErr struct {
+	CanBeTalk bool         `json:"canBeTalk"`
+	Code      int64        `json:"code"`
+	Desc      string       `json:"desc"`
+	Fields    []ErrorField `json:"fields"`
+	ID        string       `json:"id"`
+	Key       string       `json:"key"`
+	Msg       string       `json:"msg"`
+	Sources   []string     `json:"sources"`
+}
+
+type SubData struct {
+	Name string `json:"name"`
+}
@@ -0,0 +1,60 @@
+package httpcligen
+
+import (
+	gen "github.com/saitofun/qkit/gen/codegen"
+	"github.com/saitofun/qkit/kit/oas"
+	"github.com/saitofun/qkit/x/stringsx"
+)
+
+func (g *Generator) ClientName() string { return "Client" + stringsx.UpperCamelCase(g.Name) }
+
+// WriteClient generate below
+// type Interface interface{ ... }              // operations as methods
+// func NewClientXXX(c kit.Client) *ClientXXX
+// type ClientXXX struct{ ... }                 // implements Interface
+func (g *Generator) WriteClient(f *gen.File) {
+	var (
+		name    = g.ClientName()
+		ctxType = gen.Type(f.Use("context", "Context"))
+		rcv     = gen.Var(gen.Star(gen.Type(name)), "c")
+		methods = []gen.IfCanBeIfMethod{
+			gen.Func().Named("Context").Return(gen.Var(ctxType)),
+			gen.Func(gen.Var(ctxType)).Named("WithContext").Return(gen.Var(gen.Type("Interface"))),
+		}
+		impls []gen.Snippet
+	)
+
+	g.doc.Each(func(method, path string, op *oas.Operation) {
+		o := g.operation(method, path, op)
+		methods = append(methods, o.Signature(f).Named(o.Name))
+		impls = append(impls,
+			o.Signature(f).Named(o.Name).MethodOf(rcv).Do(
+				f.Expr(`return req.InvokeContext(c.Context(), c.Client, metas...)`),
+			),
+		)
+	})
+
+	f.WriteSnippet(
+		gen.DeclType(gen.Var(gen.Interface(methods...), "Interface")),
+		gen.Func(gen.Var(gen.Type(f.Use(PkgKit, "Client")), "c")).
+			Named("New"+name).
+			Return(gen.Var(gen.Star(gen.Type(name)))).
+			Do(f.Expr(`return &?{Client: c}`, gen.Type(name))),
+		gen.DeclType(gen.Var(gen.Struct(
+			gen.Var(gen.Type(f.Use(PkgKit, "Client")), "Client"),
+			gen.Var(ctxType, "ctx"),
+		), name)),
+		gen.Func().Named("Context").MethodOf(rcv).
+			Return(gen.Var(ctxType)).
+			Do(f.Expr(`if c.ctx != nil {
+return c.ctx
+}
+return ?()`, gen.Ident(f.Use("context", "Background")))),
+		gen.Func(gen.Var(ctxType, "ctx")).Named("WithContext").MethodOf(rcv).
+			Return(gen.Var(gen.Type("Interface"))).
+			Do(f.Expr(`cc := new(?)
+cc.Client, cc.ctx = c.Client, ctx
+return cc`, gen.Type(name))),
+	)
+	f.WriteSnippet(impls...)
+}
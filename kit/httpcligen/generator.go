@@ -1,3 +1,113 @@
 package httpcligen
 
-// TODO generate client interface from httptransport
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	gen "github.com/saitofun/qkit/gen/codegen"
+	"github.com/saitofun/qkit/kit/kit"
+	"github.com/saitofun/qkit/kit/oas"
+	"github.com/saitofun/qkit/x/misc/must"
+	"github.com/saitofun/qkit/x/pkgx"
+	"github.com/saitofun/qkit/x/stringsx"
+)
+
+func New(name string, doc *oas.OpenAPI) *Generator {
+	return &Generator{
+		Name: name,
+		Pkg:  "client_" + stringsx.LowerSnakeCase(name),
+		doc:  doc,
+	}
+}
+
+// NewFromRouter creates Generator from operators registered on router
+func NewFromRouter(name string, router *kit.Router) *Generator {
+	return New(name, FromRouter(router))
+}
+
+// NewFromSpec creates Generator from openapi json which can be a local file
+// or a http(s) url, eg: the swagger endpoint served by service
+func NewFromSpec(name string, uri string) (*Generator, error) {
+	doc, err := LoadSpec(uri)
+	if err != nil {
+		return nil, err
+	}
+	return New(name, doc), nil
+}
+
+func LoadSpec(uri string) (*oas.OpenAPI, error) {
+	var r io.ReadCloser
+
+	if strings.HasPrefix(uri, "http://") || strings.HasPrefix(uri, "https://") {
+		rsp, err := http.Get(uri)
+		if err != nil {
+			return nil, err
+		}
+		if rsp.StatusCode != http.StatusOK {
+			rsp.Body.Close()
+			return nil, errors.Errorf("load spec from %s failed: %s", uri, rsp.Status)
+		}
+		r = rsp.Body
+	} else {
+		f, err := os.Open(uri)
+		if err != nil {
+			return nil, err
+		}
+		r = f
+	}
+	defer r.Close()
+
+	doc := oas.NewOpenAPI()
+	if err := json.NewDecoder(r).Decode(doc); err != nil {
+		return nil, errors.Wrapf(err, "decode spec from %s", uri)
+	}
+	return doc, nil
+}
+
+type Generator struct {
+	Name string // Name service name
+	Pkg  string // Pkg generated package name, default `client_${name}`
+	doc  *oas.OpenAPI
+}
+
+func (g *Generator) Output(cwd string) {
+	dir := filepath.Join(cwd, g.Pkg)
+
+	files := []struct {
+		name  string
+		write func(*gen.File)
+	}{
+		{"client.go", g.WriteClient},
+		{"operations.go", g.WriteOperations},
+		{"types.go", g.WriteTypes},
+	}
+
+	for _, file := range files {
+		filename := gen.GenerateFileSuffix(path.Join(dir, file.name))
+		f := gen.NewFile(g.Pkg, filename)
+		file.write(f)
+		if _, err := f.Write(); err != nil {
+			log.Printf("%s generate failed: %v", filename, err)
+		}
+	}
+}
+
+var (
+	PkgKit     = "github.com/saitofun/qkit/kit/kit"
+	PkgStatusX = "github.com/saitofun/qkit/kit/statusx"
+)
+
+func init() {
+	_, current, _, _ := runtime.Caller(0)
+	PkgKit = must.String(pkgx.PkgIdByPath(filepath.Join(filepath.Dir(current), "../kit")))
+	PkgStatusX = must.String(pkgx.PkgIdByPath(filepath.Join(filepath.Dir(current), "../statusx")))
+}
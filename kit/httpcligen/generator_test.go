@@ -0,0 +1,50 @@
+package httpcligen_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/saitofun/qkit/kit/httpcligen"
+	"github.com/saitofun/qkit/kit/oas"
+	"github.com/saitofun/qkit/testutil/httptransporttestutil/server/cmd/app/routes"
+)
+
+func TestGenerator(t *testing.T) {
+	cwd, _ := os.Getwd()
+
+	g := httpcligen.NewFromRouter("demo", routes.RootRouter)
+	g.Output(filepath.Join(cwd, "__examples__"))
+
+	_, err := os.Stat(filepath.Join(cwd, "__examples__", g.Pkg, "operations__generated.go"))
+	NewWithT(t).Expect(err).To(BeNil())
+}
+
+func TestFromRouter(t *testing.T) {
+	doc := httpcligen.FromRouter(routes.RootRouter)
+
+	op := doc.Paths["/demo/proxy"]["get"]
+	NewWithT(t).Expect(op).NotTo(BeNil())
+
+	_, rsp := op.SuccessResponse()
+	NewWithT(t).Expect(rsp).NotTo(BeNil())
+	_, mt := oas.FirstMediaType(rsp.Content)
+	NewWithT(t).Expect(mt.Schema.Ref).To(Equal(oas.RefPrefix + "IpInfo"))
+}
+
+func TestLoadSpec(t *testing.T) {
+	doc := httpcligen.FromRouter(routes.RootRouter)
+
+	data, err := json.Marshal(doc)
+	NewWithT(t).Expect(err).To(BeNil())
+
+	filename := filepath.Join(t.TempDir(), "openapi.json")
+	NewWithT(t).Expect(os.WriteFile(filename, data, 0666)).To(BeNil())
+
+	loaded, err := httpcligen.LoadSpec(filename)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(loaded).To(Equal(doc))
+}
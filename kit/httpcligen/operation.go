@@ -0,0 +1,191 @@
+package httpcligen
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	gen "github.com/saitofun/qkit/gen/codegen"
+	"github.com/saitofun/qkit/kit/httptransport/httpx"
+	"github.com/saitofun/qkit/kit/oas"
+	"github.com/saitofun/qkit/x/stringsx"
+)
+
+type Operation struct {
+	*oas.Operation
+	Name   string // Name go type name of request
+	Method string
+	Path   string // Path in httprouter style
+
+	g *Generator
+}
+
+func (g *Generator) operation(method, path string, op *oas.Operation) *Operation {
+	return &Operation{
+		Operation: op,
+		Name:      stringsx.UpperCamelCase(op.OperationID),
+		Method:    method,
+		Path:      oas.PathToHttpRouter(path),
+		g:         g,
+	}
+}
+
+// Fields returns request fields, parameters are tagged with `in` and `name`
+// and request body is tagged with `in:"body"` and `mime`
+func (o *Operation) Fields(f *gen.File) []*gen.SnippetField {
+	fields := make([]*gen.SnippetField, 0)
+
+	names := map[string]bool{}
+	fieldName := func(name, in string) string {
+		n := stringsx.UpperCamelCase(name)
+		if n == "" || names[n] {
+			n += stringsx.UpperCamelCase(in)
+		}
+		names[n] = true
+		return n
+	}
+
+	params := append([]*oas.Parameter{}, o.Parameters...)
+	sort.SliceStable(params, func(i, j int) bool {
+		return positions[params[i].In] < positions[params[j].In]
+	})
+
+	for _, p := range params {
+		name := p.Name
+		if !p.Required {
+			name += ",omitempty"
+		}
+		fd := gen.Var(o.g.Type(f, p.Schema, "json"), fieldName(p.Name, p.In)).
+			WithTags(map[string][]string{"in": {p.In}, "name": {name}})
+		if p.Description != "" {
+			fd = fd.WithComments(p.Description)
+		}
+		fields = append(fields, fd)
+	}
+
+	if o.RequestBody != nil {
+		contentType, media := oas.FirstMediaType(o.RequestBody.Content)
+		tags := map[string][]string{"in": {"body"}}
+		if contentType != httpx.MIME_JSON {
+			tags["mime"] = []string{contentType}
+		}
+		key := "json"
+		if contentType == httpx.MIME_FORM_URLENCODED || contentType == httpx.MIME_MULTIPART_FORMDAT {
+			key = "name"
+		}
+		var s *oas.Schema
+		if media != nil {
+			s = media.Schema
+		}
+		fields = append(fields, gen.Var(o.g.Type(f, s, key), fieldName("Data", "body")).WithTags(tags))
+	}
+	return fields
+}
+
+// Response returns response type, nil if response has no content. raw is
+// true if response content is not json or not typed, which is copied into
+// *bytes.Buffer
+func (o *Operation) Response(f *gen.File) (typ gen.SnippetType, raw bool) {
+	code, rsp := o.SuccessResponse()
+	if rsp == nil || code == http.StatusNoContent {
+		return nil, false
+	}
+	contentType, media := oas.FirstMediaType(rsp.Content)
+	if media != nil && media.Schema != nil && strings.Contains(contentType, "json") {
+		return o.g.Type(f, media.Schema, "json"), false
+	}
+	return gen.Type(f.Use("bytes", "Buffer")), true
+}
+
+var positions = map[string]int{
+	oas.PositionPath:   0,
+	oas.PositionQuery:  1,
+	oas.PositionHeader: 2,
+	oas.PositionCookie: 3,
+}
+
+// Signature func(req *Operation, metas ...kit.Metadata) (*Response, kit.Metadata, error)
+func (o *Operation) Signature(f *gen.File) *gen.FuncType {
+	return gen.Func(
+		gen.Var(gen.Star(gen.Type(o.Name)), "req"),
+		gen.Var(gen.Ellipsis(gen.Type(f.Use(PkgKit, "Metadata"))), "metas"),
+	).Return(o.results(f)...)
+}
+
+func (o *Operation) results(f *gen.File) []*gen.SnippetField {
+	rets := []*gen.SnippetField{
+		gen.Var(gen.Type(f.Use(PkgKit, "Metadata"))),
+		gen.Var(gen.Error),
+	}
+	if typ, _ := o.Response(f); typ != nil {
+		rets = append([]*gen.SnippetField{gen.Var(gen.Star(typ))}, rets...)
+	}
+	return rets
+}
+
+// Snippets generate below
+// type Operation struct{ ... }    // request parameters
+// func (o *Operation) Path() string
+// func (o *Operation) Method() string
+// func (o *Operation) Do(ctx, kit.Client, ...kit.Metadata) kit.Result
+// func (o *Operation) InvokeContext(ctx, kit.Client, ...kit.Metadata) (*Response, kit.Metadata, error)
+// func (o *Operation) Invoke(kit.Client, ...kit.Metadata) (*Response, kit.Metadata, error)
+func (o *Operation) Snippets(f *gen.File) []gen.Snippet {
+	var (
+		rcv     = gen.Var(gen.Star(gen.Type(o.Name)), "o")
+		ctx     = gen.Var(gen.Type(f.Use("context", "Context")), "ctx")
+		client  = gen.Var(gen.Type(f.Use(PkgKit, "Client")), "c")
+		metas   = gen.Var(gen.Ellipsis(gen.Type(f.Use(PkgKit, "Metadata"))), "metas")
+		comment = []string{o.Name}
+	)
+	if o.Summary != "" {
+		comment = []string{o.Name + " " + o.Summary}
+	}
+	if o.Deprecated {
+		comment = append(comment, "Deprecated: "+o.Method+" "+o.Path)
+	}
+
+	invoke := f.Expr(`meta, err := o.Do(ctx, c, metas...).Into(nil)
+if err != nil {
+return meta, ?(err)
+}
+return meta, nil`, gen.Ident(f.Use(PkgStatusX, "FromErr")))
+	if typ, raw := o.Response(f); typ != nil {
+		rsp := f.Expr(`new(?)`, typ)
+		if raw {
+			rsp = f.Expr(`?(nil)`, gen.Ident(f.Use("bytes", "NewBuffer")))
+		}
+		invoke = f.Expr(`rsp := ?
+meta, err := o.Do(ctx, c, metas...).Into(rsp)
+if err != nil {
+return nil, meta, ?(err)
+}
+return rsp, meta, nil`, rsp, gen.Ident(f.Use(PkgStatusX, "FromErr")))
+	}
+
+	return []gen.Snippet{
+		gen.DeclType(gen.Var(gen.Struct(o.Fields(f)...), o.Name)).WithComments(comment...),
+		gen.Func().Named("Path").MethodOf(rcv).
+			Return(gen.Var(gen.String)).
+			Do(gen.Return(f.Value(o.Path))),
+		gen.Func().Named("Method").MethodOf(rcv).
+			Return(gen.Var(gen.String)).
+			Do(gen.Return(f.Value(o.Method))),
+		gen.Func(ctx, client, metas).Named("Do").MethodOf(rcv).
+			Return(gen.Var(gen.Type(f.Use(PkgKit, "Result")))).
+			Do(f.Expr(`return c.Do(ctx, o, metas...)`)),
+		gen.Func(ctx, client, metas).Named("InvokeContext").MethodOf(rcv).
+			Return(o.results(f)...).
+			Do(invoke),
+		gen.Func(client, metas).Named("Invoke").MethodOf(rcv).
+			Return(o.results(f)...).
+			Do(f.Expr(`return o.InvokeContext(?(), c, metas...)`,
+				gen.Ident(f.Use("context", "Background")))),
+	}
+}
+
+func (g *Generator) WriteOperations(f *gen.File) {
+	g.doc.Each(func(method, path string, op *oas.Operation) {
+		f.WriteSnippet(g.operation(method, path, op).Snippets(f)...)
+	})
+}
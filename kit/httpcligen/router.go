@@ -0,0 +1,134 @@
+package httpcligen
+
+import (
+	"context"
+	"go/types"
+	"reflect"
+	"sort"
+
+	"github.com/saitofun/qkit/kit/httptransport"
+	"github.com/saitofun/qkit/kit/httptransport/transformer"
+	"github.com/saitofun/qkit/kit/kit"
+	"github.com/saitofun/qkit/kit/oas"
+	"github.com/saitofun/qkit/kit/swaggergen"
+	"github.com/saitofun/qkit/x/pkgx"
+	"github.com/saitofun/qkit/x/typesx"
+)
+
+// FromRouter scans http routes registered on router to openapi document.
+// operators are resolved from source by swaggergen, so responses are typed by
+// the results of `Output`. if source of operators cannot be loaded, eg: in a
+// binary deployed without source, parameters are resolved by reflection and
+// responses are untyped
+func FromRouter(router *kit.Router) *oas.OpenAPI {
+	doc := oas.NewOpenAPI()
+	ctx := context.Background()
+
+	metas := make([]*httptransport.HttpRouteMeta, 0)
+	for _, route := range router.Routes() {
+		meta := httptransport.NewHttpRouteMeta(route)
+		if meta.Method() == "" {
+			continue
+		}
+		metas = append(metas, meta)
+	}
+
+	s := newOperatorScanner(doc, metas)
+	b := oas.NewBuilder(doc)
+	if s != nil {
+		b = s.Builder()
+	}
+
+	for _, meta := range metas {
+		method := meta.Method()
+		last := meta.Metas[len(meta.Metas)-1]
+
+		op := &oas.Operation{
+			OperationID: last.ID,
+			Summary:     last.Summary,
+			Deprecated:  last.Deprecated,
+		}
+
+		for _, m := range meta.Metas {
+			if o := s.Operator(m.Type); o != nil {
+				o.BindParameters(op)
+				continue
+			}
+			t := typesx.FromReflectType(m.Type)
+			transformer.EachParameter(ctx, t, func(p *transformer.Param) bool {
+				switch p.In {
+				case "":
+				case "body":
+					op.RequestBody = b.RequestBody(ctx, p)
+				default:
+					op.Parameters = append(op.Parameters, b.Parameter(t, p))
+				}
+				return true
+			})
+		}
+
+		if o := s.Operator(last.Type); o != nil {
+			op.Description = o.Description
+			o.BindResponses(method, op)
+		} else {
+			op.AddResponse(oas.DefaultStatusCode(method, true), &oas.Response{Description: "untyped response"})
+		}
+
+		doc.AddOperation(method, oas.PathFromHttpRouter(meta.Path()), op)
+	}
+	return doc
+}
+
+// operatorScanner resolves static operators of reflect types
+type operatorScanner struct {
+	*swaggergen.OperatorScanner
+	pkg *pkgx.Pkg
+}
+
+// newOperatorScanner loads packages of operators in metas, returns nil if
+// they cannot be loaded
+func newOperatorScanner(doc *oas.OpenAPI, metas []*httptransport.HttpRouteMeta) *operatorScanner {
+	paths := map[string]bool{}
+	for _, meta := range metas {
+		for _, m := range meta.Metas {
+			if path := m.Type.PkgPath(); path != "" {
+				paths[path] = true
+			}
+		}
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+
+	patterns := make([]string, 0, len(paths))
+	for path := range paths {
+		patterns = append(patterns, path)
+	}
+	sort.Strings(patterns)
+
+	pkg, err := pkgx.LoadAll(patterns...)
+	if err != nil {
+		return nil
+	}
+	return &operatorScanner{
+		OperatorScanner: swaggergen.NewOperatorScanner(pkg, doc),
+		pkg:             pkg,
+	}
+}
+
+// Operator returns static operator of t, nil if scanner is nil or t is not
+// found in source
+func (s *operatorScanner) Operator(t reflect.Type) *swaggergen.Operator {
+	if s == nil || t.Name() == "" {
+		return nil
+	}
+	p := s.pkg.PkgByPath(t.PkgPath())
+	if p == nil || p.Types == nil {
+		return nil
+	}
+	tn, ok := p.Types.Scope().Lookup(t.Name()).(*types.TypeName)
+	if !ok {
+		return nil
+	}
+	return s.OperatorScanner.Operator(tn.Type())
+}
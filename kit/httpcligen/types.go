@@ -0,0 +1,157 @@
+package httpcligen
+
+import (
+	"sort"
+
+	gen "github.com/saitofun/qkit/gen/codegen"
+	"github.com/saitofun/qkit/kit/oas"
+	"github.com/saitofun/qkit/x/stringsx"
+)
+
+// Type returns go type of schema s, key is the tag key of inline struct
+func (g *Generator) Type(f *gen.File, s *oas.Schema, key string) gen.SnippetType {
+	if s == nil {
+		return gen.Interface()
+	}
+	if s.IsRef() {
+		return gen.Type(TypeName(s.RefName()))
+	}
+	if len(s.AllOf) == 1 {
+		return g.Type(f, s.AllOf[0], key)
+	}
+
+	switch s.Type {
+	case oas.TypeString:
+		switch s.Format {
+		case "binary":
+			return gen.Star(gen.Type(f.Use("mime/multipart", "FileHeader")))
+		case "byte":
+			return gen.Slice(gen.Byte)
+		case "date-time":
+			return gen.Type(f.Use("time", "Time"))
+		}
+		return gen.String
+	case oas.TypeBoolean:
+		return gen.Bool
+	case oas.TypeInteger:
+		switch s.Format {
+		case "int32":
+			return gen.Int32
+		case "uint64":
+			return gen.Uint64
+		}
+		return gen.Int64
+	case oas.TypeNumber:
+		if s.Format == "float" {
+			return gen.Float32
+		}
+		return gen.Float64
+	case oas.TypeArray:
+		return gen.Slice(g.Type(f, s.Items, key))
+	case oas.TypeObject:
+		if s.AdditionalProperties != nil {
+			return gen.Map(gen.String, g.Type(f, s.AdditionalProperties, key))
+		}
+		if len(s.Properties) > 0 {
+			return g.Struct(f, s, key)
+		}
+	}
+	return gen.Interface()
+}
+
+// Struct returns struct type of object schema s, properties are tagged by key
+func (g *Generator) Struct(f *gen.File, s *oas.Schema, key string) *gen.StructType {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	required := map[string]bool{}
+	for _, name := range s.Required {
+		required[name] = true
+	}
+
+	fields := make([]*gen.SnippetField, 0, len(names))
+	for _, name := range names {
+		prop := s.Properties[name]
+
+		fieldName := prop.GoFieldName
+		if fieldName == "" {
+			fieldName = stringsx.UpperCamelCase(name)
+		}
+
+		typ := g.Type(f, prop, key)
+		tag := name
+		if !required[name] {
+			tag += ",omitempty"
+			if g.isObject(prop) {
+				typ = gen.Star(typ)
+			}
+		}
+
+		fd := gen.Var(typ, fieldName).WithTags(map[string][]string{key: {tag}})
+		if prop.Description != "" {
+			fd = fd.WithComments(prop.Description)
+		}
+		fields = append(fields, fd)
+	}
+	return gen.Struct(fields...)
+}
+
+func (g *Generator) isObject(s *oas.Schema) bool {
+	if len(s.AllOf) == 1 {
+		s = s.AllOf[0]
+	}
+	if s.IsRef() {
+		s = g.doc.Schema(s.Ref)
+	}
+	return s != nil && s.Type == oas.TypeObject && s.AdditionalProperties == nil
+}
+
+// WriteTypes generate component schemas as named types, enum schemas are
+// generated as string types with const values
+func (g *Generator) WriteTypes(f *gen.File) {
+	names := make([]string, 0, len(g.doc.Components.Schemas))
+	for name := range g.doc.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		s := g.doc.Components.Schemas[name]
+		typeName := TypeName(name)
+
+		decl := gen.DeclType(gen.Var(g.Type(f, s, "json"), typeName))
+		if s.Description != "" {
+			decl = decl.WithComments(s.Description)
+		}
+		f.WriteSnippet(decl)
+
+		if len(s.Enum) > 0 && s.Type == oas.TypeString {
+			f.WriteSnippet(g.enumValues(f, typeName, s))
+		}
+	}
+}
+
+func (g *Generator) enumValues(f *gen.File, typeName string, s *oas.Schema) gen.Snippet {
+	specs := make([]gen.SnippetSpec, 0, len(s.Enum))
+	for _, v := range s.Enum {
+		str, ok := v.(string)
+		if !ok {
+			continue
+		}
+		name := stringsx.UpperSnakeCase(typeName) + "__" + stringsx.UpperSnakeCase(str)
+		if !gen.IsValidIdent(name) {
+			continue
+		}
+		specs = append(specs, gen.Assign(gen.Var(gen.Type(typeName), name)).By(f.Value(str)))
+	}
+	if len(specs) == 0 {
+		return nil
+	}
+	return gen.DeclConst(specs...)
+}
+
+// TypeName converts component name to go type name
+func TypeName(name string) string { return stringsx.UpperCamelCase(name) }
@@ -10,4 +10,6 @@ const (
 	MIME_PROTOBUF          = "application/x-protobuf"
 	MIME_MSGPACK           = "application/x-msgpack"
 	MIME_PLAIN_TEXT        = "text/plain"
+	MIME_NDJSON            = "application/x-ndjson"
+	MIME_CSV               = "text/csv"
 )
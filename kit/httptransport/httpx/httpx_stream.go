@@ -0,0 +1,97 @@
+package httpx
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/saitofun/qkit/kit/kit"
+)
+
+// Iterator pulls values one by one, eg: *scanner.Rows[T]
+type Iterator[T any] interface {
+	Next() bool
+	Value() T
+	Err() error
+	Close() error
+}
+
+// NewNDJSONStream streams values of iter as newline delimited json
+func NewNDJSONStream[T any](iter Iterator[T]) *Stream {
+	return &Stream{
+		contentType: MIME_NDJSON,
+		closer:      iter,
+		write: func(w io.Writer) error {
+			enc := json.NewEncoder(w)
+			for iter.Next() {
+				if err := enc.Encode(iter.Value()); err != nil {
+					return err
+				}
+			}
+			return iter.Err()
+		},
+	}
+}
+
+// NewCSVStream streams values of iter as csv with header, each value is
+// converted by record
+func NewCSVStream[T any](iter Iterator[T], header []string, record func(T) []string) *Stream {
+	return &Stream{
+		contentType: MIME_CSV,
+		closer:      iter,
+		write: func(w io.Writer) error {
+			cw := csv.NewWriter(w)
+			if err := cw.Write(header); err != nil {
+				return err
+			}
+			for iter.Next() {
+				if err := cw.Write(record(iter.Value())); err != nil {
+					return err
+				}
+			}
+			cw.Flush()
+			if err := cw.Error(); err != nil {
+				return err
+			}
+			return iter.Err()
+		},
+	}
+}
+
+// Stream response body written while pulling values from iterator without
+// buffering. the iterator is closed after written.
+type Stream struct {
+	contentType string
+	filename    string
+	closer      io.Closer
+	write       func(io.Writer) error
+}
+
+// WithFilename responds stream as attachment
+func (s Stream) WithFilename(filename string) *Stream {
+	s.filename = filename
+	return &s
+}
+
+func (s *Stream) ContentType() string { return s.contentType }
+
+func (s *Stream) Meta() kit.Metadata {
+	if s.filename == "" {
+		return nil
+	}
+	metadata := kit.Metadata{}
+	metadata.Add(HeaderContentDisposition, "attachment; filename="+s.filename)
+	return metadata
+}
+
+func (s *Stream) Into(v interface{}) (kit.Metadata, error) {
+	defer s.closer.Close()
+
+	w, ok := v.(io.Writer)
+	if !ok {
+		return nil, errors.Errorf("stream cannot be written into %T", v)
+	}
+	return nil, s.write(w)
+}
@@ -0,0 +1,76 @@
+package httpx_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+
+	"github.com/saitofun/qkit/kit/httptransport/httpx"
+)
+
+type Item struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type SliceIterator[T any] struct {
+	values []T
+	idx    int
+	closed bool
+}
+
+func (i *SliceIterator[T]) Next() bool {
+	if i.idx >= len(i.values) {
+		return false
+	}
+	i.idx++
+	return true
+}
+
+func (i *SliceIterator[T]) Value() T { return i.values[i.idx-1] }
+
+func (i *SliceIterator[T]) Err() error { return nil }
+
+func (i *SliceIterator[T]) Close() error { i.closed = true; return nil }
+
+func writeStream(s *httpx.Stream) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rw := httptest.NewRecorder()
+	err := httpx.ResponseFrom(s).WriteTo(rw, req, nil)
+	fmt.Println(err)
+	fmt.Println(rw.Header().Get(httpx.HeaderContentType))
+	fmt.Println(rw.Header().Get(httpx.HeaderContentDisposition))
+	fmt.Print(rw.Body.String())
+}
+
+func ExampleNewNDJSONStream() {
+	iter := &SliceIterator[Item]{values: []Item{{1, "a"}, {2, "b"}}}
+	writeStream(httpx.NewNDJSONStream[Item](iter))
+	fmt.Println(iter.closed)
+	// Output:
+	// <nil>
+	// application/x-ndjson
+	//
+	// {"id":1,"name":"a"}
+	// {"id":2,"name":"b"}
+	// true
+}
+
+func ExampleNewCSVStream() {
+	iter := &SliceIterator[Item]{values: []Item{{1, "a"}, {2, "b"}}}
+	writeStream(
+		httpx.NewCSVStream[Item](iter, []string{"id", "name"}, func(v Item) []string {
+			return []string{strconv.Itoa(v.ID), v.Name}
+		}).WithFilename("items.csv"),
+	)
+	fmt.Println(iter.closed)
+	// Output:
+	// <nil>
+	// text/csv
+	// attachment; filename=items.csv
+	// id,name
+	// 1,a
+	// 2,b
+	// true
+}
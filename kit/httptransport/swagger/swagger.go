@@ -11,13 +11,17 @@ import (
 
 var raw = bytes.NewBuffer(nil)
 
+// Files candidates of api document, openapi.json is generated by swaggergen
+var Files = []string{"./openapi.json", "./swagger.json"}
+
 func init() {
-	data, err := ioutil.ReadFile("./swagger.json")
-	if err == nil {
-		raw.Write(data)
-	} else {
-		raw.Write([]byte("{}"))
+	for _, file := range Files {
+		if data, err := ioutil.ReadFile(file); err == nil {
+			raw.Write(data)
+			return
+		}
 	}
+	raw.Write([]byte("{}"))
 }
 
 var Router = kit.NewRouter(OpenAPI{})
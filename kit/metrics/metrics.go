@@ -0,0 +1,183 @@
+package metrics
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Default registry, metrics of qkit components are registered to
+var Default = NewRegistry()
+
+func NewRegistry() *Registry {
+	return &Registry{collectors: map[string]Collector{}}
+}
+
+// Collector writes samples of metric in prometheus text format
+type Collector interface {
+	Name() string
+	WriteTo(w io.Writer) (int64, error)
+}
+
+// Registry of metrics in process, which could be scraped in prometheus text
+// format by WriteTo
+type Registry struct {
+	mu         sync.RWMutex
+	collectors map[string]Collector
+}
+
+// Register registers collector, the registered one is returned if collector
+// with the same name exists
+func (r *Registry) Register(c Collector) Collector {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if exists, ok := r.collectors[c.Name()]; ok {
+		return exists
+	}
+	r.collectors[c.Name()] = c
+	return c
+}
+
+// Counter returns counter registered by name, created if not exists
+func (r *Registry) Counter(name, help string, labelNames ...string) *Counter {
+	c, ok := r.Register(NewCounter(name, help, labelNames...)).(*Counter)
+	if !ok {
+		panic("metric " + name + " is registered as other type")
+	}
+	return c
+}
+
+// Histogram returns histogram registered by name, created if not exists
+func (r *Registry) Histogram(name, help string, buckets []float64, labelNames ...string) *Histogram {
+	h, ok := r.Register(NewHistogram(name, help, buckets, labelNames...)).(*Histogram)
+	if !ok {
+		panic("metric " + name + " is registered as other type")
+	}
+	return h
+}
+
+// WriteTo writes all metrics in prometheus text format ordered by name
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.collectors))
+	for name := range r.collectors {
+		names = append(names, name)
+	}
+	r.mu.RUnlock()
+
+	sort.Strings(names)
+
+	total := int64(0)
+	for _, name := range names {
+		r.mu.RLock()
+		c := r.collectors[name]
+		r.mu.RUnlock()
+
+		n, err := c.WriteTo(w)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// series of metric, samples are keyed by joined label values
+type series[T any] struct {
+	mu     sync.RWMutex
+	labels []string
+	values map[string]*T
+	keys   map[string][]string
+}
+
+func newSeries[T any](labels []string) series[T] {
+	return series[T]{labels: labels, values: map[string]*T{}, keys: map[string][]string{}}
+}
+
+func (s *series[T]) get(labelValues []string, create func() *T) *T {
+	if len(labelValues) != len(s.labels) {
+		panic("label values " + strings.Join(labelValues, ",") +
+			" not match labels " + strings.Join(s.labels, ","))
+	}
+	key := strings.Join(labelValues, "\xff")
+
+	s.mu.RLock()
+	v, ok := s.values[key]
+	s.mu.RUnlock()
+	if ok {
+		return v
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if v, ok = s.values[key]; !ok {
+		v = create()
+		s.values[key] = v
+		s.keys[key] = append([]string(nil), labelValues...)
+	}
+	return v
+}
+
+func (s *series[T]) rangeSorted(f func(labelValues []string, v *T)) {
+	s.mu.RLock()
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	s.mu.RUnlock()
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s.mu.RLock()
+		v, labelValues := s.values[key], s.keys[key]
+		s.mu.RUnlock()
+		f(labelValues, v)
+	}
+}
+
+func writeHeader(buf *bytes.Buffer, name, help, typ string) {
+	if help != "" {
+		buf.WriteString("# HELP " + name + " " + escape(help, false) + "\n")
+	}
+	buf.WriteString("# TYPE " + name + " " + typ + "\n")
+}
+
+func writeSample(buf *bytes.Buffer, name string, labels, labelValues []string, extra string, value float64) {
+	buf.WriteString(name)
+	if len(labels) > 0 || extra != "" {
+		buf.WriteByte('{')
+		for i := range labels {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(labels[i] + `="` + escape(labelValues[i], true) + `"`)
+		}
+		if extra != "" {
+			if len(labels) > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(extra)
+		}
+		buf.WriteByte('}')
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(formatFloat(value))
+	buf.WriteByte('\n')
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func escape(s string, quoted bool) string {
+	r := strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	if quoted {
+		r = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+	}
+	return r.Replace(s)
+}
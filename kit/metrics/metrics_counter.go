@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"sync/atomic"
+)
+
+func NewCounter(name, help string, labelNames ...string) *Counter {
+	return &Counter{name: name, help: help, series: newSeries[uint64](labelNames)}
+}
+
+// Counter is a monotonically increasing value partitioned by labels
+type Counter struct {
+	name   string
+	help   string
+	series series[uint64]
+}
+
+func (c *Counter) Name() string { return c.name }
+
+// Add adds v to counter of label values, v should not be negative
+func (c *Counter) Add(v float64, labelValues ...string) {
+	bits := c.series.get(labelValues, func() *uint64 { return new(uint64) })
+	for {
+		prev := atomic.LoadUint64(bits)
+		next := math.Float64bits(math.Float64frombits(prev) + v)
+		if atomic.CompareAndSwapUint64(bits, prev, next) {
+			return
+		}
+	}
+}
+
+func (c *Counter) Inc(labelValues ...string) { c.Add(1, labelValues...) }
+
+// Value returns counter value of label values
+func (c *Counter) Value(labelValues ...string) float64 {
+	bits := c.series.get(labelValues, func() *uint64 { return new(uint64) })
+	return math.Float64frombits(atomic.LoadUint64(bits))
+}
+
+func (c *Counter) WriteTo(w io.Writer) (int64, error) {
+	buf := bytes.NewBuffer(nil)
+	writeHeader(buf, c.name, c.help, "counter")
+	c.series.rangeSorted(func(labelValues []string, bits *uint64) {
+		writeSample(buf, c.name, c.series.labels, labelValues, "", math.Float64frombits(atomic.LoadUint64(bits)))
+	})
+	return buf.WriteTo(w)
+}
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"sync"
+)
+
+// DefBuckets default buckets of histogram in seconds
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+func NewHistogram(name, help string, buckets []float64, labelNames ...string) *Histogram {
+	if len(buckets) == 0 {
+		buckets = DefBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &Histogram{
+		name:    name,
+		help:    help,
+		buckets: buckets,
+		series:  newSeries[histogramSample](labelNames),
+	}
+}
+
+// Histogram counts observations in cumulative buckets partitioned by labels
+type Histogram struct {
+	name    string
+	help    string
+	buckets []float64
+	series  series[histogramSample]
+}
+
+type histogramSample struct {
+	mu     sync.Mutex
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+func (h *Histogram) Name() string { return h.name }
+
+// Observe adds observation v of label values
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	s := h.sample(labelValues)
+	i := sort.SearchFloat64s(h.buckets, v)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.count++
+	s.sum += v
+}
+
+// Count returns count and sum of observations of label values
+func (h *Histogram) Count(labelValues ...string) (uint64, float64) {
+	s := h.sample(labelValues)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count, s.sum
+}
+
+func (h *Histogram) sample(labelValues []string) *histogramSample {
+	return h.series.get(labelValues, func() *histogramSample {
+		return &histogramSample{counts: make([]uint64, len(h.buckets))}
+	})
+}
+
+func (h *Histogram) WriteTo(w io.Writer) (int64, error) {
+	buf := bytes.NewBuffer(nil)
+	writeHeader(buf, h.name, h.help, "histogram")
+	h.series.rangeSorted(func(labelValues []string, s *histogramSample) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		cumulative := uint64(0)
+		for i, le := range h.buckets {
+			cumulative += s.counts[i]
+			writeSample(buf, h.name+"_bucket", h.series.labels, labelValues,
+				`le="`+formatFloat(le)+`"`, float64(cumulative))
+		}
+		writeSample(buf, h.name+"_bucket", h.series.labels, labelValues,
+			`le="+Inf"`, float64(s.count))
+		writeSample(buf, h.name+"_sum", h.series.labels, labelValues, "", s.sum)
+		writeSample(buf, h.name+"_count", h.series.labels, labelValues, "", float64(s.count))
+	})
+	return buf.WriteTo(w)
+}
@@ -0,0 +1,49 @@
+package metrics_test
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/saitofun/qkit/kit/metrics"
+)
+
+func TestRegistry(t *testing.T) {
+	r := metrics.NewRegistry()
+
+	h := r.Histogram("query_duration_seconds", "duration of queries", []float64{0.1, 1}, "name")
+	h.Observe(0.05, "User.FetchByID")
+	h.Observe(0.5, "User.FetchByID")
+	h.Observe(2, `Org."List"`)
+
+	c := r.Counter("slow_queries_total", "", "name")
+	c.Inc("User.FetchByID")
+	c.Add(2, "User.FetchByID")
+
+	NewWithT(t).Expect(r.Histogram("query_duration_seconds", "", nil, "name")).To(BeIdenticalTo(h))
+	NewWithT(t).Expect(c.Value("User.FetchByID")).To(Equal(3.0))
+
+	count, sum := h.Count("User.FetchByID")
+	NewWithT(t).Expect(count).To(Equal(uint64(2)))
+	NewWithT(t).Expect(sum).To(Equal(0.55))
+
+	buf := bytes.NewBuffer(nil)
+	_, err := r.WriteTo(buf)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(buf.String()).To(Equal(`# HELP query_duration_seconds duration of queries
+# TYPE query_duration_seconds histogram
+query_duration_seconds_bucket{name="Org.\"List\"",le="0.1"} 0
+query_duration_seconds_bucket{name="Org.\"List\"",le="1"} 0
+query_duration_seconds_bucket{name="Org.\"List\"",le="+Inf"} 1
+query_duration_seconds_sum{name="Org.\"List\""} 2
+query_duration_seconds_count{name="Org.\"List\""} 1
+query_duration_seconds_bucket{name="User.FetchByID",le="0.1"} 1
+query_duration_seconds_bucket{name="User.FetchByID",le="1"} 2
+query_duration_seconds_bucket{name="User.FetchByID",le="+Inf"} 2
+query_duration_seconds_sum{name="User.FetchByID"} 0.55
+query_duration_seconds_count{name="User.FetchByID"} 2
+# TYPE slow_queries_total counter
+slow_queries_total{name="User.FetchByID"} 3
+`))
+}
@@ -1,5 +1,11 @@
 package example
 
+import (
+	"context"
+
+	"github.com/saitofun/qkit/kit/sqlx"
+)
+
 // @def primary ID
 
 // Org describe organization information
@@ -9,4 +15,14 @@ type Org struct {
 	// @rel User.ID
 	// User relation...
 	UserID string `db:"f_user_id"`
+	// Version for optimistic locking
+	Version uint64 `db:"f_version,version"`
+}
+
+// BeforeCreate names org by user if name is empty
+func (m *Org) BeforeCreate(_ context.Context, _ sqlx.DBExecutor) error {
+	if m.Name == "" {
+		m.Name = "org of " + m.UserID
+	}
+	return nil
 }
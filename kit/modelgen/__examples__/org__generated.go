@@ -8,6 +8,7 @@ import (
 
 	"github.com/saitofun/qkit/kit/sqlx"
 	"github.com/saitofun/qkit/kit/sqlx/builder"
+	"github.com/saitofun/qkit/kit/sqlx/datatypes"
 )
 
 var OrgTable *builder.Table
@@ -39,7 +40,8 @@ func (*Org) TableDesc() []string {
 
 func (*Org) Comments() map[string]string {
 	return map[string]string{
-		"UserID": "User relation...",
+		"UserID":  "User relation...",
+		"Version": "Version for optimistic locking",
 	}
 }
 
@@ -48,6 +50,9 @@ func (*Org) ColDesc() map[string][]string {
 		"UserID": []string{
 			"User relation...",
 		},
+		"Version": []string{
+			"Version for optimistic locking",
+		},
 	}
 }
 
@@ -96,6 +101,14 @@ func (*Org) FieldUserID() string {
 	return "UserID"
 }
 
+func (m *Org) ColVersion() *builder.Column {
+	return OrgTable.ColByFieldName(m.FieldVersion())
+}
+
+func (*Org) FieldVersion() string {
+	return "Version"
+}
+
 func (m *Org) CondByValue(db sqlx.DBExecutor) builder.SqlCondition {
 	var (
 		tbl  = db.T(m)
@@ -119,9 +132,15 @@ func (m *Org) CondByValue(db sqlx.DBExecutor) builder.SqlCondition {
 }
 
 func (m *Org) Create(db sqlx.DBExecutor) error {
-
-	_, err := db.Exec(sqlx.InsertToDB(db, m, nil))
-	return err
+	return sqlx.NewTasks(db).With(func(db sqlx.DBExecutor) error {
+		if err := sqlx.BeforeCreate(db, m); err != nil {
+			return err
+		}
+		if _, err := db.Exec(sqlx.InsertToDB(db, m, nil)); err != nil {
+			return err
+		}
+		return sqlx.AfterCreate(db, m)
+	}).Do()
 }
 
 func (m *Org) List(db sqlx.DBExecutor, cond builder.SqlCondition, adds ...builder.Addition) ([]Org, error) {
@@ -141,6 +160,112 @@ func (m *Org) Count(db sqlx.DBExecutor, cond builder.SqlCondition, adds ...build
 	return
 }
 
+func (m *Org) ListByCursor(db sqlx.DBExecutor, cond builder.SqlCondition, cur *datatypes.Cursor, adds ...builder.Addition) ([]Org, *datatypes.Cursors, error) {
+	cur = cur.WithDefault()
+	var (
+		tbl     = db.T(m)
+		lst     = make([]Org, 0)
+		cols    = tbl.MustColsByFieldNames("ID").List()
+		cursors = &datatypes.Cursors{}
+	)
+	if cur.Cursor != "" {
+		key := &Org{}
+		if err := cur.Decode(&key.ID); err != nil {
+			return nil, nil, err
+		}
+		cond = builder.And(cond, builder.Seek(cur.IsPrev(), cols, key.ID))
+	}
+	adds = append([]builder.Addition{builder.Where(cond), builder.SeekOrderBy(cur.IsPrev(), cols...), cur.Addition(), builder.Comment("Org.ListByCursor")}, adds...)
+	if err := db.QueryAndScan(builder.Select(nil).From(tbl, adds...), &lst); err != nil {
+		return nil, nil, err
+	}
+	size, next, prev := cur.Page(len(lst))
+	lst = lst[:size]
+	if cur.IsPrev() {
+		for i, j := 0, len(lst)-1; i < j; i, j = i+1, j-1 {
+			lst[i], lst[j] = lst[j], lst[i]
+		}
+	}
+	var err error
+	if next {
+		last := &lst[len(lst)-1]
+		if cursors.Next, err = datatypes.EncodeCursor(last.ID); err != nil {
+			return nil, nil, err
+		}
+	}
+	if prev {
+		first := &lst[0]
+		if cursors.Prev, err = datatypes.EncodeCursor(first.ID); err != nil {
+			return nil, nil, err
+		}
+	}
+	return lst, cursors, nil
+}
+
+func (m *Org) BatchCreate(db sqlx.DBExecutor, lst []Org) error {
+	models := make([]builder.Model, 0, len(lst))
+	for i := range lst {
+		models = append(models, &lst[i])
+	}
+	return sqlx.NewTasks(db).With(func(db sqlx.DBExecutor) error {
+		for i := range models {
+			if err := sqlx.BeforeCreate(db, models[i]); err != nil {
+				return err
+			}
+		}
+		if err := sqlx.BatchInsertToDB(db, models); err != nil {
+			return err
+		}
+		for i := range models {
+			if err := sqlx.AfterCreate(db, models[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Do()
+}
+
+func (m *Org) BatchUpsert(db sqlx.DBExecutor, lst []Org, conflict string) error {
+	models := make([]builder.Model, 0, len(lst))
+	for i := range lst {
+		models = append(models, &lst[i])
+	}
+	return sqlx.NewTasks(db).With(func(db sqlx.DBExecutor) error {
+		existed, err := sqlx.BatchExistedByIndex(db, models, conflict)
+		if err != nil {
+			return err
+		}
+		for i := range models {
+			if existed[i] {
+				continue
+			}
+			if err := sqlx.BeforeCreate(db, models[i]); err != nil {
+				return err
+			}
+		}
+		if err := sqlx.BatchUpsertToDB(db, models, conflict); err != nil {
+			return err
+		}
+		for i := range models {
+			if existed[i] {
+				continue
+			}
+			if err := sqlx.AfterCreate(db, models[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Do()
+}
+
+func (m *Org) BatchDeleteByIDs(db sqlx.DBExecutor, values []uint64) error {
+	vs := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		vs = append(vs, v)
+	}
+	return sqlx.BatchDeleteByValues(db, m, "ID", vs)
+}
+
 func (m *Org) FetchByID(db sqlx.DBExecutor) error {
 	tbl := db.T(m)
 	err := db.QueryAndScan(
@@ -160,24 +285,36 @@ func (m *Org) FetchByID(db sqlx.DBExecutor) error {
 }
 
 func (m *Org) UpdateByIDWithFVs(db sqlx.DBExecutor, fvs builder.FieldValues) error {
-	tbl := db.T(m)
-	res, err := db.Exec(
-		builder.Update(tbl).
-			Where(
-				builder.And(
-					tbl.ColByFieldName("ID").Eq(m.ID),
-				),
-				builder.Comment("Org.UpdateByIDWithFVs"),
-			).
-			Set(tbl.AssignmentsByFieldValues(fvs)...),
-	)
-	if err != nil {
-		return err
-	}
-	if affected, _ := res.RowsAffected(); affected == 0 {
-		return m.FetchByID(db)
-	}
-	return nil
+	return sqlx.NewTasks(db).With(func(db sqlx.DBExecutor) error {
+		if err := sqlx.BeforeUpdate(db, m, fvs); err != nil {
+			return err
+		}
+		tbl := db.T(m)
+		delete(fvs, "Version")
+		version := tbl.ColByFieldName("Version")
+		res, err := db.Exec(
+			builder.Update(tbl).
+				Where(
+					builder.And(
+						tbl.ColByFieldName("ID").Eq(m.ID),
+						tbl.ColByFieldName("Version").Eq(m.Version),
+					),
+					builder.Comment("Org.UpdateByIDWithFVs"),
+				).
+				Set(append(tbl.AssignmentsByFieldValues(fvs), version.ValueBy(version.Inc(1)))...),
+		)
+		if err != nil {
+			return err
+		}
+		if affected, _ := res.RowsAffected(); affected == 0 {
+			if err = m.FetchByID(db); err != nil {
+				return err
+			}
+			return sqlx.NewVersionConflictError("Org.UpdateByIDWithFVs")
+		}
+		m.Version++
+		return sqlx.AfterUpdate(db, m)
+	}).Do()
 }
 
 func (m *Org) UpdateByID(db sqlx.DBExecutor, zeros ...string) error {
@@ -186,30 +323,44 @@ func (m *Org) UpdateByID(db sqlx.DBExecutor, zeros ...string) error {
 }
 
 func (m *Org) Delete(db sqlx.DBExecutor) error {
-	_, err := db.Exec(
-		builder.Delete().
-			From(
-				db.T(m),
-				builder.Where(m.CondByValue(db)),
-				builder.Comment("Org.Delete"),
-			),
-	)
-	return err
+	return sqlx.NewTasks(db).With(func(db sqlx.DBExecutor) error {
+		if err := sqlx.BeforeDelete(db, m); err != nil {
+			return err
+		}
+		if _, err := db.Exec(
+			builder.Delete().
+				From(
+					db.T(m),
+					builder.Where(m.CondByValue(db)),
+					builder.Comment("Org.Delete"),
+				),
+		); err != nil {
+			return err
+		}
+		return sqlx.AfterDelete(db, m)
+	}).Do()
 }
 
 func (m *Org) DeleteByID(db sqlx.DBExecutor) error {
-	tbl := db.T(m)
-	_, err := db.Exec(
-		builder.Delete().
-			From(
-				tbl,
-				builder.Where(
-					builder.And(
-						tbl.ColByFieldName("ID").Eq(m.ID),
+	return sqlx.NewTasks(db).With(func(db sqlx.DBExecutor) error {
+		if err := sqlx.BeforeDelete(db, m); err != nil {
+			return err
+		}
+		tbl := db.T(m)
+		if _, err := db.Exec(
+			builder.Delete().
+				From(
+					tbl,
+					builder.Where(
+						builder.And(
+							tbl.ColByFieldName("ID").Eq(m.ID),
+						),
 					),
+					builder.Comment("Org.DeleteByID"),
 				),
-				builder.Comment("Org.DeleteByID"),
-			),
-	)
-	return err
+		); err != nil {
+			return err
+		}
+		return sqlx.AfterDelete(db, m)
+	}).Do()
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/saitofun/qkit/base/types"
 	"github.com/saitofun/qkit/kit/sqlx"
 	"github.com/saitofun/qkit/kit/sqlx/builder"
+	"github.com/saitofun/qkit/kit/sqlx/datatypes"
 )
 
 var UserTable *builder.Table
@@ -81,6 +82,10 @@ func (*User) PrimaryKey() []string {
 	}
 }
 
+func (*User) SoftDeleteFieldName() string {
+	return "DeletedAt"
+}
+
 func (*User) Indexes() builder.Indexes {
 	return builder.Indexes{
 		"i_geom/SPATIAL": []string{
@@ -218,7 +223,7 @@ func (m *User) CondByValue(db sqlx.DBExecutor) builder.SqlCondition {
 	var (
 		tbl  = db.T(m)
 		fvs  = builder.FieldValueFromStructByNoneZero(m)
-		cond = []builder.SqlCondition{tbl.ColByFieldName("DeletedAt").Eq(0)}
+		cond = make([]builder.SqlCondition, 0)
 	)
 
 	for _, fn := range m.IndexFieldNames() {
@@ -245,9 +250,15 @@ func (m *User) Create(db sqlx.DBExecutor) error {
 	if m.UpdatedAt.IsZero() {
 		m.UpdatedAt.Set(time.Now())
 	}
-
-	_, err := db.Exec(sqlx.InsertToDB(db, m, nil))
-	return err
+	return sqlx.NewTasks(db).With(func(db sqlx.DBExecutor) error {
+		if err := sqlx.BeforeCreate(db, m); err != nil {
+			return err
+		}
+		if _, err := db.Exec(sqlx.InsertToDB(db, m, nil)); err != nil {
+			return err
+		}
+		return sqlx.AfterCreate(db, m)
+	}).Do()
 }
 
 func (m *User) List(db sqlx.DBExecutor, cond builder.SqlCondition, adds ...builder.Addition) ([]User, error) {
@@ -255,7 +266,6 @@ func (m *User) List(db sqlx.DBExecutor, cond builder.SqlCondition, adds ...build
 		tbl = db.T(m)
 		lst = make([]User, 0)
 	)
-	cond = builder.And(tbl.ColByFieldName("DeletedAt").Eq(0), cond)
 	adds = append([]builder.Addition{builder.Where(cond), builder.Comment("User.List")}, adds...)
 	err := db.QueryAndScan(builder.Select(nil).From(tbl, adds...), &lst)
 	return lst, err
@@ -263,12 +273,177 @@ func (m *User) List(db sqlx.DBExecutor, cond builder.SqlCondition, adds ...build
 
 func (m *User) Count(db sqlx.DBExecutor, cond builder.SqlCondition, adds ...builder.Addition) (cnt int64, err error) {
 	tbl := db.T(m)
-	cond = builder.And(tbl.ColByFieldName("DeletedAt").Eq(0), cond)
 	adds = append([]builder.Addition{builder.Where(cond), builder.Comment("User.List")}, adds...)
 	err = db.QueryAndScan(builder.Select(builder.Count()).From(tbl, adds...), &cnt)
 	return
 }
 
+func (m *User) ListByCursor(db sqlx.DBExecutor, cond builder.SqlCondition, cur *datatypes.Cursor, adds ...builder.Addition) ([]User, *datatypes.Cursors, error) {
+	cur = cur.WithDefault()
+	var (
+		tbl     = db.T(m)
+		lst     = make([]User, 0)
+		cols    = tbl.MustColsByFieldNames("ID").List()
+		cursors = &datatypes.Cursors{}
+	)
+	if cur.Cursor != "" {
+		key := &User{}
+		if err := cur.Decode(&key.ID); err != nil {
+			return nil, nil, err
+		}
+		cond = builder.And(cond, builder.Seek(cur.IsPrev(), cols, key.ID))
+	}
+	adds = append([]builder.Addition{builder.Where(cond), builder.SeekOrderBy(cur.IsPrev(), cols...), cur.Addition(), builder.Comment("User.ListByCursor")}, adds...)
+	if err := db.QueryAndScan(builder.Select(nil).From(tbl, adds...), &lst); err != nil {
+		return nil, nil, err
+	}
+	size, next, prev := cur.Page(len(lst))
+	lst = lst[:size]
+	if cur.IsPrev() {
+		for i, j := 0, len(lst)-1; i < j; i, j = i+1, j-1 {
+			lst[i], lst[j] = lst[j], lst[i]
+		}
+	}
+	var err error
+	if next {
+		last := &lst[len(lst)-1]
+		if cursors.Next, err = datatypes.EncodeCursor(last.ID); err != nil {
+			return nil, nil, err
+		}
+	}
+	if prev {
+		first := &lst[0]
+		if cursors.Prev, err = datatypes.EncodeCursor(first.ID); err != nil {
+			return nil, nil, err
+		}
+	}
+	return lst, cursors, nil
+}
+
+func (m *User) SoftDelete(db sqlx.DBExecutor, cond builder.SqlCondition) error {
+	tbl := db.T(m)
+	fvs := builder.FieldValues{}
+
+	if _, ok := fvs["DeletedAt"]; !ok {
+		fvs["DeletedAt"] = types.Timestamp{Time: time.Now()}
+	}
+
+	if _, ok := fvs["UpdatedAt"]; !ok {
+		fvs["UpdatedAt"] = types.Timestamp{Time: time.Now()}
+	}
+	_, err := db.Exec(
+		builder.Update(tbl).
+			Where(cond, builder.Comment("User.SoftDelete")).
+			Set(tbl.AssignmentsByFieldValues(fvs)...),
+	)
+	return err
+}
+
+func (m *User) Restore(db sqlx.DBExecutor, cond builder.SqlCondition) error {
+	tbl := db.T(m)
+	fvs := builder.FieldValues{"DeletedAt": 0}
+
+	if _, ok := fvs["UpdatedAt"]; !ok {
+		fvs["UpdatedAt"] = types.Timestamp{Time: time.Now()}
+	}
+	_, err := db.Exec(
+		builder.Update(tbl).
+			Where(cond, builder.OnlyDeleted(), builder.Comment("User.Restore")).
+			Set(tbl.AssignmentsByFieldValues(fvs)...),
+	)
+	return err
+}
+
+func (m *User) Purge(db sqlx.DBExecutor, cond builder.SqlCondition) error {
+	tbl := db.T(m)
+	cond = builder.And(builder.SoftDeleteCondition(tbl, builder.OnlyDeleted()), cond)
+	_, err := db.Exec(
+		builder.Delete().
+			From(
+				tbl,
+				builder.Where(cond),
+				builder.Comment("User.Purge"),
+			),
+	)
+	return err
+}
+
+func (m *User) BatchCreate(db sqlx.DBExecutor, lst []User) error {
+	models := make([]builder.Model, 0, len(lst))
+	for i := range lst {
+		if lst[i].CreatedAt.IsZero() {
+			lst[i].CreatedAt.Set(time.Now())
+		}
+		if lst[i].UpdatedAt.IsZero() {
+			lst[i].UpdatedAt.Set(time.Now())
+		}
+		models = append(models, &lst[i])
+	}
+	return sqlx.NewTasks(db).With(func(db sqlx.DBExecutor) error {
+		for i := range models {
+			if err := sqlx.BeforeCreate(db, models[i]); err != nil {
+				return err
+			}
+		}
+		if err := sqlx.BatchInsertToDB(db, models); err != nil {
+			return err
+		}
+		for i := range models {
+			if err := sqlx.AfterCreate(db, models[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Do()
+}
+
+func (m *User) BatchUpsert(db sqlx.DBExecutor, lst []User, conflict string) error {
+	models := make([]builder.Model, 0, len(lst))
+	for i := range lst {
+		if lst[i].CreatedAt.IsZero() {
+			lst[i].CreatedAt.Set(time.Now())
+		}
+		if lst[i].UpdatedAt.IsZero() {
+			lst[i].UpdatedAt.Set(time.Now())
+		}
+		models = append(models, &lst[i])
+	}
+	return sqlx.NewTasks(db).With(func(db sqlx.DBExecutor) error {
+		existed, err := sqlx.BatchExistedByIndex(db, models, conflict)
+		if err != nil {
+			return err
+		}
+		for i := range models {
+			if existed[i] {
+				continue
+			}
+			if err := sqlx.BeforeCreate(db, models[i]); err != nil {
+				return err
+			}
+		}
+		if err := sqlx.BatchUpsertToDB(db, models, conflict, "CreatedAt"); err != nil {
+			return err
+		}
+		for i := range models {
+			if existed[i] {
+				continue
+			}
+			if err := sqlx.AfterCreate(db, models[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Do()
+}
+
+func (m *User) BatchDeleteByIDs(db sqlx.DBExecutor, values []uint64) error {
+	vs := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		vs = append(vs, v)
+	}
+	return sqlx.BatchDeleteByValues(db, m, "ID", vs)
+}
+
 func (m *User) FetchByID(db sqlx.DBExecutor) error {
 	tbl := db.T(m)
 	err := db.QueryAndScan(
@@ -332,25 +507,32 @@ func (m *User) UpdateByIDWithFVs(db sqlx.DBExecutor, fvs builder.FieldValues) er
 	if _, ok := fvs["UpdatedAt"]; !ok {
 		fvs["UpdatedAt"] = types.Timestamp{Time: time.Now()}
 	}
-	tbl := db.T(m)
-	res, err := db.Exec(
-		builder.Update(tbl).
-			Where(
-				builder.And(
-					tbl.ColByFieldName("ID").Eq(m.ID),
-					tbl.ColByFieldName("DeletedAt").Eq(m.DeletedAt),
-				),
-				builder.Comment("User.UpdateByIDWithFVs"),
-			).
-			Set(tbl.AssignmentsByFieldValues(fvs)...),
-	)
-	if err != nil {
-		return err
-	}
-	if affected, _ := res.RowsAffected(); affected == 0 {
-		return m.FetchByID(db)
-	}
-	return nil
+	return sqlx.NewTasks(db).With(func(db sqlx.DBExecutor) error {
+		if err := sqlx.BeforeUpdate(db, m, fvs); err != nil {
+			return err
+		}
+		tbl := db.T(m)
+		res, err := db.Exec(
+			builder.Update(tbl).
+				Where(
+					builder.And(
+						tbl.ColByFieldName("ID").Eq(m.ID),
+						tbl.ColByFieldName("DeletedAt").Eq(m.DeletedAt),
+					),
+					builder.Comment("User.UpdateByIDWithFVs"),
+				).
+				Set(tbl.AssignmentsByFieldValues(fvs)...),
+		)
+		if err != nil {
+			return err
+		}
+		if affected, _ := res.RowsAffected(); affected == 0 {
+			if err = m.FetchByID(db); err != nil {
+				return err
+			}
+		}
+		return sqlx.AfterUpdate(db, m)
+	}).Do()
 }
 
 func (m *User) UpdateByID(db sqlx.DBExecutor, zeros ...string) error {
@@ -363,26 +545,33 @@ func (m *User) UpdateByIDAndOrgIDWithFVs(db sqlx.DBExecutor, fvs builder.FieldVa
 	if _, ok := fvs["UpdatedAt"]; !ok {
 		fvs["UpdatedAt"] = types.Timestamp{Time: time.Now()}
 	}
-	tbl := db.T(m)
-	res, err := db.Exec(
-		builder.Update(tbl).
-			Where(
-				builder.And(
-					tbl.ColByFieldName("ID").Eq(m.ID),
-					tbl.ColByFieldName("OrgID").Eq(m.OrgID),
-					tbl.ColByFieldName("DeletedAt").Eq(m.DeletedAt),
-				),
-				builder.Comment("User.UpdateByIDAndOrgIDWithFVs"),
-			).
-			Set(tbl.AssignmentsByFieldValues(fvs)...),
-	)
-	if err != nil {
-		return err
-	}
-	if affected, _ := res.RowsAffected(); affected == 0 {
-		return m.FetchByIDAndOrgID(db)
-	}
-	return nil
+	return sqlx.NewTasks(db).With(func(db sqlx.DBExecutor) error {
+		if err := sqlx.BeforeUpdate(db, m, fvs); err != nil {
+			return err
+		}
+		tbl := db.T(m)
+		res, err := db.Exec(
+			builder.Update(tbl).
+				Where(
+					builder.And(
+						tbl.ColByFieldName("ID").Eq(m.ID),
+						tbl.ColByFieldName("OrgID").Eq(m.OrgID),
+						tbl.ColByFieldName("DeletedAt").Eq(m.DeletedAt),
+					),
+					builder.Comment("User.UpdateByIDAndOrgIDWithFVs"),
+				).
+				Set(tbl.AssignmentsByFieldValues(fvs)...),
+		)
+		if err != nil {
+			return err
+		}
+		if affected, _ := res.RowsAffected(); affected == 0 {
+			if err = m.FetchByIDAndOrgID(db); err != nil {
+				return err
+			}
+		}
+		return sqlx.AfterUpdate(db, m)
+	}).Do()
 }
 
 func (m *User) UpdateByIDAndOrgID(db sqlx.DBExecutor, zeros ...string) error {
@@ -395,25 +584,32 @@ func (m *User) UpdateByNameWithFVs(db sqlx.DBExecutor, fvs builder.FieldValues)
 	if _, ok := fvs["UpdatedAt"]; !ok {
 		fvs["UpdatedAt"] = types.Timestamp{Time: time.Now()}
 	}
-	tbl := db.T(m)
-	res, err := db.Exec(
-		builder.Update(tbl).
-			Where(
-				builder.And(
-					tbl.ColByFieldName("Name").Eq(m.Name),
-					tbl.ColByFieldName("DeletedAt").Eq(m.DeletedAt),
-				),
-				builder.Comment("User.UpdateByNameWithFVs"),
-			).
-			Set(tbl.AssignmentsByFieldValues(fvs)...),
-	)
-	if err != nil {
-		return err
-	}
-	if affected, _ := res.RowsAffected(); affected == 0 {
-		return m.FetchByName(db)
-	}
-	return nil
+	return sqlx.NewTasks(db).With(func(db sqlx.DBExecutor) error {
+		if err := sqlx.BeforeUpdate(db, m, fvs); err != nil {
+			return err
+		}
+		tbl := db.T(m)
+		res, err := db.Exec(
+			builder.Update(tbl).
+				Where(
+					builder.And(
+						tbl.ColByFieldName("Name").Eq(m.Name),
+						tbl.ColByFieldName("DeletedAt").Eq(m.DeletedAt),
+					),
+					builder.Comment("User.UpdateByNameWithFVs"),
+				).
+				Set(tbl.AssignmentsByFieldValues(fvs)...),
+		)
+		if err != nil {
+			return err
+		}
+		if affected, _ := res.RowsAffected(); affected == 0 {
+			if err = m.FetchByName(db); err != nil {
+				return err
+			}
+		}
+		return sqlx.AfterUpdate(db, m)
+	}).Do()
 }
 
 func (m *User) UpdateByName(db sqlx.DBExecutor, zeros ...string) error {
@@ -422,37 +618,50 @@ func (m *User) UpdateByName(db sqlx.DBExecutor, zeros ...string) error {
 }
 
 func (m *User) Delete(db sqlx.DBExecutor) error {
-	_, err := db.Exec(
-		builder.Delete().
-			From(
-				db.T(m),
-				builder.Where(m.CondByValue(db)),
-				builder.Comment("User.Delete"),
-			),
-	)
-	return err
+	return sqlx.NewTasks(db).With(func(db sqlx.DBExecutor) error {
+		if err := sqlx.BeforeDelete(db, m); err != nil {
+			return err
+		}
+		if _, err := db.Exec(
+			builder.Delete().
+				From(
+					db.T(m),
+					builder.Where(m.CondByValue(db)),
+					builder.Comment("User.Delete"),
+				),
+		); err != nil {
+			return err
+		}
+		return sqlx.AfterDelete(db, m)
+	}).Do()
 }
 
 func (m *User) DeleteByID(db sqlx.DBExecutor) error {
-	tbl := db.T(m)
-	_, err := db.Exec(
-		builder.Delete().
-			From(
-				tbl,
-				builder.Where(
-					builder.And(
-						tbl.ColByFieldName("ID").Eq(m.ID),
-						tbl.ColByFieldName("DeletedAt").Eq(m.DeletedAt),
+	return sqlx.NewTasks(db).With(func(db sqlx.DBExecutor) error {
+		if err := sqlx.BeforeDelete(db, m); err != nil {
+			return err
+		}
+		tbl := db.T(m)
+		if _, err := db.Exec(
+			builder.Delete().
+				From(
+					tbl,
+					builder.Where(
+						builder.And(
+							tbl.ColByFieldName("ID").Eq(m.ID),
+							tbl.ColByFieldName("DeletedAt").Eq(m.DeletedAt),
+						),
 					),
+					builder.Comment("User.DeleteByID"),
 				),
-				builder.Comment("User.DeleteByID"),
-			),
-	)
-	return err
+		); err != nil {
+			return err
+		}
+		return sqlx.AfterDelete(db, m)
+	}).Do()
 }
 
 func (m *User) SoftDeleteByID(db sqlx.DBExecutor) error {
-	tbl := db.T(m)
 	fvs := builder.FieldValues{}
 
 	if _, ok := fvs["DeletedAt"]; !ok {
@@ -462,41 +671,55 @@ func (m *User) SoftDeleteByID(db sqlx.DBExecutor) error {
 	if _, ok := fvs["UpdatedAt"]; !ok {
 		fvs["UpdatedAt"] = types.Timestamp{Time: time.Now()}
 	}
-	_, err := db.Exec(
-		builder.Update(db.T(m)).
-			Where(
-				builder.And(
-					tbl.ColByFieldName("ID").Eq(m.ID),
-					tbl.ColByFieldName("DeletedAt").Eq(m.DeletedAt),
-				),
-				builder.Comment("User.SoftDeleteByID"),
-			).
-			Set(tbl.AssignmentsByFieldValues(fvs)...),
-	)
-	return err
-}
-
-func (m *User) DeleteByIDAndOrgID(db sqlx.DBExecutor) error {
-	tbl := db.T(m)
-	_, err := db.Exec(
-		builder.Delete().
-			From(
-				tbl,
-				builder.Where(
+	return sqlx.NewTasks(db).With(func(db sqlx.DBExecutor) error {
+		if err := sqlx.BeforeDelete(db, m); err != nil {
+			return err
+		}
+		tbl := db.T(m)
+		if _, err := db.Exec(
+			builder.Update(db.T(m)).
+				Where(
 					builder.And(
 						tbl.ColByFieldName("ID").Eq(m.ID),
-						tbl.ColByFieldName("OrgID").Eq(m.OrgID),
 						tbl.ColByFieldName("DeletedAt").Eq(m.DeletedAt),
 					),
+					builder.Comment("User.SoftDeleteByID"),
+				).
+				Set(tbl.AssignmentsByFieldValues(fvs)...),
+		); err != nil {
+			return err
+		}
+		return sqlx.AfterDelete(db, m)
+	}).Do()
+}
+
+func (m *User) DeleteByIDAndOrgID(db sqlx.DBExecutor) error {
+	return sqlx.NewTasks(db).With(func(db sqlx.DBExecutor) error {
+		if err := sqlx.BeforeDelete(db, m); err != nil {
+			return err
+		}
+		tbl := db.T(m)
+		if _, err := db.Exec(
+			builder.Delete().
+				From(
+					tbl,
+					builder.Where(
+						builder.And(
+							tbl.ColByFieldName("ID").Eq(m.ID),
+							tbl.ColByFieldName("OrgID").Eq(m.OrgID),
+							tbl.ColByFieldName("DeletedAt").Eq(m.DeletedAt),
+						),
+					),
+					builder.Comment("User.DeleteByIDAndOrgID"),
 				),
-				builder.Comment("User.DeleteByIDAndOrgID"),
-			),
-	)
-	return err
+		); err != nil {
+			return err
+		}
+		return sqlx.AfterDelete(db, m)
+	}).Do()
 }
 
 func (m *User) SoftDeleteByIDAndOrgID(db sqlx.DBExecutor) error {
-	tbl := db.T(m)
 	fvs := builder.FieldValues{}
 
 	if _, ok := fvs["DeletedAt"]; !ok {
@@ -506,41 +729,55 @@ func (m *User) SoftDeleteByIDAndOrgID(db sqlx.DBExecutor) error {
 	if _, ok := fvs["UpdatedAt"]; !ok {
 		fvs["UpdatedAt"] = types.Timestamp{Time: time.Now()}
 	}
-	_, err := db.Exec(
-		builder.Update(db.T(m)).
-			Where(
-				builder.And(
-					tbl.ColByFieldName("ID").Eq(m.ID),
-					tbl.ColByFieldName("OrgID").Eq(m.OrgID),
-					tbl.ColByFieldName("DeletedAt").Eq(m.DeletedAt),
-				),
-				builder.Comment("User.SoftDeleteByIDAndOrgID"),
-			).
-			Set(tbl.AssignmentsByFieldValues(fvs)...),
-	)
-	return err
+	return sqlx.NewTasks(db).With(func(db sqlx.DBExecutor) error {
+		if err := sqlx.BeforeDelete(db, m); err != nil {
+			return err
+		}
+		tbl := db.T(m)
+		if _, err := db.Exec(
+			builder.Update(db.T(m)).
+				Where(
+					builder.And(
+						tbl.ColByFieldName("ID").Eq(m.ID),
+						tbl.ColByFieldName("OrgID").Eq(m.OrgID),
+						tbl.ColByFieldName("DeletedAt").Eq(m.DeletedAt),
+					),
+					builder.Comment("User.SoftDeleteByIDAndOrgID"),
+				).
+				Set(tbl.AssignmentsByFieldValues(fvs)...),
+		); err != nil {
+			return err
+		}
+		return sqlx.AfterDelete(db, m)
+	}).Do()
 }
 
 func (m *User) DeleteByName(db sqlx.DBExecutor) error {
-	tbl := db.T(m)
-	_, err := db.Exec(
-		builder.Delete().
-			From(
-				tbl,
-				builder.Where(
-					builder.And(
-						tbl.ColByFieldName("Name").Eq(m.Name),
-						tbl.ColByFieldName("DeletedAt").Eq(m.DeletedAt),
+	return sqlx.NewTasks(db).With(func(db sqlx.DBExecutor) error {
+		if err := sqlx.BeforeDelete(db, m); err != nil {
+			return err
+		}
+		tbl := db.T(m)
+		if _, err := db.Exec(
+			builder.Delete().
+				From(
+					tbl,
+					builder.Where(
+						builder.And(
+							tbl.ColByFieldName("Name").Eq(m.Name),
+							tbl.ColByFieldName("DeletedAt").Eq(m.DeletedAt),
+						),
 					),
+					builder.Comment("User.DeleteByName"),
 				),
-				builder.Comment("User.DeleteByName"),
-			),
-	)
-	return err
+		); err != nil {
+			return err
+		}
+		return sqlx.AfterDelete(db, m)
+	}).Do()
 }
 
 func (m *User) SoftDeleteByName(db sqlx.DBExecutor) error {
-	tbl := db.T(m)
 	fvs := builder.FieldValues{}
 
 	if _, ok := fvs["DeletedAt"]; !ok {
@@ -550,16 +787,24 @@ func (m *User) SoftDeleteByName(db sqlx.DBExecutor) error {
 	if _, ok := fvs["UpdatedAt"]; !ok {
 		fvs["UpdatedAt"] = types.Timestamp{Time: time.Now()}
 	}
-	_, err := db.Exec(
-		builder.Update(db.T(m)).
-			Where(
-				builder.And(
-					tbl.ColByFieldName("Name").Eq(m.Name),
-					tbl.ColByFieldName("DeletedAt").Eq(m.DeletedAt),
-				),
-				builder.Comment("User.SoftDeleteByName"),
-			).
-			Set(tbl.AssignmentsByFieldValues(fvs)...),
-	)
-	return err
+	return sqlx.NewTasks(db).With(func(db sqlx.DBExecutor) error {
+		if err := sqlx.BeforeDelete(db, m); err != nil {
+			return err
+		}
+		tbl := db.T(m)
+		if _, err := db.Exec(
+			builder.Update(db.T(m)).
+				Where(
+					builder.And(
+						tbl.ColByFieldName("Name").Eq(m.Name),
+						tbl.ColByFieldName("DeletedAt").Eq(m.DeletedAt),
+					),
+					builder.Comment("User.SoftDeleteByName"),
+				).
+				Set(tbl.AssignmentsByFieldValues(fvs)...),
+		); err != nil {
+			return err
+		}
+		return sqlx.AfterDelete(db, m)
+	}).Do()
 }
@@ -11,9 +11,10 @@ import (
 )
 
 var (
-	g *Generator
-	f *codegen.File
-	m *Model
+	g   *Generator
+	f   *codegen.File
+	m   *Model
+	org *Model
 )
 
 func init() {
@@ -30,6 +31,7 @@ func init() {
 	g.StructName = "Org"
 	g.Scan()
 	g.Output(cwd)
+	org = GetModelByName(g, "Org")
 
 	g = New(pkg)
 	g.WithComments = true
@@ -270,7 +272,7 @@ func ExampleModel_SnippetCondByValue() {
 	// var (
 	// tbl = db.T(m)
 	// fvs = builder.FieldValueFromStructByNoneZero(m)
-	// cond = []builder.SqlCondition{tbl.ColByFieldName("DeletedAt").Eq(0)}
+	// cond = make([]builder.SqlCondition, 0)
 	// )
 	//
 	// for _, fn := range m.IndexFieldNames() {
@@ -301,10 +303,16 @@ func ExampleModel_SnippetCreate() {
 	// if m.UpdatedAt.IsZero() {
 	// m.UpdatedAt.Set(time.Now())
 	// }
-	//
-	// _, err := db.Exec(sqlx.InsertToDB(db, m, nil))
+	// return sqlx.NewTasks(db).With(func(db sqlx.DBExecutor) error {
+	// if err := sqlx.BeforeCreate(db, m); err != nil {
+	// return err
+	// }
+	// if _, err := db.Exec(sqlx.InsertToDB(db, m, nil)); err != nil {
 	// return err
 	// }
+	// return sqlx.AfterCreate(db, m)
+	// }).Do()
+	// }
 }
 
 func ExampleModel_SnippetList() {
@@ -315,7 +323,6 @@ func ExampleModel_SnippetList() {
 	// tbl = db.T(m)
 	// lst = make([]User, 0)
 	// )
-	// cond = builder.And(tbl.ColByFieldName("DeletedAt").Eq(0), cond)
 	// adds = append([]builder.Addition{builder.Where(cond), builder.Comment("User.List")}, adds...)
 	// err := db.QueryAndScan(builder.Select(nil).From(tbl, adds...), &lst)
 	// return lst, err
@@ -327,13 +334,206 @@ func ExampleModel_SnippetCount() {
 	// Output:
 	// func (m *User) Count(db sqlx.DBExecutor, cond builder.SqlCondition, adds ...builder.Addition) (cnt int64, err error) {
 	// tbl := db.T(m)
-	// cond = builder.And(tbl.ColByFieldName("DeletedAt").Eq(0), cond)
 	// adds = append([]builder.Addition{builder.Where(cond), builder.Comment("User.List")}, adds...)
 	// err = db.QueryAndScan(builder.Select(builder.Count()).From(tbl, adds...), &cnt)
 	// return
 	// }
 }
 
+func ExampleModel_SnippetListByCursor() {
+	fmt.Println(string(m.SnippetListByCursor(f).Bytes()))
+	// Output:
+	// func (m *User) ListByCursor(db sqlx.DBExecutor, cond builder.SqlCondition, cur *datatypes.Cursor, adds ...builder.Addition) ([]User, *datatypes.Cursors, error) {
+	// cur = cur.WithDefault()
+	// var (
+	// tbl = db.T(m)
+	// lst = make([]User, 0)
+	// cols = tbl.MustColsByFieldNames("ID").List()
+	// cursors = &datatypes.Cursors{}
+	// )
+	// if cur.Cursor != "" {
+	// key := &User{}
+	// if err := cur.Decode(&key.ID); err != nil {
+	// return nil, nil, err
+	// }
+	// cond = builder.And(cond, builder.Seek(cur.IsPrev(), cols, key.ID))
+	// }
+	// adds = append([]builder.Addition{builder.Where(cond), builder.SeekOrderBy(cur.IsPrev(), cols...), cur.Addition(), builder.Comment("User.ListByCursor")}, adds...)
+	// if err := db.QueryAndScan(builder.Select(nil).From(tbl, adds...), &lst); err != nil {
+	// return nil, nil, err
+	// }
+	// size, next, prev := cur.Page(len(lst))
+	// lst = lst[:size]
+	// if cur.IsPrev() {
+	// for i, j := 0, len(lst)-1; i < j; i, j = i+1, j-1 {
+	// lst[i], lst[j] = lst[j], lst[i]
+	// }
+	// }
+	// var err error
+	// if next {
+	// last := &lst[len(lst)-1]
+	// if cursors.Next, err = datatypes.EncodeCursor(last.ID); err != nil {
+	// return nil, nil, err
+	// }
+	// }
+	// if prev {
+	// first := &lst[0]
+	// if cursors.Prev, err = datatypes.EncodeCursor(first.ID); err != nil {
+	// return nil, nil, err
+	// }
+	// }
+	// return lst, cursors, nil
+	// }
+}
+
+func ExampleModel_SnippetSoftDeleteFieldName() {
+	fmt.Println(string(m.SnippetSoftDeleteFieldName(f).Bytes()))
+	// Output:
+	// func (*User) SoftDeleteFieldName() string {
+	// return "DeletedAt"
+	// }
+}
+
+func ExampleModel_SnippetSoftDeleteRestorePurge() {
+	for _, s := range m.SnippetSoftDeleteRestorePurge(f) {
+		fmt.Println(string(s.Bytes()))
+	}
+	// Output:
+	// func (m *User) SoftDelete(db sqlx.DBExecutor, cond builder.SqlCondition) error {
+	// tbl := db.T(m)
+	// fvs := builder.FieldValues{}
+	//
+	// if _, ok := fvs["DeletedAt"]; !ok {
+	// fvs["DeletedAt"] = types.Timestamp{Time: time.Now()}
+	// }
+	//
+	// if _, ok := fvs["UpdatedAt"]; !ok {
+	// fvs["UpdatedAt"] = types.Timestamp{Time: time.Now()}
+	// }
+	// _, err := db.Exec(
+	// builder.Update(tbl).
+	// Where(cond, builder.Comment("User.SoftDelete")).
+	// Set(tbl.AssignmentsByFieldValues(fvs)...),
+	// )
+	// return err
+	// }
+	// func (m *User) Restore(db sqlx.DBExecutor, cond builder.SqlCondition) error {
+	// tbl := db.T(m)
+	// fvs := builder.FieldValues{"DeletedAt": 0}
+	//
+	// if _, ok := fvs["UpdatedAt"]; !ok {
+	// fvs["UpdatedAt"] = types.Timestamp{Time: time.Now()}
+	// }
+	// _, err := db.Exec(
+	// builder.Update(tbl).
+	// Where(cond, builder.OnlyDeleted(), builder.Comment("User.Restore")).
+	// Set(tbl.AssignmentsByFieldValues(fvs)...),
+	// )
+	// return err
+	// }
+	// func (m *User) Purge(db sqlx.DBExecutor, cond builder.SqlCondition) error {
+	// tbl := db.T(m)
+	// cond = builder.And(builder.SoftDeleteCondition(tbl, builder.OnlyDeleted()), cond)
+	// _, err := db.Exec(
+	// builder.Delete().
+	// From(
+	// tbl,
+	// builder.Where(cond),
+	// builder.Comment("User.Purge"),
+	// ),
+	// )
+	// return err
+	// }
+}
+
+func ExampleModel_SnippetBatchCreate() {
+	fmt.Println(string(m.SnippetBatchCreate(f).Bytes()))
+	// Output:
+	// func (m *User) BatchCreate(db sqlx.DBExecutor, lst []User) error {
+	// models := make([]builder.Model, 0, len(lst))
+	// for i := range lst {
+	// if lst[i].CreatedAt.IsZero() {
+	// lst[i].CreatedAt.Set(time.Now())
+	// }
+	// if lst[i].UpdatedAt.IsZero() {
+	// lst[i].UpdatedAt.Set(time.Now())
+	// }
+	// models = append(models, &lst[i])
+	// }
+	// return sqlx.NewTasks(db).With(func(db sqlx.DBExecutor) error {
+	// for i := range models {
+	// if err := sqlx.BeforeCreate(db, models[i]); err != nil {
+	// return err
+	// }
+	// }
+	// if err := sqlx.BatchInsertToDB(db, models); err != nil {
+	// return err
+	// }
+	// for i := range models {
+	// if err := sqlx.AfterCreate(db, models[i]); err != nil {
+	// return err
+	// }
+	// }
+	// return nil
+	// }).Do()
+	// }
+}
+
+func ExampleModel_SnippetBatchUpsert() {
+	fmt.Println(string(m.SnippetBatchUpsert(f).Bytes()))
+	// Output:
+	// func (m *User) BatchUpsert(db sqlx.DBExecutor, lst []User, conflict string) error {
+	// models := make([]builder.Model, 0, len(lst))
+	// for i := range lst {
+	// if lst[i].CreatedAt.IsZero() {
+	// lst[i].CreatedAt.Set(time.Now())
+	// }
+	// if lst[i].UpdatedAt.IsZero() {
+	// lst[i].UpdatedAt.Set(time.Now())
+	// }
+	// models = append(models, &lst[i])
+	// }
+	// return sqlx.NewTasks(db).With(func(db sqlx.DBExecutor) error {
+	// existed, err := sqlx.BatchExistedByIndex(db, models, conflict)
+	// if err != nil {
+	// return err
+	// }
+	// for i := range models {
+	// if existed[i] {
+	// continue
+	// }
+	// if err := sqlx.BeforeCreate(db, models[i]); err != nil {
+	// return err
+	// }
+	// }
+	// if err := sqlx.BatchUpsertToDB(db, models, conflict, "CreatedAt"); err != nil {
+	// return err
+	// }
+	// for i := range models {
+	// if existed[i] {
+	// continue
+	// }
+	// if err := sqlx.AfterCreate(db, models[i]); err != nil {
+	// return err
+	// }
+	// }
+	// return nil
+	// }).Do()
+	// }
+}
+
+func ExampleModel_SnippetBatchDeleteByPrimary() {
+	fmt.Println(string(m.SnippetBatchDeleteByPrimary(f).Bytes()))
+	// Output:
+	// func (m *User) BatchDeleteByIDs(db sqlx.DBExecutor, values []uint64) error {
+	// vs := make([]interface{}, 0, len(values))
+	// for _, v := range values {
+	// vs = append(vs, v)
+	// }
+	// return sqlx.BatchDeleteByValues(db, m, "ID", vs)
+	// }
+}
+
 func ExampleModel_SnippetCRUDByUniqueKeys() {
 	ss := m.SnippetCRUDByUniqueKeys(f, "primary", "ui_name")
 	for _, s := range ss {
@@ -379,6 +579,10 @@ func ExampleModel_SnippetCRUDByUniqueKeys() {
 	// if _, ok := fvs["UpdatedAt"]; !ok {
 	// fvs["UpdatedAt"] = types.Timestamp{Time: time.Now()}
 	// }
+	// return sqlx.NewTasks(db).With(func(db sqlx.DBExecutor) error {
+	// if err := sqlx.BeforeUpdate(db, m, fvs); err != nil {
+	// return err
+	// }
 	// tbl := db.T(m)
 	// res, err := db.Exec(
 	// builder.Update(tbl).
@@ -395,9 +599,12 @@ func ExampleModel_SnippetCRUDByUniqueKeys() {
 	// return err
 	// }
 	// if affected, _ := res.RowsAffected(); affected == 0 {
-	// return m.FetchByID(db)
+	// if err = m.FetchByID(db); err != nil {
+	// return err
 	// }
-	// return nil
+	// }
+	// return sqlx.AfterUpdate(db, m)
+	// }).Do()
 	// }func (m *User) UpdateByID(db sqlx.DBExecutor, zeros ...string) error {
 	// fvs := builder.FieldValueFromStructByNoneZero(m, zeros...)
 	// return m.UpdateByIDWithFVs(db, fvs)
@@ -406,6 +613,10 @@ func ExampleModel_SnippetCRUDByUniqueKeys() {
 	// if _, ok := fvs["UpdatedAt"]; !ok {
 	// fvs["UpdatedAt"] = types.Timestamp{Time: time.Now()}
 	// }
+	// return sqlx.NewTasks(db).With(func(db sqlx.DBExecutor) error {
+	// if err := sqlx.BeforeUpdate(db, m, fvs); err != nil {
+	// return err
+	// }
 	// tbl := db.T(m)
 	// res, err := db.Exec(
 	// builder.Update(tbl).
@@ -422,25 +633,39 @@ func ExampleModel_SnippetCRUDByUniqueKeys() {
 	// return err
 	// }
 	// if affected, _ := res.RowsAffected(); affected == 0 {
-	// return m.FetchByName(db)
+	// if err = m.FetchByName(db); err != nil {
+	// return err
 	// }
-	// return nil
+	// }
+	// return sqlx.AfterUpdate(db, m)
+	// }).Do()
 	// }func (m *User) UpdateByName(db sqlx.DBExecutor, zeros ...string) error {
 	// fvs := builder.FieldValueFromStructByNoneZero(m, zeros...)
 	// return m.UpdateByNameWithFVs(db, fvs)
 	// }func (m *User) Delete(db sqlx.DBExecutor) error {
-	// _, err := db.Exec(
+	// return sqlx.NewTasks(db).With(func(db sqlx.DBExecutor) error {
+	// if err := sqlx.BeforeDelete(db, m); err != nil {
+	// return err
+	// }
+	// if _, err := db.Exec(
 	// builder.Delete().
 	// From(
 	// db.T(m),
 	// builder.Where(m.CondByValue(db)),
 	// builder.Comment("User.Delete"),
 	// ),
-	// )
+	// ); err != nil {
 	// return err
+	// }
+	// return sqlx.AfterDelete(db, m)
+	// }).Do()
 	// }func (m *User) DeleteByID(db sqlx.DBExecutor) error {
+	// return sqlx.NewTasks(db).With(func(db sqlx.DBExecutor) error {
+	// if err := sqlx.BeforeDelete(db, m); err != nil {
+	// return err
+	// }
 	// tbl := db.T(m)
-	// _, err := db.Exec(
+	// if _, err := db.Exec(
 	// builder.Delete().
 	// From(
 	// tbl,
@@ -452,10 +677,12 @@ func ExampleModel_SnippetCRUDByUniqueKeys() {
 	// ),
 	// builder.Comment("User.DeleteByID"),
 	// ),
-	// )
+	// ); err != nil {
 	// return err
+	// }
+	// return sqlx.AfterDelete(db, m)
+	// }).Do()
 	// }func (m *User) SoftDeleteByID(db sqlx.DBExecutor) error {
-	// tbl := db.T(m)
 	// fvs := builder.FieldValues{}
 	//
 	// if _, ok := fvs["DeletedAt"]; !ok {
@@ -465,7 +692,12 @@ func ExampleModel_SnippetCRUDByUniqueKeys() {
 	// if _, ok := fvs["UpdatedAt"]; !ok {
 	// fvs["UpdatedAt"] = types.Timestamp{Time: time.Now()}
 	// }
-	// _, err := db.Exec(
+	// return sqlx.NewTasks(db).With(func(db sqlx.DBExecutor) error {
+	// if err := sqlx.BeforeDelete(db, m); err != nil {
+	// return err
+	// }
+	// tbl := db.T(m)
+	// if _, err := db.Exec(
 	// builder.Update(db.T(m)).
 	// Where(
 	// builder.And(
@@ -475,11 +707,18 @@ func ExampleModel_SnippetCRUDByUniqueKeys() {
 	// builder.Comment("User.SoftDeleteByID"),
 	// ).
 	// Set(tbl.AssignmentsByFieldValues(fvs)...),
-	// )
+	// ); err != nil {
 	// return err
+	// }
+	// return sqlx.AfterDelete(db, m)
+	// }).Do()
 	// }func (m *User) DeleteByName(db sqlx.DBExecutor) error {
+	// return sqlx.NewTasks(db).With(func(db sqlx.DBExecutor) error {
+	// if err := sqlx.BeforeDelete(db, m); err != nil {
+	// return err
+	// }
 	// tbl := db.T(m)
-	// _, err := db.Exec(
+	// if _, err := db.Exec(
 	// builder.Delete().
 	// From(
 	// tbl,
@@ -491,10 +730,12 @@ func ExampleModel_SnippetCRUDByUniqueKeys() {
 	// ),
 	// builder.Comment("User.DeleteByName"),
 	// ),
-	// )
+	// ); err != nil {
 	// return err
+	// }
+	// return sqlx.AfterDelete(db, m)
+	// }).Do()
 	// }func (m *User) SoftDeleteByName(db sqlx.DBExecutor) error {
-	// tbl := db.T(m)
 	// fvs := builder.FieldValues{}
 	//
 	// if _, ok := fvs["DeletedAt"]; !ok {
@@ -504,7 +745,12 @@ func ExampleModel_SnippetCRUDByUniqueKeys() {
 	// if _, ok := fvs["UpdatedAt"]; !ok {
 	// fvs["UpdatedAt"] = types.Timestamp{Time: time.Now()}
 	// }
-	// _, err := db.Exec(
+	// return sqlx.NewTasks(db).With(func(db sqlx.DBExecutor) error {
+	// if err := sqlx.BeforeDelete(db, m); err != nil {
+	// return err
+	// }
+	// tbl := db.T(m)
+	// if _, err := db.Exec(
 	// builder.Update(db.T(m)).
 	// Where(
 	// builder.And(
@@ -514,7 +760,40 @@ func ExampleModel_SnippetCRUDByUniqueKeys() {
 	// builder.Comment("User.SoftDeleteByName"),
 	// ).
 	// Set(tbl.AssignmentsByFieldValues(fvs)...),
+	// ); err != nil {
+	// return err
+	// }
+	// return sqlx.AfterDelete(db, m)
+	// }).Do()
+	// }
+}
+
+func ExampleModel_SnippetUpdateWithFVs() {
+	fmt.Println(string(org.SnippetUpdateWithFVs(f, []string{"ID"}, "FetchByID", "UpdateByIDWithFVs").Bytes()))
+	// Output:
+	// tbl := db.T(m)
+	// delete(fvs, "Version")
+	// version := tbl.ColByFieldName("Version")
+	// res, err := db.Exec(
+	// builder.Update(tbl).
+	// Where(
+	// builder.And(
+	// tbl.ColByFieldName("ID").Eq(m.ID),
+	// tbl.ColByFieldName("Version").Eq(m.Version),
+	// ),
+	// builder.Comment("Org.UpdateByIDWithFVs"),
+	// ).
+	// Set(append(tbl.AssignmentsByFieldValues(fvs), version.ValueBy(version.Inc(1)))...),
 	// )
+	// if err != nil {
 	// return err
 	// }
+	// if affected, _ := res.RowsAffected(); affected == 0 {
+	// if err = m.FetchByID(db); err != nil {
+	// return err
+	// }
+	// return sqlx.NewVersionConflictError("Org.UpdateByIDWithFVs")
+	// }
+	// m.Version++
+	// return sqlx.AfterUpdate(db, m)
 }
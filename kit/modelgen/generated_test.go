@@ -0,0 +1,66 @@
+package modelgen_test
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	example "github.com/saitofun/qkit/kit/modelgen/__examples__"
+	"github.com/saitofun/qkit/kit/sqlx"
+	"github.com/saitofun/qkit/kit/sqlx/datatypes"
+	"github.com/saitofun/qkit/kit/sqlx/driver/sqlite"
+	"github.com/saitofun/qkit/kit/sqlx/migration"
+)
+
+func TestGenerated_ListByCursor(t *testing.T) {
+	d := sqlx.NewDatabase("test_list_by_cursor")
+	d.Register(&example.Org{})
+	db := d.OpenDB(&sqlite.Connector{})
+	NewWithT(t).Expect(migration.Migrate(db, nil)).To(BeNil())
+
+	lst := make([]example.Org, 0)
+	for i := 0; i < datatypes.DefaultCursorSize+5; i++ {
+		lst = append(lst, example.Org{Name: fmt.Sprintf("org%d", i), UserID: "user"})
+	}
+	NewWithT(t).Expect((&example.Org{}).BatchCreate(db, lst)).To(BeNil())
+
+	m := &example.Org{}
+
+	t.Run("NilCursor", func(t *testing.T) {
+		page, cursors, err := m.ListByCursor(db, nil, nil)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(page).To(HaveLen(datatypes.DefaultCursorSize))
+		NewWithT(t).Expect(page[0].Name).To(Equal("org0"))
+		NewWithT(t).Expect(cursors.Next).NotTo(BeEmpty())
+		NewWithT(t).Expect(cursors.Prev).To(BeEmpty())
+
+		rest, cursors, err := m.ListByCursor(db, nil, &datatypes.Cursor{Cursor: cursors.Next})
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(rest).To(HaveLen(5))
+		NewWithT(t).Expect(cursors.Next).To(BeEmpty())
+		NewWithT(t).Expect(cursors.Prev).NotTo(BeEmpty())
+	})
+
+	t.Run("ZeroSize", func(t *testing.T) {
+		page, _, err := m.ListByCursor(db, nil, &datatypes.Cursor{})
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(page).To(HaveLen(datatypes.DefaultCursorSize))
+	})
+}
+
+func TestGenerated_BatchCreateHooks(t *testing.T) {
+	d := sqlx.NewDatabase("test_batch_create_hooks")
+	d.Register(&example.Org{})
+	db := d.OpenDB(&sqlite.Connector{})
+	NewWithT(t).Expect(migration.Migrate(db, nil)).To(BeNil())
+
+	lst := []example.Org{{UserID: "1"}, {UserID: "2", Name: "named"}}
+	NewWithT(t).Expect((&example.Org{}).BatchCreate(db, lst)).To(BeNil())
+
+	orgs, err := (&example.Org{}).List(db, nil)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(orgs).To(HaveLen(2))
+	NewWithT(t).Expect(orgs[0].Name).To(Equal("org of 1"))
+	NewWithT(t).Expect(orgs[1].Name).To(Equal("named"))
+}
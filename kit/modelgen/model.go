@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
 
 	g "github.com/saitofun/qkit/gen/codegen"
@@ -74,6 +75,11 @@ func NewModel(pkg *pkgx.Pkg, tn *types.TypeName, doc string, cfg *Config) *Model
 		m.FieldKeyAutoIncrement = col.FieldName
 	}
 
+	if col := m.Table.Version(); col != nil {
+		m.HasVersion = true
+		m.FieldKeyVersion = col.FieldName
+	}
+
 	return &m
 }
 
@@ -84,10 +90,12 @@ type Model struct {
 	*builder.Table
 	Fields                map[string]*types.Var
 	FieldKeyAutoIncrement string
+	FieldKeyVersion       string
 	HasDeletedAt          bool
 	HasCreatedAt          bool
 	HasUpdatedAt          bool
 	HasAutoIncrement      bool
+	HasVersion            bool
 }
 
 func (m *Model) AddColumnAndField(col *builder.Column, tpe *types.Var) {
@@ -291,6 +299,18 @@ func (m *Model) SnippetPrimaryKey(f *g.File) g.Snippet {
 		Do(g.Return(f.Value(m.Keys.Primary)))
 }
 
+// SnippetSoftDeleteFieldName generate below
+// SoftDeleteFieldName implements builder.WithSoftDelete
+// func(`Model`) SoftDeleteFieldName() string
+func (m *Model) SnippetSoftDeleteFieldName(f *g.File) g.Snippet {
+	if !m.HasDeletedAt {
+		return nil
+	}
+	return g.Func().Named("SoftDeleteFieldName").MethodOf(g.Var(m.PtrType())).
+		Return(g.Var(g.String)).
+		Do(g.Return(f.Value(m.FieldKeyDeletedAt)))
+}
+
 // SnippetIndexes generate below
 // Indexes implements builder.WithIndexes
 // func(`Model`) Indexes() builder.Indexes
@@ -387,7 +407,8 @@ func (m *Model) SnippetCondByValue(f *g.File) g.Snippet {
 			g.DeclVar(
 				g.Assign(g.Var(nil, `tbl`)).By(g.Ref(g.Ident(`db`), g.Call(`T`, g.Ident(`m`)))),
 				g.Assign(g.Var(nil, `fvs`)).By(g.Call(f.Use(BuilderPkg, `FieldValueFromStructByNoneZero`), g.Ident(`m`))),
-				m.DeletedAtCondInitial(f, g.Ident(`tbl`), g.Ident(`cond`)),
+				g.Assign(g.Var(nil, `cond`)).
+					By(g.Call(`make`, g.Slice(g.Type(f.Use(BuilderPkg, `SqlCondition`))), f.Value(0))),
 			),
 			g.Exprer(`
 for _, fn := range m.IndexFieldNames() {
@@ -408,22 +429,32 @@ cond = append(cond, tbl.ColByFieldName(fn).Eq(v))
 }
 
 func (m *Model) SetCreatedSnippet(f *g.File) g.Snippet {
+	return m.SetCreatedSnippetOf(f, `m`)
+}
+
+// SetCreatedSnippetOf sets CreatedAt of value v if zero
+func (m *Model) SetCreatedSnippetOf(f *g.File, v string) g.Snippet {
 	if !m.HasCreatedAt {
 		return nil
 	}
 	return g.Exprer(`
-if m.` + m.FieldKeyCreatedAt + `.IsZero() {
-m.` + m.FieldKeyCreatedAt + `.Set(` + f.Use(`time`, `Now`) + `())
+if ` + v + `.` + m.FieldKeyCreatedAt + `.IsZero() {
+` + v + `.` + m.FieldKeyCreatedAt + `.Set(` + f.Use(`time`, `Now`) + `())
 }`)
 }
 
 func (m *Model) SetUpdatedSnippet(f *g.File) g.Snippet {
+	return m.SetUpdatedSnippetOf(f, `m`)
+}
+
+// SetUpdatedSnippetOf sets UpdatedAt of value v if zero
+func (m *Model) SetUpdatedSnippetOf(f *g.File, v string) g.Snippet {
 	if !m.HasUpdatedAt {
 		return nil
 	}
 	return g.Exprer(`
-if m.` + m.FieldKeyUpdatedAt + `.IsZero() {
-m.` + m.FieldKeyUpdatedAt + `.Set(` + f.Use(`time`, `Now`) + `())
+if ` + v + `.` + m.FieldKeyUpdatedAt + `.IsZero() {
+` + v + `.` + m.FieldKeyUpdatedAt + `.Set(` + f.Use(`time`, `Now`) + `())
 }`)
 }
 
@@ -441,30 +472,6 @@ if _, ok := ?[?]; !ok {
 	)
 }
 
-func (m *Model) DeletedAtCondInitial(f *g.File, tbl, cond *g.SnippetIdent) g.SnippetSpec {
-	if !m.HasDeletedAt {
-		return g.Assign(cond).
-			By(g.Call(`make`, g.Slice(g.Type(f.Use(BuilderPkg, `SqlCondition`))), f.Value(0)))
-	}
-	// cond = []builder.SqlCondition{tbl.ColByFieldName("DeletedAt").Eq(0)}
-	return g.Assign(cond).
-		By(g.Exprer(
-			`[]`+f.Use(BuilderPkg, `SqlCondition`)+`{?.ColByFieldName(?).Eq(0)}`,
-			tbl, f.Value(m.FieldKeyDeletedAt),
-		))
-}
-
-func (m *Model) DeletedAtCondAttach(f *g.File, tbl, cond *g.SnippetIdent) g.SnippetSpec {
-	if !m.HasDeletedAt {
-		return nil
-	}
-	return g.Assign(cond).By(g.Call(
-		f.Use(BuilderPkg, `And`),
-		g.Exprer(`?.ColByFieldName(?).Eq(0)`, tbl, m.FieldKeyDeletedAt),
-		cond,
-	))
-}
-
 func (m *Model) SetDeletedSnippetForFVs(f *g.File, fvs *g.SnippetIdent) g.Snippet {
 	if !m.HasUpdatedAt {
 		return nil
@@ -479,6 +486,48 @@ if _, ok := ?[?]; !ok {
 	)
 }
 
+// HookCall calls lifecycle hook of model by sqlx helper, eg:
+// sqlx.BeforeCreate(db, m)
+func (m *Model) HookCall(f *g.File, hook string, args ...string) g.SnippetExpr {
+	return m.HookCallOf(f, hook, `m`, args...)
+}
+
+// HookCallOf calls lifecycle hook of model expression v, eg:
+// sqlx.BeforeCreate(db, &lst[i])
+func (m *Model) HookCallOf(f *g.File, hook string, v string, args ...string) g.SnippetExpr {
+	return g.Exprer(f.Use(SQLxPkg, hook) + `(` + strings.Join(append([]string{`db`, v}, args...), `, `) + `)`)
+}
+
+// HookSnippet calls lifecycle hook of model and returns if hook failed
+func (m *Model) HookSnippet(f *g.File, hook string, args ...string) g.Snippet {
+	return m.HookSnippetOf(f, hook, `m`, args...)
+}
+
+// HookSnippetOf calls lifecycle hook of model expression v and returns if
+// hook failed
+func (m *Model) HookSnippetOf(f *g.File, hook string, v string, args ...string) g.Snippet {
+	return g.Exprer(`if err := ` + string(m.HookCallOf(f, hook, v, args...)) + `; err != nil {
+return err
+}`)
+}
+
+// TxSnippet returns body run in transaction by sqlx.NewTasks, so hooks and
+// writes in body are committed or rolled back together. db in body is the
+// executor of the transaction
+func (m *Model) TxSnippet(f *g.File, body ...g.Snippet) g.Snippet {
+	b := bytes.NewBuffer(nil)
+	b.WriteString(`return ` + f.Use(SQLxPkg, `NewTasks`) + `(db).With(func(db ` +
+		f.Use(SQLxPkg, `DBExecutor`) + `) error {`)
+	for _, s := range body {
+		if s != nil {
+			b.WriteString("\n")
+			b.Write(s.Bytes())
+		}
+	}
+	b.WriteString("\n}).Do()")
+	return g.Exprer(b.String())
+}
+
 // SnippetCreate generate below
 // Create to create record by value m
 // func (m *`Model`) Create(DBExecutor) error // Create by this
@@ -492,9 +541,13 @@ func (m *Model) SnippetCreate(f *g.File) g.Snippet {
 		Do(
 			m.SetCreatedSnippet(f),
 			m.SetUpdatedSnippet(f),
-			g.Exprer(`
-_, err := db.Exec(?(db, m, nil))
-return err`, g.Ident(f.Use(SQLxPkg, `InsertToDB`))),
+			m.TxSnippet(f,
+				m.HookSnippet(f, `BeforeCreate`),
+				g.Exprer(`if _, err := db.Exec(?(db, m, nil)); err != nil {
+return err
+}`, g.Ident(f.Use(SQLxPkg, `InsertToDB`))),
+				g.Return(m.HookCall(f, `AfterCreate`)),
+			),
 		)
 }
 
@@ -519,7 +572,6 @@ func (m *Model) SnippetList(f *g.File) g.Snippet {
 				g.Assign(g.Var(nil, `tbl`)).By(g.Ref(g.Ident(`db`), g.Call(`T`, g.Ident(`m`)))),
 				g.Assign(g.Var(nil, `lst`)).By(g.Call(`make`, g.Slice(g.Type(m.StructName)), g.Valuer(0))),
 			),
-			m.DeletedAtCondAttach(f, g.Ident(`tbl`), g.Ident(`cond`)),
 			g.Assign(g.Var(nil, `adds`)).By(g.Exprer(
 				`append([]`+f.Use(BuilderPkg, `Addition`)+
 					`{`+f.Use(BuilderPkg, `Where`)+`(cond), `+
@@ -545,7 +597,6 @@ func (m *Model) SnippetCount(f *g.File) g.Snippet {
 		Return(g.Var(g.Int64, `cnt`), g.Var(g.Error, `err`)).
 		Do(
 			g.Define(g.Var(nil, `tbl`)).By(g.Ref(g.Ident(`db`), g.Call(`T`, g.Ident(`m`)))),
-			m.DeletedAtCondAttach(f, g.Ident(`tbl`), g.Ident(`cond`)),
 			g.Assign(g.Var(nil, `adds`)).By(g.Exprer(
 				`append([]`+f.Use(BuilderPkg, `Addition`)+
 					`{`+f.Use(BuilderPkg, `Where`)+`(cond), `+
@@ -556,6 +607,275 @@ func (m *Model) SnippetCount(f *g.File) g.Snippet {
 		)
 }
 
+// SnippetBatchCreate generate below
+// BatchCreate to create records by lst in multi-row insert statements, create
+// hooks are called for each record in the same transaction
+// func (m *`Model`) BatchCreate(DBExecutor, []`Model`) error
+func (m *Model) SnippetBatchCreate(f *g.File) g.Snippet {
+	if !m.WithMethods {
+		return nil
+	}
+	return g.Func(
+		g.Var(g.Type(f.Use(SQLxPkg, `DBExecutor`)), `db`),
+		g.Var(g.Slice(g.Type(m.StructName)), `lst`),
+	).Named(`BatchCreate`).MethodOf(g.Var(m.PtrType(), `m`)).
+		Return(g.Var(g.Error)).
+		Do(
+			m.batchModelsSnippet(f),
+			m.TxSnippet(f,
+				m.batchHookSnippet(f, `BeforeCreate`, false),
+				g.Exprer(`if err := ?(db, models); err != nil {
+return err
+}`, g.Ident(f.Use(SQLxPkg, `BatchInsertToDB`))),
+				m.batchHookSnippet(f, `AfterCreate`, false),
+				g.Return(g.Nil),
+			),
+		)
+}
+
+// SnippetBatchUpsert generate below
+// BatchUpsert to create records by lst, and update records conflicted on
+// unique index named conflict. existed records are queried in the same
+// transaction, create hooks are called for records created only
+// func (m *`Model`) BatchUpsert(DBExecutor, []`Model`, string) error
+func (m *Model) SnippetBatchUpsert(f *g.File) g.Snippet {
+	if !m.WithMethods {
+		return nil
+	}
+	args := []g.Snippet{g.Ident(`db`), g.Ident(`models`), g.Ident(`conflict`)}
+	if m.HasCreatedAt {
+		args = append(args, f.Value(m.FieldKeyCreatedAt))
+	}
+	return g.Func(
+		g.Var(g.Type(f.Use(SQLxPkg, `DBExecutor`)), `db`),
+		g.Var(g.Slice(g.Type(m.StructName)), `lst`),
+		g.Var(g.String, `conflict`),
+	).Named(`BatchUpsert`).MethodOf(g.Var(m.PtrType(), `m`)).
+		Return(g.Var(g.Error)).
+		Do(
+			m.batchModelsSnippet(f),
+			m.TxSnippet(f,
+				g.Exprer(`existed, err := ?(db, models, conflict)
+if err != nil {
+return err
+}`, g.Ident(f.Use(SQLxPkg, `BatchExistedByIndex`))),
+				m.batchHookSnippet(f, `BeforeCreate`, true),
+				g.Exprer(`if err := ?; err != nil {
+return err
+}`, g.Call(f.Use(SQLxPkg, `BatchUpsertToDB`), args...)),
+				m.batchHookSnippet(f, `AfterCreate`, true),
+				g.Return(g.Nil),
+			),
+		)
+}
+
+// SnippetBatchDeleteByPrimary generate below, only for single field primary
+// BatchDeleteByXXXs to delete records by values of primary key XXX, records
+// are soft deleted if model has soft delete policy. records are not loaded so
+// delete hooks are not called
+// func (m *`Model`) BatchDeleteByXXXs(DBExecutor, []XXXType) error
+func (m *Model) SnippetBatchDeleteByPrimary(f *g.File) g.Snippet {
+	if !m.WithMethods || len(m.Keys.Primary) != 1 {
+		return nil
+	}
+	fn := m.Keys.Primary[0]
+	return g.Func(
+		g.Var(g.Type(f.Use(SQLxPkg, `DBExecutor`)), `db`),
+		g.Var(g.Slice(m.FileType(f, fn)), `values`),
+	).Named(`BatchDeleteBy`+fn+`s`).MethodOf(g.Var(m.PtrType(), `m`)).
+		Return(g.Var(g.Error)).
+		Do(
+			g.Exprer(`vs := make([]interface{}, 0, len(values))
+for _, v := range values {
+vs = append(vs, v)
+}`),
+			g.Return(g.Call(f.Use(SQLxPkg, `BatchDeleteByValues`),
+				g.Ident(`db`), g.Ident(`m`), f.Value(fn), g.Ident(`vs`))),
+		)
+}
+
+func (m *Model) batchModelsSnippet(f *g.File) g.Snippet {
+	b := bytes.NewBuffer(nil)
+	b.WriteString(`models := make([]` + f.Use(BuilderPkg, `Model`) + `, 0, len(lst))
+for i := range lst {`)
+	for _, s := range []g.Snippet{
+		m.SetCreatedSnippetOf(f, `lst[i]`),
+		m.SetUpdatedSnippetOf(f, `lst[i]`),
+	} {
+		if s != nil {
+			b.Write(s.Bytes())
+		}
+	}
+	b.WriteString(`
+models = append(models, &lst[i])
+}`)
+	return g.Exprer(b.String())
+}
+
+// batchHookSnippet calls hook of each model, models existed are skipped if
+// skipExisted
+func (m *Model) batchHookSnippet(f *g.File, hook string, skipExisted bool) g.Snippet {
+	b := bytes.NewBuffer(nil)
+	b.WriteString("for i := range models {\n")
+	if skipExisted {
+		b.WriteString("if existed[i] {\ncontinue\n}\n")
+	}
+	b.Write(m.HookSnippetOf(f, hook, `models[i]`).Bytes())
+	b.WriteString("\n}")
+	return g.Exprer(b.String())
+}
+
+// SnippetListByCursor generate below, only for model has primary key
+// ListByCursor by condition and keyset pagination ordered by primary key, nil
+// cursor lists the first page and zero size lists default size
+// func (m *`Model`) ListByCursor(DBExecutor, SqlCondition, *Cursor, Additions) ([]`Model`, *Cursors, error)
+func (m *Model) SnippetListByCursor(f *g.File) g.Snippet {
+	if !m.WithMethods || len(m.Keys.Primary) == 0 {
+		return nil
+	}
+
+	var (
+		fields = make([]string, 0, len(m.Keys.Primary))
+		refs   = make([]string, 0, len(m.Keys.Primary))
+		values = func(v string) string {
+			vs := make([]string, 0, len(m.Keys.Primary))
+			for _, fn := range m.Keys.Primary {
+				vs = append(vs, v+"."+fn)
+			}
+			return strings.Join(vs, ", ")
+		}
+	)
+	for _, fn := range m.Keys.Primary {
+		fields = append(fields, strconv.Quote(fn))
+		refs = append(refs, "&key."+fn)
+	}
+
+	return g.Func(
+		g.Var(g.Type(f.Use(SQLxPkg, `DBExecutor`)), `db`),
+		g.Var(g.Type(f.Use(BuilderPkg, `SqlCondition`)), `cond`),
+		g.Var(g.Star(g.Type(f.Use(DatatypesPkg, `Cursor`))), `cur`),
+		g.Var(g.Ellipsis(g.Type(f.Use(BuilderPkg, `Addition`))), `adds`),
+	).Named(`ListByCursor`).MethodOf(g.Var(m.PtrType(), `m`)).
+		Return(
+			g.Var(g.Slice(g.Type(m.StructName))),
+			g.Var(g.Star(g.Type(f.Use(DatatypesPkg, `Cursors`)))),
+			g.Var(g.Error),
+		).
+		Do(
+			g.Assign(g.Ident(`cur`)).By(g.Ref(g.Ident(`cur`), g.Call(`WithDefault`))),
+			g.DeclVar(
+				g.Assign(g.Var(nil, `tbl`)).By(g.Ref(g.Ident(`db`), g.Call(`T`, g.Ident(`m`)))),
+				g.Assign(g.Var(nil, `lst`)).By(g.Call(`make`, g.Slice(g.Type(m.StructName)), g.Valuer(0))),
+				g.Assign(g.Var(nil, `cols`)).By(g.Exprer(`tbl.MustColsByFieldNames(`+strings.Join(fields, ", ")+`).List()`)),
+				g.Assign(g.Var(nil, `cursors`)).By(g.Exprer(`&`+f.Use(DatatypesPkg, `Cursors`)+`{}`)),
+			),
+			g.Exprer(`if cur.Cursor != "" {
+key := &`+m.StructName+`{}
+if err := cur.Decode(`+strings.Join(refs, ", ")+`); err != nil {
+return nil, nil, err
+}
+cond = `+f.Use(BuilderPkg, `And`)+`(cond, `+f.Use(BuilderPkg, `Seek`)+`(cur.IsPrev(), cols, `+values("key")+`))
+}`),
+			g.Assign(g.Var(nil, `adds`)).By(g.Exprer(
+				`append([]`+f.Use(BuilderPkg, `Addition`)+
+					`{`+f.Use(BuilderPkg, `Where`)+`(cond), `+
+					f.Use(BuilderPkg, `SeekOrderBy`)+`(cur.IsPrev(), cols...), cur.Addition(), `+
+					f.Use(BuilderPkg, `Comment`)+`("`+m.StructName+`.ListByCursor")}, adds...)`,
+			)),
+			g.Exprer(`if err := db.QueryAndScan(`+f.Use(BuilderPkg, `Select`)+`(nil).From(tbl, adds...), &lst); err != nil {
+return nil, nil, err
+}
+size, next, prev := cur.Page(len(lst))
+lst = lst[:size]
+if cur.IsPrev() {
+for i, j := 0, len(lst)-1; i < j; i, j = i+1, j-1 {
+lst[i], lst[j] = lst[j], lst[i]
+}
+}
+var err error
+if next {
+last := &lst[len(lst)-1]
+if cursors.Next, err = `+f.Use(DatatypesPkg, `EncodeCursor`)+`(`+values("last")+`); err != nil {
+return nil, nil, err
+}
+}
+if prev {
+first := &lst[0]
+if cursors.Prev, err = `+f.Use(DatatypesPkg, `EncodeCursor`)+`(`+values("first")+`); err != nil {
+return nil, nil, err
+}
+}`),
+			g.Return(g.Ident(`lst`), g.Ident(`cursors`), g.Nil),
+		)
+}
+
+// SnippetSoftDeleteRestorePurge generate below, only for model has DeletedAt
+// SoftDelete to set DeletedAt of records alive matched condition
+// func (m *`Model`) SoftDelete(DBExecutor, SqlCondition) error
+// Restore to reset DeletedAt of records soft deleted matched condition
+// func (m *`Model`) Restore(DBExecutor, SqlCondition) error
+// Purge to delete records soft deleted matched condition permanently
+// func (m *`Model`) Purge(DBExecutor, SqlCondition) error
+// records matched condition are not loaded, so lifecycle hooks are not called
+// by SoftDelete, Restore and Purge
+func (m *Model) SnippetSoftDeleteRestorePurge(f *g.File) []g.Snippet {
+	if !m.WithMethods || !m.HasDeletedAt {
+		return nil
+	}
+
+	args := func() []*g.SnippetField {
+		return []*g.SnippetField{
+			g.Var(g.Type(f.Use(SQLxPkg, `DBExecutor`)), `db`),
+			g.Var(g.Type(f.Use(BuilderPkg, `SqlCondition`)), `cond`),
+		}
+	}
+
+	return []g.Snippet{
+		g.Func(args()...).Named(`SoftDelete`).MethodOf(g.Var(m.PtrType(), `m`)).
+			Return(g.Var(g.Error)).
+			Do(
+				g.Exprer(`tbl := db.T(m)
+fvs := `+f.Use(BuilderPkg, `FieldValues`)+`{}`),
+				m.SetDeletedSnippetForFVs(f, g.Ident(`fvs`)),
+				m.SetUpdatedSnippetForFVs(f, g.Ident(`fvs`)),
+				g.Exprer(`_, err := db.Exec(
+`+f.Use(BuilderPkg, `Update`)+`(tbl).
+Where(cond, `+f.Use(BuilderPkg, `Comment`)+`(?)).
+Set(tbl.AssignmentsByFieldValues(fvs)...),
+)
+return err`, f.Value(m.StructName+".SoftDelete")),
+			),
+		g.Func(args()...).Named(`Restore`).MethodOf(g.Var(m.PtrType(), `m`)).
+			Return(g.Var(g.Error)).
+			Do(
+				g.Exprer(`tbl := db.T(m)
+fvs := `+f.Use(BuilderPkg, `FieldValues`)+`{?: 0}`, f.Value(m.FieldKeyDeletedAt)),
+				m.SetUpdatedSnippetForFVs(f, g.Ident(`fvs`)),
+				g.Exprer(`_, err := db.Exec(
+`+f.Use(BuilderPkg, `Update`)+`(tbl).
+Where(cond, `+f.Use(BuilderPkg, `OnlyDeleted`)+`(), `+f.Use(BuilderPkg, `Comment`)+`(?)).
+Set(tbl.AssignmentsByFieldValues(fvs)...),
+)
+return err`, f.Value(m.StructName+".Restore")),
+			),
+		g.Func(args()...).Named(`Purge`).MethodOf(g.Var(m.PtrType(), `m`)).
+			Return(g.Var(g.Error)).
+			Do(
+				g.Exprer(`tbl := db.T(m)
+cond = `+f.Use(BuilderPkg, `And`)+`(`+f.Use(BuilderPkg, `SoftDeleteCondition`)+`(tbl, `+f.Use(BuilderPkg, `OnlyDeleted`)+`()), cond)
+_, err := db.Exec(
+`+f.Use(BuilderPkg, `Delete`)+`().
+From(
+tbl,
+`+f.Use(BuilderPkg, `Where`)+`(cond),
+`+f.Use(BuilderPkg, `Comment`)+`(?),
+),
+)
+return err`, f.Value(m.StructName+".Purge")),
+			),
+	}
+}
+
 func IndexCond(f *g.File, fns ...string) string {
 	b := bytes.NewBuffer(nil)
 	b.WriteString(f.Use(BuilderPkg, "And(\n"))
@@ -567,6 +887,61 @@ func IndexCond(f *g.File, fns ...string) string {
 	return b.String()
 }
 
+// SnippetUpdateWithFVs generate update by unique index fns, if model has
+// version field, the version is checked and increased for optimistic locking
+func (m *Model) SnippetUpdateWithFVs(f *g.File, fns []string, fetchBy, name string) g.Snippet {
+	if !m.HasVersion {
+		return g.Exprer(`tbl := db.T(m)
+res, err := db.Exec(
+`+f.Use(BuilderPkg, `Update`)+`(tbl).
+Where(
+`+IndexCond(f, fns...)+`
+`+f.Use(BuilderPkg, `Comment`)+`(?),
+).
+Set(tbl.AssignmentsByFieldValues(fvs)...),
+)
+if err != nil {
+return err
+}
+if affected, _ := res.RowsAffected(); affected == 0 {
+if err = m.`+fetchBy+`(db); err != nil {
+return err
+}
+}
+return `+string(m.HookCall(f, `AfterUpdate`)),
+			f.Value(m.StructName+"."+name),
+		)
+	}
+
+	return g.Exprer(`tbl := db.T(m)
+delete(fvs, ?)
+version := tbl.ColByFieldName(?)
+res, err := db.Exec(
+`+f.Use(BuilderPkg, `Update`)+`(tbl).
+Where(
+`+IndexCond(f, append(fns[:len(fns):len(fns)], m.FieldKeyVersion)...)+`
+`+f.Use(BuilderPkg, `Comment`)+`(?),
+).
+Set(append(tbl.AssignmentsByFieldValues(fvs), version.ValueBy(version.Inc(1)))...),
+)
+if err != nil {
+return err
+}
+if affected, _ := res.RowsAffected(); affected == 0 {
+if err = m.`+fetchBy+`(db); err != nil {
+return err
+}
+return `+f.Use(SQLxPkg, `NewVersionConflictError`)+`(?)
+}
+m.`+m.FieldKeyVersion+`++
+return `+string(m.HookCall(f, `AfterUpdate`)),
+		f.Value(m.FieldKeyVersion),
+		f.Value(m.FieldKeyVersion),
+		f.Value(m.StructName+"."+name),
+		f.Value(m.StructName+"."+name),
+	)
+}
+
 // SnippetCRUDByUniqueKeys generate below
 // UpdateByXXX to update record by value m
 // XXX is UniqueIndexNames contacted by `And`; function name like `UpdateByNameAndIDAnd...`
@@ -592,17 +967,22 @@ func (m *Model) SnippetCRUDByUniqueKeys(f *g.File, keys ...string) []g.Snippet {
 			Named("Delete").MethodOf(g.Var(m.PtrType(), `m`)).
 			Return(g.Var(g.Error)).
 			Do(
-				g.Exprer(`_, err := db.Exec(
+				m.TxSnippet(f,
+					m.HookSnippet(f, `BeforeDelete`),
+					g.Exprer(`if _, err := db.Exec(
 `+f.Use(BuilderPkg, `Delete`)+`().
 From(
 db.T(m),
 `+f.Use(BuilderPkg, `Where`)+`(m.CondByValue(db)),
 `+f.Use(BuilderPkg, `Comment`)+`(?),
 ),
-)`,
-					f.Value(m.StructName+".Delete"),
+); err != nil {
+return err
+}`,
+						f.Value(m.StructName+".Delete"),
+					),
+					g.Return(m.HookCall(f, `AfterDelete`)),
 				),
-				g.Return(g.Ident(`err`)),
 			),
 	}
 
@@ -668,23 +1048,9 @@ m,
 				Return(g.Var(g.Error)).
 				Do(
 					m.SetUpdatedSnippetForFVs(f, g.Ident(`fvs`)),
-					g.Exprer(`tbl := db.T(m)
-res, err := db.Exec(
-`+f.Use(BuilderPkg, `Update`)+`(tbl).
-Where(
-`+IndexCond(f, fns...)+`
-`+f.Use(BuilderPkg, `Comment`)+`(?),
-).
-Set(tbl.AssignmentsByFieldValues(fvs)...),
-)
-if err != nil {
-return err
-}
-if affected, _ := res.RowsAffected(); affected == 0 {
-return m.`+mthNameFetchBy+`(db)
-}
-return nil`,
-						f.Value(m.StructName+"."+mthNameUpdateByWithFVs),
+					m.TxSnippet(f,
+						m.HookSnippet(f, `BeforeUpdate`, `fvs`),
+						m.SnippetUpdateWithFVs(f, fns, mthNameFetchBy, mthNameUpdateByWithFVs),
 					),
 				),
 		)
@@ -712,8 +1078,10 @@ return m.`+mthNameUpdateByWithFVs+`(db, fvs)`,
 				Named(mthNameDeleteBy).MethodOf(g.Var(m.PtrType(), `m`)).
 				Return(g.Var(g.Error)).
 				Do(
-					g.Exprer(`tbl := db.T(m)
-_, err := db.Exec(
+					m.TxSnippet(f,
+						m.HookSnippet(f, `BeforeDelete`),
+						g.Exprer(`tbl := db.T(m)
+if _, err := db.Exec(
 `+f.Use(BuilderPkg, `Delete`)+`().
 From(
 tbl,
@@ -722,10 +1090,13 @@ tbl,
 ),
 `+f.Use(BuilderPkg, `Comment`)+`(?),
 ),
-)`,
-						f.Value(m.StructName+"."+mthNameDeleteBy),
+); err != nil {
+return err
+}`,
+							f.Value(m.StructName+"."+mthNameDeleteBy),
+						),
+						g.Return(m.HookCall(f, `AfterDelete`)),
 					),
-					g.Return(g.Ident(`err`)),
 				),
 		)
 
@@ -736,20 +1107,25 @@ tbl,
 					Named(mthNameSoftDeleteBy).MethodOf(g.Var(m.PtrType(), `m`)).
 					Return(g.Var(g.Error)).
 					Do(
-						g.Exprer(`tbl := db.T(m)
-fvs := `+f.Use(BuilderPkg, `FieldValues`)+`{}`),
+						g.Exprer(`fvs := `+f.Use(BuilderPkg, `FieldValues`)+`{}`),
 						m.SetDeletedSnippetForFVs(f, g.Ident(`fvs`)),
 						m.SetUpdatedSnippetForFVs(f, g.Ident(`fvs`)),
-						g.Exprer(`_, err := db.Exec(
+						m.TxSnippet(f,
+							m.HookSnippet(f, `BeforeDelete`),
+							g.Exprer(`tbl := db.T(m)
+if _, err := db.Exec(
 `+f.Use(BuilderPkg, `Update`)+`(db.T(m)).
 Where(
 `+IndexCond(f, fns...)+`
 `+f.Use(BuilderPkg, `Comment`)+`(?),
 ).
 Set(tbl.AssignmentsByFieldValues(fvs)...),
-)
-return err`,
-							f.Value(m.StructName+"."+mthNameSoftDeleteBy)),
+); err != nil {
+return err
+}`,
+								f.Value(m.StructName+"."+mthNameSoftDeleteBy)),
+							g.Return(m.HookCall(f, `AfterDelete`)),
+						),
 					),
 			)
 		}
@@ -767,6 +1143,7 @@ func (m *Model) WriteTo(f *g.File) {
 	snippets = append(snippets, m.SnippetColDesc(f))
 	snippets = append(snippets, m.SnippetColRel(f))
 	snippets = append(snippets, m.SnippetPrimaryKey(f))
+	snippets = append(snippets, m.SnippetSoftDeleteFieldName(f))
 	snippets = append(snippets, m.SnippetIndexes(f))
 	snippets = append(snippets, m.SnippetIndexFieldNames(f))
 	snippets = append(snippets, m.SnippetUniqueIndexes(f)...)
@@ -775,14 +1152,20 @@ func (m *Model) WriteTo(f *g.File) {
 	snippets = append(snippets, m.SnippetCreate(f))
 	snippets = append(snippets, m.SnippetList(f))
 	snippets = append(snippets, m.SnippetCount(f))
+	snippets = append(snippets, m.SnippetListByCursor(f))
+	snippets = append(snippets, m.SnippetSoftDeleteRestorePurge(f)...)
+	snippets = append(snippets, m.SnippetBatchCreate(f))
+	snippets = append(snippets, m.SnippetBatchUpsert(f))
+	snippets = append(snippets, m.SnippetBatchDeleteByPrimary(f))
 	snippets = append(snippets, m.SnippetCRUDByUniqueKeys(f)...)
 
 	f.WriteSnippet(snippets...)
 }
 
 var (
-	BuilderPkg = "github.com/saitofun/qkit/kit/sqlx/builder"
-	SQLxPkg    = "github.com/saitofun/qkit/kit/sqlx"
+	BuilderPkg   = "github.com/saitofun/qkit/kit/sqlx/builder"
+	SQLxPkg      = "github.com/saitofun/qkit/kit/sqlx"
+	DatatypesPkg = "github.com/saitofun/qkit/kit/sqlx/datatypes"
 )
 
 func init() {
@@ -791,4 +1174,6 @@ func init() {
 	SQLxPkg = must.String(pkgx.PkgIdByPath(dir))
 	dir = filepath.Join(filepath.Dir(current), "../sqlx/builder")
 	BuilderPkg = must.String(pkgx.PkgIdByPath(dir))
+	dir = filepath.Join(filepath.Dir(current), "../sqlx/datatypes")
+	DatatypesPkg = must.String(pkgx.PkgIdByPath(dir))
 }
@@ -0,0 +1,108 @@
+package outbox
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/saitofun/qkit/base/types"
+	"github.com/saitofun/qkit/kit/mq"
+	"github.com/saitofun/qkit/kit/sqlx"
+	"github.com/saitofun/qkit/kit/sqlx/builder"
+)
+
+// Outbox rows of tasks dispatched in transaction, it should be registered to
+// database to be created by migration, eg: d.Register(&outbox.Outbox{}).
+// rows committed are moved to mq.TaskManager by Relay
+type Outbox struct {
+	ID        uint64          `db:"f_id,autoincrement"`
+	Channel   string          `db:"f_channel,size=128"`
+	TaskUUID  string          `db:"f_task_uuid,size=64"`
+	Subject   string          `db:"f_subject,size=128"`
+	Arg       []byte          `db:"f_arg,null"`
+	CreatedAt types.Timestamp `db:"f_created_at,default='0'"`
+	// ClaimedUntil lease of row claimed by relay
+	ClaimedUntil types.Timestamp `db:"f_claimed_until,default='0'"`
+	// RelayedAt when task pushed by relay, row relayed is kept as tombstone
+	// to dedup the task until purged
+	RelayedAt types.Timestamp `db:"f_relayed_at,default='0'"`
+}
+
+func (Outbox) TableName() string { return "t_mq_outbox" }
+
+func (Outbox) PrimaryKey() []string { return []string{"ID"} }
+
+func (Outbox) UniqueIndexes() builder.Indexes {
+	return builder.Indexes{"ui_task": {"Channel", "TaskUUID"}}
+}
+
+func (Outbox) Indexes() builder.Indexes {
+	return builder.Indexes{"i_relayed_at": {"RelayedAt"}}
+}
+
+var ErrOutboxNotRegistered = errors.New("outbox table is not registered")
+
+// Dispatch inserts task to outbox by db, so the task is committed or rolled
+// back with the transaction of db. task dispatched again with the same
+// TaskUUID is ignored until tombstone of the relayed row purged
+func Dispatch(db sqlx.DBExecutor, ch string, t mq.Task) error {
+	if t == nil {
+		return nil
+	}
+	tbl := db.T(&Outbox{})
+	if tbl == nil {
+		return ErrOutboxNotRegistered
+	}
+
+	arg, err := marshalArg(t)
+	if err != nil {
+		return err
+	}
+
+	cols := tbl.MustColsByFieldNames("Channel", "TaskUUID", "Subject", "Arg", "CreatedAt")
+	_, err = db.Exec(
+		builder.Insert().
+			Into(tbl, builder.OnConflict(tbl.MustColsByFieldNames("Channel", "TaskUUID")).DoNothing()).
+			Values(cols, ch, t.ID(), t.Subject(), arg, types.AsTimestamp(time.Now())),
+	)
+	return err
+}
+
+// DispatchTask returns sqlx.Task dispatching task, eg:
+// sqlx.NewTasks(db).With(createOrder, outbox.DispatchTask(ch, t)).Do()
+func DispatchTask(ch string, t mq.Task) sqlx.Task {
+	return func(db sqlx.DBExecutor) error {
+		return Dispatch(db, ch, t)
+	}
+}
+
+// Task is the task restored from outbox
+type Task struct {
+	mq.TaskHeader
+	arg []byte
+}
+
+var _ interface {
+	mq.Task
+	mq.WithArg
+} = (*Task)(nil)
+
+func (t *Task) Arg() interface{} { return t.arg }
+
+func marshalArg(t mq.Task) ([]byte, error) {
+	with, ok := t.(mq.WithArg)
+	if !ok {
+		return nil, nil
+	}
+	switch arg := with.Arg().(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return arg, nil
+	case string:
+		return []byte(arg), nil
+	default:
+		return json.Marshal(arg)
+	}
+}
@@ -0,0 +1,197 @@
+package outbox_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+
+	"github.com/saitofun/qkit/base/types"
+	"github.com/saitofun/qkit/kit/mq"
+	"github.com/saitofun/qkit/kit/mq/mem_mq"
+	"github.com/saitofun/qkit/kit/mq/outbox"
+	"github.com/saitofun/qkit/kit/sqlx"
+	"github.com/saitofun/qkit/kit/sqlx/builder"
+	"github.com/saitofun/qkit/kit/sqlx/driver/sqlite"
+	"github.com/saitofun/qkit/kit/sqlx/migration"
+)
+
+type Task struct {
+	mq.TaskHeader
+	arg string
+}
+
+func (t *Task) Arg() interface{} { return t.arg }
+
+func NewTask(id, arg string) *Task {
+	t := &Task{arg: arg}
+	t.SetID(id)
+	t.SetSubject("Subject")
+	return t
+}
+
+type FailedTaskManager struct {
+	mq.TaskManager
+	failed string
+}
+
+func (tm *FailedTaskManager) Push(ch string, t mq.Task) error {
+	if t.ID() == tm.failed {
+		return errors.New("push failed")
+	}
+	return tm.TaskManager.Push(ch, t)
+}
+
+func TestOutbox(t *testing.T) {
+	d := sqlx.NewDatabase("test_outbox")
+	tbl := d.Register(&outbox.Outbox{})
+	db := d.OpenDB(&sqlite.Connector{})
+	NewWithT(t).Expect(migration.Migrate(db, nil)).To(BeNil())
+
+	tm := mem_mq.New(10)
+	ch := "outbox"
+
+	relayedAt := tbl.ColByFieldName("RelayedAt")
+
+	// count rows not relayed
+	count := func() int {
+		n := 0
+		err := db.QueryAndScan(builder.Select(builder.Count()).From(tbl,
+			builder.Where(relayedAt.Eq(types.TimestampZero)),
+		), &n)
+		NewWithT(t).Expect(err).To(BeNil())
+		return n
+	}
+
+	// count tombstones of rows relayed
+	tombstones := func() int {
+		n := 0
+		err := db.QueryAndScan(builder.Select(builder.Count()).From(tbl,
+			builder.Where(relayedAt.Neq(types.TimestampZero)),
+		), &n)
+		NewWithT(t).Expect(err).To(BeNil())
+		return n
+	}
+
+	t.Run("Rollback", func(t *testing.T) {
+		err := sqlx.NewTasks(db).With(
+			outbox.DispatchTask(ch, NewTask("1", "a")),
+			func(db sqlx.DBExecutor) error { return errors.New("failed") },
+		).Do()
+		NewWithT(t).Expect(err).NotTo(BeNil())
+		NewWithT(t).Expect(count()).To(Equal(0))
+	})
+
+	t.Run("Commit", func(t *testing.T) {
+		err := sqlx.NewTasks(db).With(
+			outbox.DispatchTask(ch, NewTask("1", "a")),
+			outbox.DispatchTask(ch, NewTask("1", "a")),
+			outbox.DispatchTask(ch, NewTask("2", "b")),
+		).Do()
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(count()).To(Equal(2))
+	})
+
+	t.Run("Relay", func(t *testing.T) {
+		relay := outbox.NewRelay(db, tm, outbox.WithBatchSize(1))
+
+		for _, expect := range []struct{ id, arg string }{{"1", "a"}, {"2", "b"}} {
+			n, err := relay.RelayOnce()
+			NewWithT(t).Expect(err).To(BeNil())
+			NewWithT(t).Expect(n).To(Equal(1))
+
+			task, err := tm.Pop(ch)
+			NewWithT(t).Expect(err).To(BeNil())
+			NewWithT(t).Expect(task.ID()).To(Equal(expect.id))
+			NewWithT(t).Expect(task.Subject()).To(Equal("Subject"))
+			NewWithT(t).Expect(task.(mq.WithArg).Arg()).To(Equal([]byte(expect.arg)))
+		}
+
+		n, err := relay.RelayOnce()
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(n).To(Equal(0))
+		NewWithT(t).Expect(count()).To(Equal(0))
+		NewWithT(t).Expect(tombstones()).To(Equal(2))
+	})
+
+	t.Run("Tombstone", func(t *testing.T) {
+		// task relayed and in flight is not dispatched again
+		NewWithT(t).Expect(outbox.Dispatch(db, ch, NewTask("1", "a"))).To(BeNil())
+		NewWithT(t).Expect(count()).To(Equal(0))
+
+		n, err := outbox.NewRelay(db, tm).RelayOnce()
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(n).To(Equal(0))
+
+		// tombstones are purged after retention
+		_, err = db.Exec(builder.Update(tbl).Where(relayedAt.Neq(types.TimestampZero)).Set(
+			relayedAt.ValueBy(types.AsTimestamp(time.Now().Add(-2 * time.Hour))),
+		))
+		NewWithT(t).Expect(err).To(BeNil())
+
+		n, err = outbox.NewRelay(db, tm, outbox.WithRetention(time.Hour)).RelayOnce()
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(n).To(Equal(0))
+		NewWithT(t).Expect(tombstones()).To(Equal(0))
+
+		NewWithT(t).Expect(outbox.Dispatch(db, ch, NewTask("1", "a"))).To(BeNil())
+		n, err = outbox.NewRelay(db, tm).RelayOnce()
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(n).To(Equal(1))
+
+		task, err := tm.Pop(ch)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(task.ID()).To(Equal("1"))
+	})
+
+	t.Run("PushFailed", func(t *testing.T) {
+		err := sqlx.NewTasks(db).With(
+			outbox.DispatchTask(ch, NewTask("3", "c")),
+			outbox.DispatchTask(ch, NewTask("4", "d")),
+		).Do()
+		NewWithT(t).Expect(err).To(BeNil())
+
+		failed := &FailedTaskManager{TaskManager: tm, failed: "4"}
+		n, err := outbox.NewRelay(db, failed).RelayOnce()
+		NewWithT(t).Expect(err).NotTo(BeNil())
+		NewWithT(t).Expect(n).To(Equal(1))
+		NewWithT(t).Expect(count()).To(Equal(1))
+
+		// rows not pushed are released
+		n, err = outbox.NewRelay(db, tm).RelayOnce()
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(n).To(Equal(1))
+		NewWithT(t).Expect(count()).To(Equal(0))
+
+		for _, id := range []string{"3", "4"} {
+			task, err := tm.Pop(ch)
+			NewWithT(t).Expect(err).To(BeNil())
+			NewWithT(t).Expect(task.ID()).To(Equal(id))
+		}
+	})
+
+	t.Run("Claimed", func(t *testing.T) {
+		NewWithT(t).Expect(outbox.Dispatch(db, ch, NewTask("5", "e"))).To(BeNil())
+
+		// rows claimed by a crashed relay are hidden until lease expired
+		_, err := db.Exec(builder.Update(tbl).Set(
+			tbl.ColByFieldName("ClaimedUntil").ValueBy(types.AsTimestamp(time.Now().Add(time.Minute))),
+		))
+		NewWithT(t).Expect(err).To(BeNil())
+
+		n, err := outbox.NewRelay(db, tm).RelayOnce()
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(n).To(Equal(0))
+
+		_, err = db.Exec(builder.Update(tbl).Set(
+			tbl.ColByFieldName("ClaimedUntil").ValueBy(types.AsTimestamp(time.Now().Add(-time.Second))),
+		))
+		NewWithT(t).Expect(err).To(BeNil())
+
+		n, err = outbox.NewRelay(db, tm).RelayOnce()
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(n).To(Equal(1))
+		NewWithT(t).Expect(count()).To(Equal(0))
+	})
+}
@@ -0,0 +1,216 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/saitofun/qkit/base/types"
+	"github.com/saitofun/qkit/conf/log"
+	"github.com/saitofun/qkit/kit/mq"
+	"github.com/saitofun/qkit/kit/sqlx"
+	"github.com/saitofun/qkit/kit/sqlx/builder"
+)
+
+type Option func(*Relay)
+
+// WithBatchSize sets max count of rows relayed each round, default 100
+func WithBatchSize(n int) Option {
+	return func(r *Relay) { r.batch = n }
+}
+
+// WithInterval sets waiting duration when outbox is empty or relay failed,
+// default 1 second
+func WithInterval(d time.Duration) Option {
+	return func(r *Relay) { r.interval = d }
+}
+
+// WithLease sets duration rows claimed by a relay are hidden from others,
+// rows are claimed again after lease expired if the relay crashed before
+// marked them relayed, default 1 minute
+func WithLease(d time.Duration) Option {
+	return func(r *Relay) { r.lease = d }
+}
+
+// WithRetention sets duration tombstones of relayed rows are kept to dedup
+// tasks, default 24 hours
+func WithRetention(d time.Duration) Option {
+	return func(r *Relay) { r.retention = d }
+}
+
+func NewRelay(db sqlx.DBExecutor, tm mq.TaskManager, options ...Option) *Relay {
+	r := &Relay{
+		db:        db,
+		tm:        tm,
+		batch:     100,
+		interval:  time.Second,
+		lease:     time.Minute,
+		retention: 24 * time.Hour,
+	}
+	for _, opt := range options {
+		opt(r)
+	}
+	return r
+}
+
+// Relay moves committed outbox rows into mq.TaskManager in order of
+// dispatched. a batch of rows is claimed with a lease in a short transaction,
+// rows are locked by FOR UPDATE SKIP LOCKED on postgres, so relays running
+// concurrently never claim the same rows. claimed rows are pushed out of
+// transaction and marked relayed after pushed. rows relayed are kept as
+// tombstones until retention passed, so tasks relayed are never pushed again
+// even popped and in flight, and dispatched again with the same TaskUUID are
+// ignored. rows not pushed are released to be relayed next round, and rows of
+// crashed relay are claimed again after lease expired, so tasks are delivered
+// at least once. task queued with the same TaskUUID is removed before pushed
+// to dedup. order is kept within a relay, but not between batches of
+// concurrent relays.
+type Relay struct {
+	db        sqlx.DBExecutor
+	tm        mq.TaskManager
+	batch     int
+	interval  time.Duration
+	lease     time.Duration
+	retention time.Duration
+}
+
+// RelayOnce relays a batch of outbox rows, returns count of rows relayed
+func (r *Relay) RelayOnce() (int, error) {
+	tbl := r.db.T(&Outbox{})
+	if tbl == nil {
+		return 0, ErrOutboxNotRegistered
+	}
+
+	if err := r.purge(tbl); err != nil {
+		return 0, err
+	}
+
+	rows, err := r.claim(tbl)
+	if err != nil || len(rows) == 0 {
+		return 0, err
+	}
+
+	var (
+		relayed = make([]interface{}, 0, len(rows))
+		pushErr error
+	)
+	for i := range rows {
+		if pushErr = r.push(&rows[i]); pushErr != nil {
+			break
+		}
+		relayed = append(relayed, rows[i].ID)
+	}
+
+	if len(relayed) > 0 {
+		if _, err = r.db.Exec(
+			builder.Update(tbl).
+				Where(tbl.ColByFieldName("ID").In(relayed...)).
+				Set(tbl.ColByFieldName("RelayedAt").ValueBy(types.AsTimestamp(time.Now()))),
+		); err != nil {
+			return 0, err
+		}
+	}
+
+	if pushErr != nil {
+		released := make([]interface{}, 0, len(rows)-len(relayed))
+		for i := len(relayed); i < len(rows); i++ {
+			released = append(released, rows[i].ID)
+		}
+		if _, err = r.db.Exec(
+			builder.Update(tbl).
+				Where(tbl.ColByFieldName("ID").In(released...)).
+				Set(tbl.ColByFieldName("ClaimedUntil").ValueBy(types.TimestampZero)),
+		); err != nil {
+			return len(relayed), err
+		}
+	}
+	return len(relayed), pushErr
+}
+
+// claim selects a batch of rows not claimed or lease expired, and claims them
+// with lease
+func (r *Relay) claim(tbl *builder.Table) ([]Outbox, error) {
+	var (
+		rows []Outbox
+		now  = time.Now()
+	)
+
+	err := sqlx.NewTasks(r.db).With(
+		func(db sqlx.DBExecutor) error {
+			adds := []builder.Addition{
+				builder.Where(builder.And(
+					tbl.ColByFieldName("RelayedAt").Eq(types.TimestampZero),
+					tbl.ColByFieldName("ClaimedUntil").Lte(types.AsTimestamp(now)),
+				)),
+				builder.OrderBy(builder.AscOrder(tbl.ColByFieldName("ID"))),
+				builder.Limit(int64(r.batch)),
+			}
+			if db.Dialect().DriverName() == "postgres" {
+				adds = append(adds, builder.AsAddition(builder.Expr("FOR UPDATE SKIP LOCKED")))
+			}
+			return db.QueryAndScan(builder.Select(nil).From(tbl, adds...), &rows)
+		},
+		func(db sqlx.DBExecutor) error {
+			if len(rows) == 0 {
+				return nil
+			}
+			ids := make([]interface{}, 0, len(rows))
+			for i := range rows {
+				ids = append(ids, rows[i].ID)
+			}
+			_, err := db.Exec(
+				builder.Update(tbl).
+					Where(tbl.ColByFieldName("ID").In(ids...)).
+					Set(tbl.ColByFieldName("ClaimedUntil").ValueBy(types.AsTimestamp(now.Add(r.lease)))),
+			)
+			return err
+		},
+	).Do()
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// purge deletes tombstones of rows relayed before retention
+func (r *Relay) purge(tbl *builder.Table) error {
+	relayedAt := tbl.ColByFieldName("RelayedAt")
+	_, err := r.db.Exec(builder.Delete().From(tbl, builder.Where(builder.And(
+		relayedAt.Neq(types.TimestampZero),
+		relayedAt.Lt(types.AsTimestamp(time.Now().Add(-r.retention))),
+	))))
+	return err
+}
+
+// Run relays outbox rows until ctx done
+func (r *Relay) Run(ctx context.Context) {
+	l := log.FromContext(ctx)
+
+	for {
+		n, err := r.RelayOnce()
+		if err != nil {
+			l.Warn(errors.Wrap(err, "outbox relay failed"))
+		}
+		wait := time.Duration(0)
+		if err != nil || n < r.batch {
+			wait = r.interval
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (r *Relay) push(row *Outbox) error {
+	t := &Task{arg: row.Arg}
+	t.SetID(row.TaskUUID)
+	t.SetSubject(row.Subject)
+
+	if err := r.tm.Remove(row.Channel, row.TaskUUID); err != nil {
+		return err
+	}
+	return r.tm.Push(row.Channel, t)
+}
@@ -1,3 +1,81 @@
 package redis_mq
 
-// TODO
+import (
+	"encoding/json"
+
+	"github.com/saitofun/qkit/kit/mq"
+)
+
+// Task is the task restored from redis, state changes are written back to
+// the TaskManager which popped it
+type Task struct {
+	mq.TaskHeader
+	arg []byte
+
+	tm *TaskManager
+	ch string
+}
+
+var _ interface {
+	mq.Task
+	mq.WithArg
+} = (*Task)(nil)
+
+func (t *Task) Arg() interface{} { return t.arg }
+
+func (t *Task) SetState(s mq.TaskState) {
+	t.TaskHeader.SetState(s)
+	if t.tm != nil {
+		_ = t.tm.setState(t.ch, t.ID(), s)
+	}
+}
+
+// Renew extends lease of task for another visibility timeout of the
+// TaskManager, handlers running longer than it should renew periodically
+func (t *Task) Renew() error {
+	if t.tm == nil {
+		return nil
+	}
+	return t.tm.Renew(t.ch, t.ID())
+}
+
+type envelope struct {
+	ID      string       `json:"id"`
+	Subject string       `json:"subject"`
+	State   mq.TaskState `json:"state"`
+	Arg     []byte       `json:"arg,omitempty"`
+}
+
+func marshal(t mq.Task) ([]byte, error) {
+	e := &envelope{ID: t.ID(), Subject: t.Subject(), State: t.State()}
+
+	if with, ok := t.(mq.WithArg); ok {
+		switch arg := with.Arg().(type) {
+		case nil:
+		case []byte:
+			e.Arg = arg
+		case string:
+			e.Arg = []byte(arg)
+		default:
+			data, err := json.Marshal(arg)
+			if err != nil {
+				return nil, err
+			}
+			e.Arg = data
+		}
+	}
+	return json.Marshal(e)
+}
+
+func unmarshal(data []byte) (*Task, error) {
+	e := &envelope{}
+	if err := json.Unmarshal(data, e); err != nil {
+		return nil, err
+	}
+
+	t := &Task{arg: e.Arg}
+	t.TaskHeader.SetID(e.ID)
+	t.TaskHeader.SetSubject(e.Subject)
+	t.TaskHeader.SetState(e.State)
+	return t, nil
+}
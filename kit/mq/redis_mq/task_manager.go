@@ -0,0 +1,316 @@
+package redis_mq
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+
+	confredis "github.com/saitofun/qkit/conf/redis"
+	"github.com/saitofun/qkit/kit/mq"
+)
+
+type Option func(*TaskManager)
+
+// WithPopTimeout sets the max blocking duration of Pop, default 1 second
+func WithPopTimeout(d time.Duration) Option {
+	return func(tm *TaskManager) { tm.timeout = d }
+}
+
+// WithStateExpiration sets how long a task state is kept after it was
+// pushed or changed, default 1 hour
+func WithStateExpiration(d time.Duration) Option {
+	return func(tm *TaskManager) { tm.expiration = d }
+}
+
+// WithVisibilityTimeout sets how long a popped task is invisible to other
+// consumers before it is acked, default 1 minute. tasks not acked in time are
+// considered lost by crashed consumers and pushed back to queue
+func WithVisibilityTimeout(d time.Duration) Option {
+	return func(tm *TaskManager) { tm.visibility = d }
+}
+
+func New(op confredis.Operator, options ...Option) *TaskManager {
+	tm := &TaskManager{
+		op:         op,
+		timeout:    time.Second,
+		expiration: time.Hour,
+		visibility: time.Minute,
+	}
+	for _, opt := range options {
+		opt(tm)
+	}
+	return tm
+}
+
+// TaskManager is a redis backed mq.TaskManager. for each channel, task ids
+// are queued in a list, task payloads are stored in a hash and task states
+// are stored in standalone keys with expiration, so it can be shared by
+// multiple processes.
+// Pop moves task id to a processing list atomically and leases it for the
+// visibility timeout, the task is acked and removed when its state is set to
+// SUCCEEDED or FAILED, and handlers running longer should renew the lease by
+// Task.Renew. ids in processing list seen without lease for a visibility
+// timeout are pushed back to queue, so ids just popped and not leased yet are
+// not pushed back. delivery is at-least-once and handlers should be
+// idempotent
+type TaskManager struct {
+	op         confredis.Operator
+	timeout    time.Duration
+	expiration time.Duration
+	visibility time.Duration
+
+	mtx       sync.Mutex
+	recovered map[string]time.Time
+	// unleased time ids first seen without lease in processing list by channel
+	unleased map[string]map[string]time.Time
+}
+
+// ErrLeaseExpired the lease of task expired, and the task may be delivered
+// again
+var ErrLeaseExpired = errors.New("lease of task expired")
+
+var _ mq.TaskManager = (*TaskManager)(nil)
+
+func (tm *TaskManager) Push(ch string, t mq.Task) error {
+	data, err := marshal(t)
+	if err != nil {
+		return err
+	}
+	id := t.ID()
+	_, err = tm.op.Exec(
+		confredis.Command("HSET", tm.tasks(ch), id, data),
+		confredis.Command("SET", tm.state(ch, id), t.State().Int(), "PX", tm.expiration.Milliseconds()),
+		confredis.Command("LPUSH", tm.queue(ch), id),
+	)
+	return err
+}
+
+// Pop blocks until a task is available or pop timeout, returns nil task when
+// timeout or the task popped has been removed. the task popped stays in
+// processing list until acked by setting its state to SUCCEEDED or FAILED
+func (tm *TaskManager) Pop(ch string) (mq.Task, error) {
+	if err := tm.recover(ch); err != nil {
+		return nil, err
+	}
+
+	timeout := tm.timeout.Seconds()
+	if timeout < 1 {
+		timeout = 1
+	}
+	id, err := redis.String(tm.op.Exec(
+		confredis.Command("BRPOPLPUSH", tm.queue(ch), tm.processing(ch), int64(timeout)),
+	))
+	if err != nil {
+		if err == redis.ErrNil {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	replies, err := redis.Values(tm.op.Exec(
+		confredis.Command("SET", tm.lease(ch, id), 1, "PX", tm.visibility.Milliseconds()),
+		confredis.Command("HGET", tm.tasks(ch), id),
+	))
+	if err != nil {
+		return nil, err
+	}
+	data, err := redis.Bytes(replies[1], nil)
+	if err != nil {
+		if err == redis.ErrNil {
+			// removed after pushed
+			return nil, tm.ack(ch, id)
+		}
+		return nil, err
+	}
+
+	t, err := unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	t.tm, t.ch = tm, ch
+	return t, nil
+}
+
+func (tm *TaskManager) Remove(ch string, id string) error {
+	_, err := tm.op.Exec(
+		confredis.Command("LREM", tm.queue(ch), 0, id),
+		confredis.Command("LREM", tm.processing(ch), 0, id),
+		confredis.Command("HDEL", tm.tasks(ch), id),
+		confredis.Command("DEL", tm.lease(ch, id)),
+		confredis.Command("DEL", tm.state(ch, id)),
+	)
+	return err
+}
+
+// Renew extends lease of task popped from ch for another visibility timeout,
+// returns ErrLeaseExpired if the lease expired already
+func (tm *TaskManager) Renew(ch string, id string) error {
+	_, err := redis.String(tm.op.Exec(
+		confredis.Command("SET", tm.lease(ch, id), 1, "PX", tm.visibility.Milliseconds(), "XX"),
+	))
+	if err == redis.ErrNil {
+		return ErrLeaseExpired
+	}
+	return err
+}
+
+func (tm *TaskManager) Clear(ch string) error {
+	replies, err := redis.Values(tm.op.Exec(
+		confredis.Command("LRANGE", tm.queue(ch), 0, -1),
+		confredis.Command("LRANGE", tm.processing(ch), 0, -1),
+		confredis.Command("HKEYS", tm.tasks(ch)),
+	))
+	if err != nil {
+		return err
+	}
+
+	cmds := []*confredis.Cmd{
+		confredis.Command("DEL", tm.queue(ch)),
+		confredis.Command("DEL", tm.processing(ch)),
+		confredis.Command("DEL", tm.tasks(ch)),
+	}
+	cleared := map[string]bool{}
+	for _, reply := range replies {
+		ids, err := redis.Strings(reply, nil)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if cleared[id] {
+				continue
+			}
+			cleared[id] = true
+			cmds = append(cmds,
+				confredis.Command("DEL", tm.state(ch, id)),
+				confredis.Command("DEL", tm.lease(ch, id)),
+			)
+		}
+	}
+	_, err = tm.op.Exec(cmds[0], cmds[1:]...)
+	return err
+}
+
+// State returns the latest state of task, it is synced by the workers
+// through Task.SetState
+func (tm *TaskManager) State(ch string, id string) (mq.TaskState, error) {
+	v, err := redis.Int(tm.op.Exec(confredis.Command("GET", tm.state(ch, id))))
+	if err != nil {
+		if err == redis.ErrNil {
+			return mq.TASK_STATE_UNKNOWN, nil
+		}
+		return mq.TASK_STATE_UNKNOWN, err
+	}
+	return mq.TaskState(v), nil
+}
+
+func (tm *TaskManager) setState(ch string, id string, s mq.TaskState) error {
+	cmds := []*confredis.Cmd{
+		confredis.Command("SET", tm.state(ch, id), s.Int(), "PX", tm.expiration.Milliseconds()),
+	}
+	if s == mq.TASK_STATE__SUCCEEDED || s == mq.TASK_STATE__FAILED {
+		cmds = append(cmds, tm.ackCmds(ch, id)...)
+	}
+	_, err := tm.op.Exec(cmds[0], cmds[1:]...)
+	return err
+}
+
+// ack removes the task finished from processing list
+func (tm *TaskManager) ack(ch string, id string) error {
+	cmds := tm.ackCmds(ch, id)
+	_, err := tm.op.Exec(cmds[0], cmds[1:]...)
+	return err
+}
+
+func (tm *TaskManager) ackCmds(ch string, id string) []*confredis.Cmd {
+	return []*confredis.Cmd{
+		confredis.Command("LREM", tm.processing(ch), 0, id),
+		confredis.Command("HDEL", tm.tasks(ch), id),
+		confredis.Command("DEL", tm.lease(ch, id)),
+	}
+}
+
+// recover pushes ids in processing list seen without lease for a visibility
+// timeout back to queue, it runs at most once per visibility timeout for each
+// channel. ids popped by other processes are not leased until the next round
+// trip, so ids seen without lease the first time are only recorded. only the
+// process removed the id from processing list pushes it back, so concurrent
+// recoveries do not duplicate it
+func (tm *TaskManager) recover(ch string) error {
+	now := time.Now()
+
+	tm.mtx.Lock()
+	if tm.recovered == nil {
+		tm.recovered = map[string]time.Time{}
+		tm.unleased = map[string]map[string]time.Time{}
+	}
+	if last, ok := tm.recovered[ch]; ok && now.Sub(last) < tm.visibility {
+		tm.mtx.Unlock()
+		return nil
+	}
+	tm.recovered[ch] = now
+	seen := tm.unleased[ch]
+	tm.mtx.Unlock()
+
+	ids, err := redis.Strings(tm.op.Exec(
+		confredis.Command("LRANGE", tm.processing(ch), 0, -1),
+	))
+	if err != nil {
+		return err
+	}
+
+	unleased := map[string]time.Time{}
+	defer func() {
+		tm.mtx.Lock()
+		tm.unleased[ch] = unleased
+		tm.mtx.Unlock()
+	}()
+
+	for _, id := range ids {
+		leased, err := redis.Bool(tm.op.Exec(confredis.Command("EXISTS", tm.lease(ch, id))))
+		if err != nil {
+			return err
+		}
+		if leased {
+			continue
+		}
+		since, ok := seen[id]
+		if !ok || now.Sub(since) < tm.visibility {
+			if !ok {
+				since = now
+			}
+			unleased[id] = since
+			continue
+		}
+		removed, err := redis.Int(tm.op.Exec(
+			confredis.Command("LREM", tm.processing(ch), 1, id),
+		))
+		if err != nil {
+			return err
+		}
+		if removed == 0 {
+			continue
+		}
+		if _, err = tm.op.Exec(confredis.Command("RPUSH", tm.queue(ch), id)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (tm *TaskManager) queue(ch string) string { return tm.op.Prefix("mq:" + ch) }
+
+func (tm *TaskManager) processing(ch string) string {
+	return tm.op.Prefix("mq:" + ch + ":processing")
+}
+
+func (tm *TaskManager) tasks(ch string) string { return tm.op.Prefix("mq:" + ch + ":tasks") }
+
+func (tm *TaskManager) state(ch, id string) string {
+	return tm.op.Prefix("mq:" + ch + ":state:" + id)
+}
+
+func (tm *TaskManager) lease(ch, id string) string {
+	return tm.op.Prefix("mq:" + ch + ":lease:" + id)
+}
@@ -0,0 +1,355 @@
+package redis_mq
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	confredis "github.com/saitofun/qkit/conf/redis"
+	"github.com/saitofun/qkit/kit/mq"
+)
+
+// memOperator implements commands used by TaskManager in memory, blocking
+// commands return immediately
+type memOperator struct {
+	mtx     sync.Mutex
+	strings map[string][]byte
+	expires map[string]time.Time
+	hashes  map[string]map[string][]byte
+	lists   map[string][]string
+}
+
+func newMemOperator() *memOperator {
+	return &memOperator{
+		strings: map[string][]byte{},
+		expires: map[string]time.Time{},
+		hashes:  map[string]map[string][]byte{},
+		lists:   map[string][]string{},
+	}
+}
+
+var _ confredis.Operator = (*memOperator)(nil)
+
+func (o *memOperator) Prefix(key string) string { return "test:" + key }
+
+func (o *memOperator) Get() confredis.Conn { return nil }
+
+func (o *memOperator) GetContext(context.Context) (confredis.Conn, error) { return nil, nil }
+
+func (o *memOperator) Exec(cmd *confredis.Cmd, others ...*confredis.Cmd) (interface{}, error) {
+	return o.ExecContext(context.Background(), cmd, others...)
+}
+
+func (o *memOperator) ExecContext(_ context.Context, cmd *confredis.Cmd, others ...*confredis.Cmd) (interface{}, error) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	if len(others) == 0 {
+		return o.do(cmd)
+	}
+	replies := make([]interface{}, 0, len(others)+1)
+	for _, c := range append([]*confredis.Cmd{cmd}, others...) {
+		reply, err := o.do(c)
+		if err != nil {
+			return nil, err
+		}
+		replies = append(replies, reply)
+	}
+	return replies, nil
+}
+
+func (o *memOperator) expire(key string) {
+	if at, ok := o.expires[key]; ok && !time.Now().Before(at) {
+		delete(o.strings, key)
+		delete(o.expires, key)
+	}
+}
+
+func (o *memOperator) do(cmd *confredis.Cmd) (interface{}, error) {
+	args := make([]string, 0, len(cmd.Args))
+	for _, arg := range cmd.Args {
+		switch v := arg.(type) {
+		case []byte:
+			args = append(args, string(v))
+		default:
+			args = append(args, fmt.Sprint(v))
+		}
+	}
+	key := args[0]
+	o.expire(key)
+
+	switch cmd.Name {
+	case "SET":
+		if len(args) == 5 && args[4] == "XX" {
+			if _, ok := o.strings[key]; !ok {
+				return nil, nil
+			}
+		}
+		o.strings[key] = []byte(args[1])
+		delete(o.expires, key)
+		if len(args) >= 4 && args[2] == "PX" {
+			var ms int64
+			fmt.Sscan(args[3], &ms)
+			o.expires[key] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		}
+		return "OK", nil
+	case "GET":
+		if v, ok := o.strings[key]; ok {
+			return v, nil
+		}
+		return nil, nil
+	case "EXISTS":
+		if _, ok := o.strings[key]; ok {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case "DEL":
+		delete(o.strings, key)
+		delete(o.hashes, key)
+		delete(o.lists, key)
+		return int64(1), nil
+	case "HSET":
+		if o.hashes[key] == nil {
+			o.hashes[key] = map[string][]byte{}
+		}
+		o.hashes[key][args[1]] = []byte(args[2])
+		return int64(1), nil
+	case "HGET":
+		if v, ok := o.hashes[key][args[1]]; ok {
+			return v, nil
+		}
+		return nil, nil
+	case "HKEYS":
+		values := make([]interface{}, 0)
+		for k := range o.hashes[key] {
+			values = append(values, []byte(k))
+		}
+		return values, nil
+	case "HDEL":
+		delete(o.hashes[key], args[1])
+		return int64(1), nil
+	case "LPUSH":
+		o.lists[key] = append([]string{args[1]}, o.lists[key]...)
+		return int64(len(o.lists[key])), nil
+	case "RPUSH":
+		o.lists[key] = append(o.lists[key], args[1])
+		return int64(len(o.lists[key])), nil
+	case "LRANGE":
+		values := make([]interface{}, 0)
+		for _, v := range o.lists[key] {
+			values = append(values, []byte(v))
+		}
+		return values, nil
+	case "LREM":
+		var count int
+		fmt.Sscan(args[1], &count)
+		removed, kept := 0, make([]string, 0)
+		for _, v := range o.lists[key] {
+			if v == args[2] && (count == 0 || removed < count) {
+				removed++
+				continue
+			}
+			kept = append(kept, v)
+		}
+		o.lists[key] = kept
+		return int64(removed), nil
+	case "BRPOPLPUSH":
+		src := o.lists[key]
+		if len(src) == 0 {
+			return nil, nil
+		}
+		v := src[len(src)-1]
+		o.lists[key] = src[:len(src)-1]
+		o.lists[args[1]] = append([]string{v}, o.lists[args[1]]...)
+		return []byte(v), nil
+	}
+	return nil, fmt.Errorf("unsupported command %s", cmd.Name)
+}
+
+func (o *memOperator) list(key string) []string {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	return append([]string{}, o.lists[key]...)
+}
+
+func newTask(id string, arg string) *argTask {
+	t := &argTask{arg: arg}
+	t.SetID(id)
+	t.SetSubject("subject")
+	return t
+}
+
+func TestTaskManager(t *testing.T) {
+	ch := "ch"
+
+	t.Run("PushPop", func(t *testing.T) {
+		tm := New(newMemOperator())
+
+		for _, id := range []string{"1", "2", "3"} {
+			NewWithT(t).Expect(tm.Push(ch, newTask(id, "arg"+id))).To(BeNil())
+			s, err := tm.State(ch, id)
+			NewWithT(t).Expect(err).To(BeNil())
+			NewWithT(t).Expect(s).To(Equal(mq.TASK_STATE__PENDING))
+		}
+
+		for _, id := range []string{"1", "2", "3"} {
+			task, err := tm.Pop(ch)
+			NewWithT(t).Expect(err).To(BeNil())
+			NewWithT(t).Expect(task.ID()).To(Equal(id))
+			NewWithT(t).Expect(task.(*Task).Arg()).To(Equal([]byte("arg" + id)))
+		}
+
+		task, err := tm.Pop(ch)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(task).To(BeNil())
+	})
+
+	t.Run("Ack", func(t *testing.T) {
+		op := newMemOperator()
+		tm := New(op)
+
+		NewWithT(t).Expect(tm.Push(ch, newTask("1", "arg"))).To(BeNil())
+
+		task, err := tm.Pop(ch)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(op.list(tm.processing(ch))).To(Equal([]string{"1"}))
+
+		task.SetState(mq.TASK_STATE__SUCCEEDED)
+		NewWithT(t).Expect(op.list(tm.processing(ch))).To(BeEmpty())
+
+		s, err := tm.State(ch, "1")
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(s).To(Equal(mq.TASK_STATE__SUCCEEDED))
+	})
+
+	t.Run("RedeliverUnacked", func(t *testing.T) {
+		op := newMemOperator()
+		tm := New(op, WithVisibilityTimeout(10*time.Millisecond))
+
+		NewWithT(t).Expect(tm.Push(ch, newTask("1", "arg"))).To(BeNil())
+
+		task, err := tm.Pop(ch)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(task.ID()).To(Equal("1"))
+
+		// consumer crashed before acked
+		time.Sleep(20 * time.Millisecond)
+
+		other := New(op, WithVisibilityTimeout(10*time.Millisecond))
+		task, err = other.Pop(ch)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(task).To(BeNil())
+
+		// pushed back after seen without lease for a visibility timeout
+		time.Sleep(20 * time.Millisecond)
+
+		task, err = other.Pop(ch)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(task).NotTo(BeNil())
+		NewWithT(t).Expect(task.ID()).To(Equal("1"))
+	})
+
+	t.Run("KeepJustPopped", func(t *testing.T) {
+		op := newMemOperator()
+		tm := New(op, WithVisibilityTimeout(10*time.Millisecond))
+
+		NewWithT(t).Expect(tm.Push(ch, newTask("1", "arg"))).To(BeNil())
+		// popped by other process, which has not leased it yet
+		_, err := op.Exec(confredis.Command("BRPOPLPUSH", tm.queue(ch), tm.processing(ch), 1))
+		NewWithT(t).Expect(err).To(BeNil())
+
+		task, err := tm.Pop(ch)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(task).To(BeNil())
+		NewWithT(t).Expect(op.list(tm.processing(ch))).To(Equal([]string{"1"}))
+	})
+
+	t.Run("Renew", func(t *testing.T) {
+		op := newMemOperator()
+		tm := New(op, WithVisibilityTimeout(20*time.Millisecond))
+
+		NewWithT(t).Expect(tm.Push(ch, newTask("1", "arg"))).To(BeNil())
+		task, err := tm.Pop(ch)
+		NewWithT(t).Expect(err).To(BeNil())
+
+		for i := 0; i < 3; i++ {
+			time.Sleep(10 * time.Millisecond)
+			NewWithT(t).Expect(task.(*Task).Renew()).To(BeNil())
+		}
+		leased, err := op.Exec(confredis.Command("EXISTS", tm.lease(ch, "1")))
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(leased).To(Equal(int64(1)))
+
+		time.Sleep(30 * time.Millisecond)
+		NewWithT(t).Expect(task.(*Task).Renew()).To(Equal(ErrLeaseExpired))
+	})
+
+	t.Run("KeepLeased", func(t *testing.T) {
+		op := newMemOperator()
+		tm := New(op)
+
+		NewWithT(t).Expect(tm.Push(ch, newTask("1", "arg"))).To(BeNil())
+
+		task, err := tm.Pop(ch)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(task.ID()).To(Equal("1"))
+
+		task, err = New(op).Pop(ch)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(task).To(BeNil())
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		op := newMemOperator()
+		tm := New(op)
+
+		NewWithT(t).Expect(tm.Push(ch, newTask("1", "arg"))).To(BeNil())
+		NewWithT(t).Expect(tm.Push(ch, newTask("2", "arg"))).To(BeNil())
+		NewWithT(t).Expect(tm.Remove(ch, "1")).To(BeNil())
+
+		s, err := tm.State(ch, "1")
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(s).To(Equal(mq.TASK_STATE_UNKNOWN))
+
+		task, err := tm.Pop(ch)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(task.ID()).To(Equal("2"))
+
+		NewWithT(t).Expect(tm.Remove(ch, "2")).To(BeNil())
+		NewWithT(t).Expect(op.list(tm.processing(ch))).To(BeEmpty())
+	})
+
+	t.Run("PopRemoved", func(t *testing.T) {
+		op := newMemOperator()
+		tm := New(op)
+
+		NewWithT(t).Expect(tm.Push(ch, newTask("1", "arg"))).To(BeNil())
+		// payload removed while id is still queued
+		_, err := op.Exec(confredis.Command("HDEL", tm.tasks(ch), "1"))
+		NewWithT(t).Expect(err).To(BeNil())
+
+		task, err := tm.Pop(ch)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(task).To(BeNil())
+		NewWithT(t).Expect(op.list(tm.processing(ch))).To(BeEmpty())
+	})
+
+	t.Run("Clear", func(t *testing.T) {
+		op := newMemOperator()
+		tm := New(op)
+
+		NewWithT(t).Expect(tm.Push(ch, newTask("1", "arg"))).To(BeNil())
+		NewWithT(t).Expect(tm.Push(ch, newTask("2", "arg"))).To(BeNil())
+		_, err := tm.Pop(ch)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(tm.Clear(ch)).To(BeNil())
+
+		NewWithT(t).Expect(op.list(tm.queue(ch))).To(BeEmpty())
+		NewWithT(t).Expect(op.list(tm.processing(ch))).To(BeEmpty())
+		NewWithT(t).Expect(op.strings).To(BeEmpty())
+	})
+}
@@ -0,0 +1,48 @@
+package redis_mq
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/saitofun/qkit/kit/mq"
+)
+
+type argTask struct {
+	mq.TaskHeader
+	arg interface{}
+}
+
+func (t *argTask) Arg() interface{} { return t.arg }
+
+func TestTaskMarshal(t *testing.T) {
+	cases := []struct {
+		name string
+		arg  interface{}
+		want []byte
+	}{
+		{"Nil", nil, nil},
+		{"Bytes", []byte("payload"), []byte("payload")},
+		{"String", "payload", []byte("payload")},
+		{"Struct", struct{ A int }{1}, []byte(`{"A":1}`)},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			src := &argTask{arg: c.arg}
+			src.SetID("id")
+			src.SetSubject("subject")
+			src.SetState(mq.TASK_STATE__FAILED)
+
+			data, err := marshal(src)
+			NewWithT(t).Expect(err).To(BeNil())
+
+			dst, err := unmarshal(data)
+			NewWithT(t).Expect(err).To(BeNil())
+			NewWithT(t).Expect(dst.ID()).To(Equal("id"))
+			NewWithT(t).Expect(dst.Subject()).To(Equal("subject"))
+			NewWithT(t).Expect(dst.State()).To(Equal(mq.TASK_STATE__FAILED))
+			NewWithT(t).Expect(dst.Arg()).To(Equal(c.want))
+		})
+	}
+}
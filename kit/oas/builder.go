@@ -0,0 +1,191 @@
+package oas
+
+import (
+	"go/ast"
+	"reflect"
+	"strings"
+
+	"github.com/saitofun/qkit/kit/enum"
+	"github.com/saitofun/qkit/x/reflectx"
+	"github.com/saitofun/qkit/x/stringsx"
+	"github.com/saitofun/qkit/x/typesx"
+)
+
+// NewBuilder creates schema Builder which registers named struct and enum
+// types into the components of doc
+func NewBuilder(doc *OpenAPI) *Builder {
+	return &Builder{doc: doc, names: map[string]string{}}
+}
+
+type Builder struct {
+	doc   *OpenAPI
+	names map[string]string // full type name => component name
+	// Enum returns values and labels if t is an enum type, by default values
+	// are resolved from enum.IntStringerEnum of reflect types
+	Enum func(t typesx.Type) (values []interface{}, labels []string, ok bool)
+	// Describe returns description of named type or struct field
+	Describe func(t typesx.Type, field string) string
+}
+
+var (
+	rtEnum     = typesx.FromReflectType(reflect.TypeOf((*enum.IntStringerEnum)(nil)).Elem())
+	fileHeader = "mime/multipart.FileHeader"
+)
+
+// Schema returns the schema of t, named types are returned as $ref
+func (b *Builder) Schema(t typesx.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		if typesx.FullTypeName(t.Elem()) == fileHeader {
+			return String("binary")
+		}
+		t = t.Elem()
+	}
+
+	switch full := typesx.FullTypeName(t); full {
+	case "time.Time":
+		return String("date-time")
+	case fileHeader:
+		return String("binary")
+	}
+
+	if values, labels, ok := b.enum(t); ok {
+		return b.named(t, func() *Schema {
+			s := String("")
+			s.Enum, s.GoEnumLabels = values, labels
+			return s
+		})
+	}
+
+	if _, ok := typesx.EncodingTextMarshalerTypeReplacer(t); ok {
+		return String("")
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return Boolean()
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return Integer("int32")
+	case reflect.Int, reflect.Int64, reflect.Uint32:
+		return Integer("int64")
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		return Integer("uint64")
+	case reflect.Float32:
+		return Number("float")
+	case reflect.Float64:
+		return Number("double")
+	case reflect.String:
+		return String("")
+	case reflect.Slice, reflect.Array:
+		elem := t.Elem()
+		if elem.Kind() == reflect.Uint8 && elem.PkgPath() == "" {
+			return String("byte")
+		}
+		return ArrayOf(b.Schema(elem))
+	case reflect.Map:
+		return MapOf(b.Schema(t.Elem()))
+	case reflect.Struct:
+		return b.named(t, func() *Schema { return b.ObjectOf(t, "json") })
+	}
+	return &Schema{}
+}
+
+// ObjectOf returns inline object schema of struct t, property names are
+// resolved from tag key, eg: `json` for json body and `name` for form body
+func (b *Builder) ObjectOf(t typesx.Type, key string) *Schema {
+	s := Object()
+
+	typesx.EachField(t, key, func(f typesx.StructField, display string, omitempty bool) bool {
+		prop := b.Schema(f.Type())
+		ext := &Schema{}
+		if f.Name() != stringsx.UpperCamelCase(display) {
+			ext.GoFieldName = f.Name()
+		}
+		if flags := reflectx.ParseStructTag(string(f.Tag())); flags != nil {
+			if v, ok := flags["validate"]; ok {
+				ext.Validate = string(v)
+			}
+		}
+		if b.Describe != nil {
+			ext.Description = b.Describe(t, f.Name())
+		}
+		s.AddProperty(display, withExtensions(prop, ext), !omitempty && f.Type().Kind() != reflect.Ptr)
+		return true
+	})
+	return s
+}
+
+// withExtensions merges extensions into s, $ref schema is wrapped by allOf
+// because siblings of $ref are ignored
+func withExtensions(s *Schema, ext *Schema) *Schema {
+	if ext.GoFieldName == "" && ext.Validate == "" && ext.Description == "" {
+		return s
+	}
+	if s.IsRef() {
+		s = &Schema{AllOf: []*Schema{s}}
+	}
+	s.GoFieldName, s.Validate = ext.GoFieldName, ext.Validate
+	if ext.Description != "" {
+		s.Description = ext.Description
+	}
+	return s
+}
+
+func (b *Builder) named(t typesx.Type, build func() *Schema) *Schema {
+	if t.Name() == "" || b.doc == nil {
+		return build()
+	}
+	full := typesx.FullTypeName(t)
+	if name, ok := b.names[full]; ok {
+		return RefSchema(name)
+	}
+
+	name := b.componentName(t)
+	b.names[full] = name
+	// register first to break recursive reference
+	b.doc.AddSchema(name, &Schema{})
+
+	s := build()
+	if b.Describe != nil {
+		s.Description = b.Describe(t, "")
+	}
+	b.doc.AddSchema(name, s)
+	return RefSchema(name)
+}
+
+func (b *Builder) componentName(t typesx.Type) string {
+	name := t.Name()
+	if i := strings.Index(name, "["); i > 0 {
+		name = name[0:i]
+	}
+	if b.doc.Schema(name) == nil {
+		return name
+	}
+	parts := strings.Split(t.PkgPath(), "/")
+	prefixed := stringsx.UpperCamelCase(parts[len(parts)-1]) + name
+	for i := 1; b.doc.Schema(prefixed) != nil; i++ {
+		prefixed = stringsx.UpperCamelCase(parts[len(parts)-1]) + name + strings.Repeat("_", i)
+	}
+	return prefixed
+}
+
+func (b *Builder) enum(t typesx.Type) ([]interface{}, []string, bool) {
+	if b.Enum != nil {
+		if values, labels, ok := b.Enum(t); ok {
+			return values, labels, ok
+		}
+	}
+	rt, ok := t.(*typesx.ReflectType)
+	if !ok || !t.Implements(rtEnum) || !ast.IsExported(t.Name()) {
+		return nil, nil, false
+	}
+	e, ok := reflect.New(rt.Type).Elem().Interface().(enum.IntStringerEnum)
+	if !ok {
+		return nil, nil, false
+	}
+	values, labels := make([]interface{}, 0), make([]string, 0)
+	for _, v := range e.ConstValues() {
+		values = append(values, v.String())
+		labels = append(labels, v.Label())
+	}
+	return values, labels, true
+}
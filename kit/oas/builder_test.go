@@ -0,0 +1,46 @@
+package oas_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/saitofun/qkit/kit/oas"
+	"github.com/saitofun/qkit/testutil/httptransporttestutil/server/pkg/types"
+	"github.com/saitofun/qkit/x/typesx"
+)
+
+type Node struct {
+	Name     string         `json:"name"`
+	Protocol types.Protocol `json:"protocol,omitempty"`
+	Children []*Node        `json:"children,omitempty"`
+	Parent   *Node          `json:"parent,omitempty"`
+	Raw      []byte         `json:"raw,omitempty"`
+	Labels   map[string]int `json:"labels,omitempty"`
+	Created  time.Time      `json:"created"`
+	Weight   float32        `json:"weight,omitempty" validate:"@float32[0,1]"`
+}
+
+func TestBuilder(t *testing.T) {
+	doc := oas.NewOpenAPI()
+	b := oas.NewBuilder(doc)
+
+	s := b.Schema(typesx.FromReflectType(reflect.TypeOf(&Node{})))
+	NewWithT(t).Expect(s).To(Equal(oas.RefSchema("Node")))
+
+	node := doc.Schema("Node")
+	NewWithT(t).Expect(node.Required).To(Equal([]string{"name", "created"}))
+	NewWithT(t).Expect(node.Properties["name"]).To(Equal(oas.String("")))
+	NewWithT(t).Expect(node.Properties["protocol"]).To(Equal(oas.RefSchema("Protocol")))
+	NewWithT(t).Expect(node.Properties["children"]).To(Equal(oas.ArrayOf(oas.RefSchema("Node"))))
+	NewWithT(t).Expect(node.Properties["parent"]).To(Equal(oas.RefSchema("Node")))
+	NewWithT(t).Expect(node.Properties["raw"]).To(Equal(oas.String("byte")))
+	NewWithT(t).Expect(node.Properties["labels"]).To(Equal(oas.MapOf(oas.Integer("int64"))))
+	NewWithT(t).Expect(node.Properties["created"]).To(Equal(oas.String("date-time")))
+	NewWithT(t).Expect(node.Properties["weight"].Validate).To(Equal("@float32[0,1]"))
+
+	protocol := doc.Schema("Protocol")
+	NewWithT(t).Expect(protocol.Enum).To(Equal([]interface{}{"HTTP", "HTTPS", "TCP"}))
+}
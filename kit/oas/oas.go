@@ -0,0 +1,176 @@
+package oas
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const Version = "3.0.3"
+
+func NewOpenAPI() *OpenAPI {
+	return &OpenAPI{
+		OpenAPI:    Version,
+		Paths:      map[string]PathItem{},
+		Components: Components{Schemas: map[string]*Schema{}},
+	}
+}
+
+// OpenAPI is the subset of OpenAPI 3.0 document used by qkit generators
+type OpenAPI struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+// PathItem maps lower case http method to operation
+type PathItem map[string]*Operation
+
+func (o *OpenAPI) AddOperation(method, path string, op *Operation) {
+	if o.Paths == nil {
+		o.Paths = map[string]PathItem{}
+	}
+	if o.Paths[path] == nil {
+		o.Paths[path] = PathItem{}
+	}
+	o.Paths[path][strings.ToLower(method)] = op
+}
+
+func (o *OpenAPI) AddSchema(name string, s *Schema) {
+	if o.Components.Schemas == nil {
+		o.Components.Schemas = map[string]*Schema{}
+	}
+	o.Components.Schemas[name] = s
+}
+
+// Schema returns component schema by name or by ref
+func (o *OpenAPI) Schema(nameOrRef string) *Schema {
+	return o.Components.Schemas[strings.TrimPrefix(nameOrRef, RefPrefix)]
+}
+
+// Each visits operations ordered by path and method
+func (o *OpenAPI) Each(each func(method, path string, op *Operation)) {
+	paths := make([]string, 0, len(o.Paths))
+	for p := range o.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	for _, p := range paths {
+		methods := make([]string, 0, len(o.Paths[p]))
+		for m := range o.Paths[p] {
+			methods = append(methods, m)
+		}
+		sort.Strings(methods)
+
+		for _, m := range methods {
+			each(strings.ToUpper(m), p, o.Paths[p][m])
+		}
+	}
+}
+
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Deprecated  bool                 `json:"deprecated,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+func (op *Operation) AddResponse(code int, rsp *Response) {
+	if op.Responses == nil {
+		op.Responses = map[string]*Response{}
+	}
+	op.Responses[StatusCode(code)] = rsp
+}
+
+// SuccessResponse returns the first 2xx response
+func (op *Operation) SuccessResponse() (int, *Response) {
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		if c, err := strconv.Atoi(code); err == nil && c >= 200 && c < 300 {
+			return c, op.Responses[code]
+		}
+	}
+	return 0, nil
+}
+
+func StatusCode(code int) string {
+	if code <= 0 {
+		return "default"
+	}
+	return strconv.Itoa(code)
+}
+
+const (
+	PositionPath   = "path"
+	PositionQuery  = "query"
+	PositionHeader = "header"
+	PositionCookie = "cookie"
+)
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+type RequestBody struct {
+	Description string                `json:"description,omitempty"`
+	Required    bool                  `json:"required,omitempty"`
+	Content     map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// FirstMediaType returns content type and media type ordered by content type
+func FirstMediaType(content map[string]*MediaType) (string, *MediaType) {
+	types := make([]string, 0, len(content))
+	for t := range content {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	for _, t := range types {
+		return t, content[t]
+	}
+	return "", nil
+}
+
+func DefaultStatusCode(method string, hasContent bool) int {
+	if !hasContent {
+		return http.StatusNoContent
+	}
+	if method == http.MethodPost {
+		return http.StatusCreated
+	}
+	return http.StatusOK
+}
@@ -0,0 +1,95 @@
+package oas
+
+import (
+	"context"
+	"strings"
+
+	"github.com/saitofun/qkit/kit/httptransport/httpx"
+	"github.com/saitofun/qkit/kit/httptransport/transformer"
+	"github.com/saitofun/qkit/x/typesx"
+)
+
+// Parameter returns parameter of p declared in struct t, as scanned by
+// transformer.EachParameter
+func (b *Builder) Parameter(t typesx.Type, p *transformer.Param) *Parameter {
+	required := p.In == PositionPath
+	if tag, ok := p.Tags["name"]; ok && !required {
+		required = !tag.HasFlag("omitempty")
+	}
+
+	s := b.Schema(p.Type)
+	ext := &Schema{}
+	if tag, ok := p.Tags["validate"]; ok {
+		ext.Validate = string(tag)
+	}
+	if tag, ok := p.Tags["default"]; ok {
+		ext.Default = string(tag)
+		required = false
+	}
+	if ext.Validate != "" || ext.Default != nil {
+		if s.IsRef() {
+			s = &Schema{AllOf: []*Schema{s}}
+		}
+		s.Validate, s.Default = ext.Validate, ext.Default
+	}
+
+	description := ""
+	if b.Describe != nil {
+		description = b.Describe(t, p.Field.Name())
+	}
+
+	return &Parameter{
+		Name:        p.Name,
+		In:          p.In,
+		Description: description,
+		Required:    required,
+		Schema:      s,
+	}
+}
+
+// RequestBody returns request body of p, content type is resolved from `mime`
+// tag, default application/json
+func (b *Builder) RequestBody(ctx context.Context, p *transformer.Param) *RequestBody {
+	contentType := httpx.MIME_JSON
+	if tag, ok := p.Tags["mime"]; ok {
+		if tsf, err := transformer.NewTransformer(ctx, p.Type, transformer.Option{MIME: tag.Name()}); err == nil {
+			contentType = tsf.Names()[0]
+		}
+	}
+
+	var s *Schema
+	switch contentType {
+	case httpx.MIME_FORM_URLENCODED, httpx.MIME_MULTIPART_FORMDAT:
+		s = b.ObjectOf(typesx.DeRef(p.Type), "name")
+	default:
+		s = b.Schema(p.Type)
+	}
+
+	return &RequestBody{
+		Required: true,
+		Content:  map[string]*MediaType{contentType: {Schema: s}},
+	}
+}
+
+// PathFromHttpRouter converts httprouter path `/:id` or `/*path` to openapi
+// path `/{id}` or `/{path}`
+func PathFromHttpRouter(p string) string {
+	parts := strings.Split(p, "/")
+	for i, part := range parts {
+		if strings.HasPrefix(part, ":") || strings.HasPrefix(part, "*") {
+			parts[i] = "{" + part[1:] + "}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// PathToHttpRouter converts openapi path `/{id}` to httprouter path `/:id`
+func PathToHttpRouter(p string) string {
+	parts := strings.Split(p, "/")
+	for i, part := range parts {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			parts[i] = ":" + part[1:len(part)-1]
+		}
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,15 @@
+package oas_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/saitofun/qkit/kit/oas"
+)
+
+func TestPathConvert(t *testing.T) {
+	NewWithT(t).Expect(oas.PathFromHttpRouter("/demo/restful/:id")).To(Equal("/demo/restful/{id}"))
+	NewWithT(t).Expect(oas.PathFromHttpRouter("/demo/files/*path")).To(Equal("/demo/files/{path}"))
+	NewWithT(t).Expect(oas.PathToHttpRouter("/demo/restful/{id}")).To(Equal("/demo/restful/:id"))
+}
@@ -0,0 +1,81 @@
+package oas
+
+import "strings"
+
+const RefPrefix = "#/components/schemas/"
+
+const (
+	TypeInteger = "integer"
+	TypeNumber  = "number"
+	TypeString  = "string"
+	TypeBoolean = "boolean"
+	TypeArray   = "array"
+	TypeObject  = "object"
+)
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Deprecated           bool               `json:"deprecated,omitempty"`
+
+	Minimum          *float64 `json:"minimum,omitempty"`
+	Maximum          *float64 `json:"maximum,omitempty"`
+	ExclusiveMinimum bool     `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum bool     `json:"exclusiveMaximum,omitempty"`
+	MinLength        *uint64  `json:"minLength,omitempty"`
+	MaxLength        *uint64  `json:"maxLength,omitempty"`
+	MinItems         *uint64  `json:"minItems,omitempty"`
+	MaxItems         *uint64  `json:"maxItems,omitempty"`
+	Pattern          string   `json:"pattern,omitempty"`
+
+	// GoFieldName keeps the go struct field name of property
+	GoFieldName string `json:"x-go-field-name,omitempty"`
+	// GoEnumLabels keeps the labels of enum values
+	GoEnumLabels []string `json:"x-go-enum-labels,omitempty"`
+	// Validate keeps the validate tag of property or parameter
+	Validate string `json:"x-tag-validate,omitempty"`
+}
+
+func RefSchema(name string) *Schema { return &Schema{Ref: RefPrefix + name} }
+
+func (s *Schema) RefName() string { return strings.TrimPrefix(s.Ref, RefPrefix) }
+
+func (s *Schema) IsRef() bool { return s != nil && s.Ref != "" }
+
+func Integer(format string) *Schema { return &Schema{Type: TypeInteger, Format: format} }
+
+func Number(format string) *Schema { return &Schema{Type: TypeNumber, Format: format} }
+
+func String(format string) *Schema { return &Schema{Type: TypeString, Format: format} }
+
+func Boolean() *Schema { return &Schema{Type: TypeBoolean} }
+
+func ArrayOf(items *Schema) *Schema { return &Schema{Type: TypeArray, Items: items} }
+
+func MapOf(elem *Schema) *Schema {
+	return &Schema{Type: TypeObject, AdditionalProperties: elem}
+}
+
+func Object() *Schema {
+	return &Schema{Type: TypeObject, Properties: map[string]*Schema{}}
+}
+
+func (s *Schema) AddProperty(name string, prop *Schema, required bool) {
+	if s.Properties == nil {
+		s.Properties = map[string]*Schema{}
+	}
+	s.Properties[name] = prop
+	if required {
+		s.Required = append(s.Required, name)
+	}
+}
@@ -0,0 +1,334 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/saitofun/qkit/base/types"
+	"github.com/saitofun/qkit/conf/jwt"
+	"github.com/saitofun/qkit/kit/metax"
+	"github.com/saitofun/qkit/kit/sqlx/builder"
+)
+
+const (
+	AuditOperationInsert = "INSERT"
+	AuditOperationUpdate = "UPDATE"
+	AuditOperationDelete = "DELETE"
+)
+
+// AuditLog records change of a row made by statement executed by DB.Exec on
+// table with audit enabled. Before and After are json of field values, and
+// empty when row not exists.
+type AuditLog struct {
+	ID uint64 `db:"f_id,autoincrement" json:"-"`
+	// Table name of changed row
+	Table string `db:"f_table,size=64" json:"table"`
+	// RowKey json array of primary key values of changed row
+	RowKey string `db:"f_row_key" json:"rowKey"`
+	// Operation INSERT, UPDATE or DELETE
+	Operation string `db:"f_operation,size=16" json:"operation"`
+	// Actor who made the change
+	Actor     string          `db:"f_actor,default=''" json:"actor"`
+	Before    string          `db:"f_before,default=''" json:"before,omitempty"`
+	After     string          `db:"f_after,default=''" json:"after,omitempty"`
+	CreatedAt types.Timestamp `db:"f_created_at,default='0'" json:"createdAt"`
+}
+
+func (AuditLog) TableName() string { return "t_audit_log" }
+
+// auditLogTable returns table of AuditLog, Before and After are text as json
+// of rows is not limited in size
+func auditLogTable() *builder.Table {
+	t := builder.TableFromModel(&AuditLog{})
+	for _, fn := range []string{"Before", "After"} {
+		t.ColByFieldName(fn).DataType = "text"
+	}
+	return t
+}
+
+func (AuditLog) PrimaryKey() []string { return []string{"ID"} }
+
+func (AuditLog) Indexes() builder.Indexes {
+	return builder.Indexes{"i_row": {"Table", "RowKey"}}
+}
+
+// AuditActorFromContext resolves actor of changes, defaults to auth of jwt if
+// it implements fmt.Stringer, otherwise `actor` of metax.Meta in context.
+// replace it to resolve actor from auth of other types
+var AuditActorFromContext = func(ctx context.Context) string {
+	if auth, ok := jwt.AuthFromContext(ctx).(fmt.Stringer); ok {
+		return auth.String()
+	}
+	return metax.GetMetaFrom(ctx).Get("actor")
+}
+
+// AuditHistory lists changes of row of model identified by values of primary
+// key, in order of changed
+func AuditHistory(db DBExecutor, m builder.Model, pk ...interface{}) ([]AuditLog, error) {
+	key, err := auditPrimaryKey(pk)
+	if err != nil {
+		return nil, err
+	}
+
+	t := db.T(&AuditLog{})
+	if t == nil {
+		return nil, nil
+	}
+
+	logs := make([]AuditLog, 0)
+	err = db.QueryAndScan(
+		builder.Select(nil).From(
+			t,
+			builder.Where(builder.And(
+				t.ColByFieldName("Table").Eq(db.T(m).Name),
+				t.ColByFieldName("RowKey").Eq(key),
+			)),
+			builder.OrderBy(builder.AscOrder(t.ColByFieldName("ID"))),
+		),
+		&logs,
+	)
+	return logs, err
+}
+
+// auditedTableOf returns table of insert, update or delete statement e if
+// audit enabled
+func auditedTableOf(e builder.SqlExpr) *builder.Table {
+	var t *builder.Table
+	switch stmt := e.(type) {
+	case *builder.StmtInsert:
+		t = stmt.Table()
+	case *builder.StmtUpdate:
+		t = stmt.Table()
+	case *builder.StmtDelete:
+		t = stmt.Table()
+	}
+	if t == nil || !t.Audit || t.Model == nil {
+		return nil
+	}
+	return t
+}
+
+// execAudited executes e and records changes of rows in the same transaction.
+// rows are captured by the condition of e before executed, and by primary key
+// after executed. inserted rows are returned by the insert statement, so
+// values generated by database, eg: auto increment id, are recorded.
+func (d *DB) execAudited(t *builder.Table, e builder.SqlExpr) (res sql.Result, err error) {
+	err = NewTasks(d).With(func(db DBExecutor) error {
+		tx := db.(*DB)
+		actor := AuditActorFromContext(tx.Context())
+
+		var logs []builder.Model
+
+		switch stmt := e.(type) {
+		case *builder.StmtInsert:
+			var after []builder.FieldValues
+			after, err = auditSelect(tx, t, stmt.Returning(&t.Columns))
+			if err != nil {
+				if tx.dialect.IsErrorConflict(err) {
+					return NewSqlError(sqlErrTypeConflict, err.Error())
+				}
+				return err
+			}
+			res = auditInsertResult(t, after)
+			logs, err = auditChanged(t, AuditOperationInsert, nil, after)
+		case *builder.StmtUpdate:
+			var before []builder.FieldValues
+			before, err = auditSelect(tx, t, stmt.Selection(nil, auditLocking(tx)...))
+			if err != nil {
+				return err
+			}
+			if res, err = tx.exec(e); err != nil {
+				return err
+			}
+			var after []builder.FieldValues
+			after, err = auditSelect(tx, t, auditSelectionByPrimaryKey(t, before))
+			if err != nil {
+				return err
+			}
+			logs, err = auditChanged(t, AuditOperationUpdate, before, after)
+		case *builder.StmtDelete:
+			var before []builder.FieldValues
+			before, err = auditSelect(tx, t, stmt.Selection(nil, auditLocking(tx)...))
+			if err != nil {
+				return err
+			}
+			if res, err = tx.exec(e); err != nil {
+				return err
+			}
+			logs, err = auditChanged(t, AuditOperationDelete, before, nil)
+		}
+		if err != nil {
+			return err
+		}
+
+		now := types.AsTimestamp(time.Now())
+		for i := range logs {
+			l := logs[i].(*AuditLog)
+			l.Table, l.Actor, l.CreatedAt = t.Name, actor, now
+		}
+		return BatchInsertToDB(tx, logs)
+	}).Do()
+	return res, err
+}
+
+// auditLocking locks rows captured before changed on postgres, so they are not
+// changed by others until audit logs recorded
+func auditLocking(db *DB) []builder.Addition {
+	if db.Dialect().DriverName() == "postgres" {
+		return []builder.Addition{builder.ForUpdate()}
+	}
+	return nil
+}
+
+func auditSelect(db *DB, t *builder.Table, stmt builder.SqlExpr) ([]builder.FieldValues, error) {
+	if stmt == nil {
+		return nil, nil
+	}
+	rv := reflect.New(reflect.SliceOf(reflect.Indirect(reflect.ValueOf(t.Model)).Type()))
+	if err := db.QueryAndScan(stmt, rv.Interface()); err != nil {
+		return nil, err
+	}
+
+	rows := rv.Elem()
+	names := t.Columns.FieldNames()
+	fvs := make([]builder.FieldValues, 0, rows.Len())
+	for i := 0; i < rows.Len(); i++ {
+		fvs = append(fvs, builder.FieldValueFromStruct(rows.Index(i).Addr().Interface(), names))
+	}
+	return fvs, nil
+}
+
+func auditSelectionByPrimaryKey(t *builder.Table, rows []builder.FieldValues) builder.SqlExpr {
+	names := auditPrimaryKeyFieldNames(t)
+	if len(rows) == 0 || len(names) == 0 {
+		return nil
+	}
+
+	conds := make([]builder.SqlCondition, 0, len(rows))
+	for _, fvs := range rows {
+		cond := make([]builder.SqlCondition, 0, len(names))
+		for _, name := range names {
+			cond = append(cond, t.ColByFieldName(name).Eq(fvs[name]))
+		}
+		conds = append(conds, builder.And(cond...))
+	}
+	return builder.Select(nil).From(t, builder.Where(builder.Or(conds...)), builder.WithDeleted())
+}
+
+// auditResult is result of audited insert statement, which is executed as
+// query to return inserted rows
+type auditResult struct {
+	affected int64
+	id       int64
+}
+
+func auditInsertResult(t *builder.Table, rows []builder.FieldValues) sql.Result {
+	res := &auditResult{affected: int64(len(rows)), id: -1}
+	if col := t.AutoIncrement(); col != nil && len(rows) > 0 {
+		if id, ok := rows[len(rows)-1][col.FieldName]; ok {
+			rv := reflect.Indirect(reflect.ValueOf(id))
+			switch rv.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				res.id = rv.Int()
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				res.id = int64(rv.Uint())
+			}
+		}
+	}
+	return res
+}
+
+// LastInsertId returns auto increment id of the last inserted row
+func (r *auditResult) LastInsertId() (int64, error) {
+	if r.id < 0 {
+		return 0, errors.New("last insert id is not available")
+	}
+	return r.id, nil
+}
+
+func (r *auditResult) RowsAffected() (int64, error) { return r.affected, nil }
+
+func auditChanged(t *builder.Table, op string, before, after []builder.FieldValues) ([]builder.Model, error) {
+	logs := make([]builder.Model, 0, len(before)+len(after))
+	afters := make(map[string]builder.FieldValues, len(after))
+	keys := make([]string, 0, len(after))
+
+	for _, fvs := range after {
+		key, err := auditPrimaryKeyOf(t, fvs)
+		if err != nil {
+			return nil, err
+		}
+		afters[key] = fvs
+		keys = append(keys, key)
+	}
+
+	appendLog := func(key string, before, after builder.FieldValues) error {
+		l := &AuditLog{RowKey: key, Operation: op}
+		if before != nil {
+			data, err := json.Marshal(before)
+			if err != nil {
+				return err
+			}
+			l.Before = string(data)
+		}
+		if after != nil {
+			data, err := json.Marshal(after)
+			if err != nil {
+				return err
+			}
+			l.After = string(data)
+		}
+		logs = append(logs, l)
+		return nil
+	}
+
+	if before == nil {
+		for _, key := range keys {
+			if err := appendLog(key, nil, afters[key]); err != nil {
+				return nil, err
+			}
+		}
+		return logs, nil
+	}
+
+	for _, fvs := range before {
+		key, err := auditPrimaryKeyOf(t, fvs)
+		if err != nil {
+			return nil, err
+		}
+		if err := appendLog(key, fvs, afters[key]); err != nil {
+			return nil, err
+		}
+	}
+	return logs, nil
+}
+
+func auditPrimaryKeyFieldNames(t *builder.Table) []string {
+	if key := t.Keys.Key("primary"); key != nil {
+		return key.Def.FieldNames
+	}
+	return nil
+}
+
+func auditPrimaryKeyOf(t *builder.Table, fvs builder.FieldValues) (string, error) {
+	names := auditPrimaryKeyFieldNames(t)
+	values := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		values = append(values, fvs[name])
+	}
+	return auditPrimaryKey(values)
+}
+
+func auditPrimaryKey(values []interface{}) (string, error) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,82 @@
+package sqlx_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/saitofun/qkit/kit/metax"
+	"github.com/saitofun/qkit/kit/sqlx"
+	"github.com/saitofun/qkit/kit/sqlx/builder"
+	"github.com/saitofun/qkit/kit/sqlx/migration"
+)
+
+type AuditedItem struct {
+	Item
+}
+
+func (*AuditedItem) TableName() string { return "t_audited_item" }
+
+func (*AuditedItem) AuditEnabled() bool { return true }
+
+func TestAudit(t *testing.T) {
+	for name, connector := range connectors {
+		t.Run(name, func(t *testing.T) {
+			d := sqlx.NewDatabase("test_audit")
+			tbl := d.Register(&AuditedItem{})
+			NewWithT(t).Expect(d.Table("t_audit_log")).NotTo(BeNil())
+
+			db := d.OpenDB(connector).
+				WithContext(metax.ContextWith(context.Background(), "actor", "tester"))
+			defer func() {
+				d.Tables.Range(func(t *builder.Table, _ int) {
+					_, _ = db.Exec(db.Dialect().DropTable(t))
+				})
+			}()
+			NewWithT(t).Expect(migration.Migrate(db, nil)).To(BeNil())
+
+			res, err := db.Exec(builder.Insert().Into(tbl).
+				Values(tbl.MustColsByFieldNames("Name", "Price"), "a", 1))
+			NewWithT(t).Expect(err).To(BeNil())
+			NewWithT(t).Expect(res.LastInsertId()).To(Equal(int64(1)))
+
+			_, err = db.Exec(builder.Update(tbl).
+				Where(tbl.ColByFieldName("Name").Eq("a")).
+				Set(tbl.ColByFieldName("Price").ValueBy(2)))
+			NewWithT(t).Expect(err).To(BeNil())
+
+			_, err = db.Exec(builder.Delete().From(tbl, builder.Where(tbl.ColByFieldName("ID").Eq(1))))
+			NewWithT(t).Expect(err).To(BeNil())
+
+			logs, err := sqlx.AuditHistory(db, &AuditedItem{}, 1)
+			NewWithT(t).Expect(err).To(BeNil())
+			NewWithT(t).Expect(logs).To(HaveLen(3))
+
+			for i, expect := range []struct{ op, before, after string }{
+				{sqlx.AuditOperationInsert, "", `{"ID":1,"Name":"a","Price":1,"Rank":1,"Stock":0}`},
+				{sqlx.AuditOperationUpdate, `{"ID":1,"Name":"a","Price":1,"Rank":1,"Stock":0}`, `{"ID":1,"Name":"a","Price":2,"Rank":1,"Stock":0}`},
+				{sqlx.AuditOperationDelete, `{"ID":1,"Name":"a","Price":2,"Rank":1,"Stock":0}`, ""},
+			} {
+				NewWithT(t).Expect(logs[i].Operation).To(Equal(expect.op))
+				NewWithT(t).Expect(logs[i].Actor).To(Equal("tester"))
+				NewWithT(t).Expect(logs[i].Before).To(Equal(expect.before))
+				NewWithT(t).Expect(logs[i].After).To(Equal(expect.after))
+			}
+
+			t.Run("InsertRows", func(t *testing.T) {
+				res, err := db.Exec(builder.Insert().Into(tbl).
+					Values(tbl.MustColsByFieldNames("Name", "Price"), "b", 1, "c", 2))
+				NewWithT(t).Expect(err).To(BeNil())
+				NewWithT(t).Expect(res.RowsAffected()).To(Equal(int64(2)))
+
+				for _, id := range []int{2, 3} {
+					logs, err := sqlx.AuditHistory(db, &AuditedItem{}, id)
+					NewWithT(t).Expect(err).To(BeNil())
+					NewWithT(t).Expect(logs).To(HaveLen(1))
+					NewWithT(t).Expect(logs[0].Operation).To(Equal(sqlx.AuditOperationInsert))
+				}
+			})
+		})
+	}
+}
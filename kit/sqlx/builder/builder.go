@@ -37,6 +37,37 @@ type WithColRel interface {
 	ColRel() map[string][]string
 }
 
+// WithSoftDelete rows are soft deleted by field, and alive when field is zero.
+// it is implemented by model opted in, eg: generated by modelgen for model has
+// DeletedAt, rather than promoted from embedded struct
+type WithSoftDelete interface {
+	SoftDeleteFieldName() string
+}
+
+// WithAudit changes of rows are recorded when audit enabled
+type WithAudit interface {
+	AuditEnabled() bool
+}
+
+// WithChecks CHECK constraints of table by name, fields are referred by
+// #FieldName, eg: {"period": "#StartedAt < #EndedAt"}
+type WithChecks interface {
+	Checks() map[string]string
+}
+
+// WithExclusions EXCLUDE constraints of table by name, eg:
+// {"room_during": "USING gist (#RoomID WITH =, #During WITH &&)"}
+type WithExclusions interface {
+	Exclusions() map[string]string
+}
+
+// WithPartition table is partitioned when created, partitions are created
+// and attached to the table by dialect. primary key and unique indexes should
+// include columns of partition key.
+type WithPartition interface {
+	Partition() *Partition
+}
+
 type Indexes map[string][]string
 
 var (
@@ -49,6 +80,9 @@ type Dialect interface {
 	PrimaryKeyName() string
 	IsErrorUnknownDatabase(error) bool
 	IsErrorConflict(error) bool
+	// IsErrorRetryable if transaction failed by serialization failure or
+	// deadlock, which should be retried as a whole
+	IsErrorRetryable(error) bool
 	CreateDatabase(string) SqlExpr
 	CreateSchema(string) SqlExpr
 	DropDatabase(string) SqlExpr
@@ -62,6 +96,9 @@ type Dialect interface {
 	AddIndex(*Key) SqlExpr
 	DropIndex(*Key) SqlExpr
 	DataType(*ColumnType) SqlExpr
+	Savepoint(name string) SqlExpr
+	RollbackToSavepoint(name string) SqlExpr
+	ReleaseSavepoint(name string) SqlExpr
 }
 
 type DataTypeDescriber interface {
@@ -13,6 +13,7 @@ const (
 	AdditionJoin AdditionType = iota
 	AdditionWhere
 	AdditionGroupBy
+	AdditionWindow
 	AdditionCombination
 	AdditionOrderBy
 	AdditionLimit
@@ -27,6 +28,7 @@ var (
 	_ Addition = (*join)(nil)
 	_ Addition = (*where)(nil)
 	_ Addition = (*groupby)(nil)
+	_ Addition = (*window)(nil)
 	_ Addition = (*orderby)(nil)
 	_ Addition = (*addition)(nil)
 	_ Addition = (*limit)(nil)
@@ -9,6 +9,7 @@ type join struct {
 	AdditionType
 
 	prefix   string
+	lateral  bool
 	target   SqlExpr
 	joinCond SqlCondition
 	joinCols []*Column
@@ -18,9 +19,13 @@ func (j join) On(c SqlCondition) *join { j.joinCond = c; return &j }
 
 func (j join) Using(cols ...*Column) *join { j.joinCols = cols; return &j }
 
+// Lateral joins derived table which could refer columns of preceding tables,
+// joined ON TRUE if no condition
+func (j join) Lateral() *join { j.lateral = true; return &j }
+
 func (j *join) IsNil() bool {
 	return j == nil || IsNilExpr(j.target) ||
-		(j.prefix != "CROSS" && IsNilExpr(j.joinCond) && len(j.joinCols) == 0)
+		(j.prefix != "CROSS" && !j.lateral && IsNilExpr(j.joinCond) && len(j.joinCols) == 0)
 }
 
 func (j *join) Ex(ctx context.Context) *Ex {
@@ -28,7 +33,13 @@ func (j *join) Ex(ctx context.Context) *Ex {
 	if j.prefix != "" {
 		e = Expr(j.prefix + " JOIN ")
 	}
+	if j.lateral {
+		e.WriteQuery("LATERAL ")
+	}
 	e.WriteExpr(j.target)
+	if j.lateral && j.prefix != "CROSS" && IsNilExpr(j.joinCond) && len(j.joinCols) == 0 {
+		e.WriteQuery(" ON TRUE")
+	}
 	if !IsNilExpr(j.joinCond) {
 		e.WriteExpr(ExprBy(func(ctx context.Context) *Ex {
 			e := Expr(" ON ")
@@ -0,0 +1,81 @@
+package builder
+
+import "context"
+
+type window struct {
+	AdditionType
+	names []string
+	defs  []SqlExpr
+}
+
+// Window declares named windows of select referred by Function.OverWindow,
+// eg: WINDOW w AS (PARTITION BY f_org_id ORDER BY (f_id) ASC)
+func Window(name string, defs ...SqlExpr) *window {
+	return (&window{AdditionType: AdditionWindow}).Window(name, defs...)
+}
+
+func (w window) Window(name string, defs ...SqlExpr) *window {
+	w.names = append(append([]string{}, w.names...), name)
+	w.defs = append(append([]SqlExpr{}, w.defs...), windowDef(defs))
+	return &w
+}
+
+func (w *window) IsNil() bool { return w == nil || len(w.names) == 0 }
+
+func (w *window) Ex(ctx context.Context) *Ex {
+	e := Expr("WINDOW ")
+	for i := range w.names {
+		if i > 0 {
+			e.WriteQuery(", ")
+		}
+		e.WriteQuery(w.names[i])
+		e.WriteQuery(" AS ")
+		e.WriteExpr(w.defs[i])
+	}
+	return e.Ex(ctx)
+}
+
+// windowDef writes definitions of window in group, eg:
+// (PARTITION BY f_org_id ORDER BY (f_id) ASC ROWS UNBOUNDED PRECEDING)
+func windowDef(defs []SqlExpr) SqlExpr {
+	return ExprBy(func(ctx context.Context) *Ex {
+		e := Expr("")
+		e.WriteGroup(func(e *Ex) {
+			n := 0
+			for i := range defs {
+				if IsNilExpr(defs[i]) {
+					continue
+				}
+				if n > 0 {
+					e.WriteQueryByte(' ')
+				}
+				e.WriteExpr(defs[i])
+				n++
+			}
+		})
+		return e.Ex(ctx)
+	})
+}
+
+type partitionby struct {
+	exprs []SqlExpr
+}
+
+// PartitionBy partitions rows of window
+func PartitionBy(es ...SqlExpr) *partitionby { return &partitionby{exprs: es} }
+
+func (p *partitionby) IsNil() bool { return p == nil || len(p.exprs) == 0 }
+
+func (p *partitionby) Ex(ctx context.Context) *Ex {
+	e := Expr("PARTITION BY ")
+	for i := range p.exprs {
+		if i > 0 {
+			e.WriteQueryByte(',')
+		}
+		e.WriteExpr(p.exprs[i])
+	}
+	return e.Ex(ctx)
+}
+
+// Frame of window, eg: ROWS BETWEEN 1 PRECEDING AND CURRENT ROW
+func Frame(frame string, args ...interface{}) SqlExpr { return Expr(frame, args...) }
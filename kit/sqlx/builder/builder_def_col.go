@@ -27,9 +27,9 @@ func (c *Column) Ex(ctx context.Context) *Ex {
 	toggles := TogglesFromContext(ctx)
 	if c.Table != nil && (c.exactly || toggles.Is(ToggleMultiTable)) {
 		if toggles.Is(ToggleNeedAutoAlias) {
-			return Expr("?.? AS ?", c.Table, Expr(c.Name), Expr(c.Name)).Ex(ctx)
+			return Expr("?.? AS ?", c.Table.ref(), Expr(c.Name), Expr(c.Name)).Ex(ctx)
 		}
-		return Expr("?.?", c.Table, Expr(c.Name)).Ex(ctx)
+		return Expr("?.?", c.Table.ref(), Expr(c.Name)).Ex(ctx)
 	}
 	return ExactlyExpr(c.Name).Ex(ctx)
 }
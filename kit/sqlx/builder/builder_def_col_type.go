@@ -4,21 +4,36 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/saitofun/qkit/x/ptrx"
 	"github.com/saitofun/qkit/x/typesx"
 )
 
 type ColumnType struct {
-	Type           typesx.Type
-	DataType       string
-	Length         uint64
-	Decimal        uint64
-	Default        *string
-	OnUpdate       *string
-	Null           bool
-	AutoIncrement  bool
-	Comment        string
-	Desc           []string
-	Rel            []string
+	Type          typesx.Type
+	DataType      string
+	Length        uint64
+	Decimal       uint64
+	Default       *string
+	OnUpdate      *string
+	Null          bool
+	AutoIncrement bool
+	Version       bool
+	Comment       string
+	Desc          []string
+	Rel           []string
+	// ForeignKey if foreign key declared by Rel is enabled, it is enabled by
+	// RelOnDelete or RelOnUpdate too
+	ForeignKey bool
+	// RelOnDelete and RelOnUpdate actions of foreign key declared by Rel
+	RelOnDelete string
+	RelOnUpdate string
+	// Check boolean expression of column CHECK constraint, eg: f_age >= 0.
+	// commas of tag values are only allowed in parentheses or quotes, eg:
+	// check=f_status IN (1, 2)
+	Check string
+	// Generated expression of column GENERATED ALWAYS AS (...) STORED, eg:
+	// f_price * f_quantity
+	Generated      string
 	DeprecatedActs *DeprecatedActs
 }
 
@@ -29,13 +44,16 @@ func AnalyzeColumnType(t typesx.Type, tag string) *ColumnType {
 		return ct
 	}
 
-	for _, flag := range strings.Split(tag, ",") {
-		kv := strings.Split(flag, "=")
+	for _, flag := range splitTagFlags(tag) {
+		// values could contain `=`, eg: check=f_age >= 0
+		kv := strings.SplitN(flag, "=", 2)
 		switch strings.ToLower(kv[0]) {
 		case "null":
 			ct.Null = true
 		case "autoincrement":
 			ct.AutoIncrement = true
+		case "version":
+			ct.Version = true
 		case "size":
 			if len(kv) == 1 {
 				panic("missing size value")
@@ -64,6 +82,28 @@ func AnalyzeColumnType(t typesx.Type, tag string) *ColumnType {
 				panic("missing onupdate value")
 			}
 			ct.OnUpdate = &kv[1]
+		case "fk":
+			ct.ForeignKey = true
+		case "rel_delete":
+			if len(kv) == 1 {
+				panic("missing rel_delete value")
+			}
+			ct.RelOnDelete = kv[1]
+		case "rel_update":
+			if len(kv) == 1 {
+				panic("missing rel_update value")
+			}
+			ct.RelOnUpdate = kv[1]
+		case "check":
+			if len(kv) == 1 {
+				panic("missing check value")
+			}
+			ct.Check = kv[1]
+		case "generated":
+			if len(kv) == 1 {
+				panic("missing generated value")
+			}
+			ct.Generated = kv[1]
 		case "deprecated":
 			rename := ""
 			if len(kv) > 1 {
@@ -73,9 +113,49 @@ func AnalyzeColumnType(t typesx.Type, tag string) *ColumnType {
 		}
 	}
 
+	// version starts from 0 when inserted without version
+	if ct.Version && ct.Default == nil {
+		ct.Default = ptrx.String("'0'")
+	}
+
 	return ct
 }
 
+// splitTagFlags splits tag by commas not in parentheses or quotes, so values
+// like check=f_status IN (1, 2) are kept. it panics if parentheses or quotes
+// are unbalanced, which means the value is truncated
+func splitTagFlags(tag string) []string {
+	flags := make([]string, 0)
+	depth, quoted, start := 0, false, 0
+
+	for i := 0; i < len(tag); i++ {
+		switch c := tag[i]; {
+		case c == '\'':
+			quoted = !quoted
+		case quoted:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			flags = append(flags, tag[start:i])
+			start = i + 1
+		}
+		if depth < 0 {
+			break
+		}
+	}
+	if depth != 0 || quoted {
+		panic("unbalanced parentheses or quotes in tag: " + tag)
+	}
+	return append(flags, tag[start:])
+}
+
+// ForeignKeyEnabled if foreign key declared by Rel is enabled
+func (ct *ColumnType) ForeignKeyEnabled() bool {
+	return len(ct.Rel) == 2 && (ct.ForeignKey || ct.RelOnDelete != "" || ct.RelOnUpdate != "")
+}
+
 type DeprecatedActs struct {
 	RenameTo string `name:"rename"`
 	// TODO drop column or other action
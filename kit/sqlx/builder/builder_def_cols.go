@@ -10,6 +10,7 @@ import (
 type Columns struct {
 	lst     []*Column
 	autoInc *Column
+	version *Column
 }
 
 func Cols(names ...string) *Columns {
@@ -37,6 +38,9 @@ func (c *Columns) Ex(ctx context.Context) *Ex {
 
 func (c *Columns) AutoIncrement() *Column { return c.autoInc }
 
+// Version column for optimistic locking, tagged by `version`
+func (c *Columns) Version() *Column { return c.version }
+
 func (c *Columns) Len() int {
 	if c == nil || c.lst == nil {
 		return 0
@@ -149,6 +153,12 @@ func (c *Columns) Add(cols ...*Column) {
 			}
 			c.autoInc = col
 		}
+		if col.ColumnType != nil && col.ColumnType.Version {
+			if c.version != nil {
+				panic("version field can only have one")
+			}
+			c.version = col
+		}
 		c.lst = append(c.lst, col)
 	}
 }
@@ -0,0 +1,167 @@
+package builder
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// ConstraintDialect dialect supports CHECK or EXCLUDE constraints altered
+// after table created
+type ConstraintDialect interface {
+	AddConstraint(*Constraint) SqlExpr
+	DropConstraint(*Constraint) SqlExpr
+}
+
+type ConstraintKind string
+
+const (
+	ConstraintCheck   ConstraintKind = "CHECK"
+	ConstraintExclude ConstraintKind = "EXCLUDE"
+)
+
+// Constraint CHECK or EXCLUDE constraint of table, named like index without
+// table prefix. Def refers fields by #FieldName as IndexDef.Expr, eg:
+// CHECK:   #StartedAt < #EndedAt
+// EXCLUDE: USING gist (#RoomID WITH =, #During WITH &&)
+type Constraint struct {
+	Table *Table
+	Name  string
+	Kind  ConstraintKind
+	Def   string
+}
+
+// Check returns CHECK constraint of boolean expression
+func Check(name string, expr string) *Constraint {
+	return &Constraint{Name: strings.ToLower(name), Kind: ConstraintCheck, Def: expr}
+}
+
+// Exclude returns EXCLUDE constraint of definition after keyword EXCLUDE
+func Exclude(name string, def string) *Constraint {
+	return &Constraint{Name: strings.ToLower(name), Kind: ConstraintExclude, Def: def}
+}
+
+// ColumnCheckName returns name of CHECK constraint declared by tag of col, as
+// postgres named
+func ColumnCheckName(col *Column) string { return col.Name + "_check" }
+
+func (c Constraint) On(t *Table) *Constraint { c.Table = t; return &c }
+
+func (c *Constraint) T() *Table { return c.Table }
+
+func (c *Constraint) IsNil() bool { return c == nil || c.Def == "" }
+
+// Ex writes definition of constraint, eg: CHECK (f_age >= 0)
+func (c *Constraint) Ex(ctx context.Context) *Ex {
+	e := Expr(string(c.Kind))
+	e.WriteQueryByte(' ')
+	if c.Kind == ConstraintCheck {
+		e.WriteGroup(func(e *Ex) {
+			e.WriteExpr(c.Table.Expr(c.Def))
+		})
+	} else {
+		e.WriteExpr(c.Table.Expr(c.Def))
+	}
+	return e.Ex(ctx)
+}
+
+// Equal if c and other are the same constraint. definitions are compared
+// without spaces, parentheses and type casts, which are added when database
+// stores them, and IN and BETWEEN are compared in forms postgres rewrites
+// them to, eg: f_a IN (1, 2) as f_a = ANY (ARRAY[1, 2])
+func (c *Constraint) Equal(other *Constraint) bool {
+	return c.Name == other.Name && c.Kind == other.Kind &&
+		normalizeDef(ResolveExpr(c).Query()) == normalizeDef(ResolveExpr(other).Query())
+}
+
+var (
+	regexpTypeCast = regexp.MustCompile(
+		`::(double precision|character varying|bit varying|(timestamp|time) with(out)? time zone|[a-z_0-9]+)(\[])?`,
+	)
+	regexpNotIn      = regexp.MustCompile(`([a-z0-9_.]+)\s+not\s+in\s*\(([^()]*)\)`)
+	regexpIn         = regexp.MustCompile(`([a-z0-9_.]+)\s+in\s*\(([^()]*)\)`)
+	regexpNotBetween = regexp.MustCompile(`([a-z0-9_.]+)\s+not\s+between\s+(\S+)\s+and\s+(\S+)`)
+	regexpBetween    = regexp.MustCompile(`([a-z0-9_.]+)\s+between\s+(\S+)\s+and\s+(\S+)`)
+)
+
+func normalizeDef(def string) string {
+	def = strings.ToLower(def)
+	def = regexpNotIn.ReplaceAllString(def, "$1 <> all (array[$2])")
+	def = regexpIn.ReplaceAllString(def, "$1 = any (array[$2])")
+	def = regexpNotBetween.ReplaceAllString(def, "$1 < $2 or $1 > $3")
+	def = regexpBetween.ReplaceAllString(def, "$1 >= $2 and $1 <= $3")
+	def = regexpTypeCast.ReplaceAllString(def, "")
+	return strings.NewReplacer(" ", "", "\t", "", "\n", "", "(", "", ")", "").Replace(def)
+}
+
+type Constraints struct {
+	lst []*Constraint
+}
+
+func (cs *Constraints) Len() int {
+	if cs == nil {
+		return 0
+	}
+	return len(cs.lst)
+}
+
+func (cs *Constraints) Range(f func(c *Constraint, idx int)) {
+	for i := range cs.lst {
+		f(cs.lst[i], i)
+	}
+}
+
+func (cs *Constraints) Add(constraints ...*Constraint) {
+	for i := range constraints {
+		if c := constraints[i]; c != nil {
+			cs.lst = append(cs.lst, c)
+		}
+	}
+}
+
+func (cs *Constraints) Constraint(name string) *Constraint {
+	name = strings.ToLower(name)
+	for i := range cs.lst {
+		if name == cs.lst[i].Name {
+			return cs.lst[i]
+		}
+	}
+	return nil
+}
+
+// constraintSteps returns steps migrating constraints of prevT to t, steps are
+// empty if dialect not supports altering constraints
+func (t *Table) constraintSteps(prevT *Table, d Dialect) (steps []*MigrationStep) {
+	cd, ok := d.(ConstraintDialect)
+	if !ok {
+		return
+	}
+
+	t.Constraints.Range(func(c *Constraint, _ int) {
+		if prev := prevT.Constraints.Constraint(c.Name); prev != nil {
+			if prev.Equal(c) {
+				return
+			}
+			steps = appendStep(steps, constraintStep(StepDropConstraint, prev, cd.DropConstraint(prev)))
+		}
+		steps = appendStep(steps, constraintStep(StepAddConstraint, c, cd.AddConstraint(c)))
+	})
+
+	prevT.Constraints.Range(func(c *Constraint, _ int) {
+		if t.Constraints.Constraint(c.Name) == nil {
+			steps = appendStep(steps, constraintStep(StepDropConstraint, c, cd.DropConstraint(c)))
+		}
+	})
+	return
+}
+
+func constraintStep(kind MigrationStepKind, c *Constraint, expr SqlExpr) *MigrationStep {
+	return &MigrationStep{
+		Kind:   kind,
+		Table:  c.Table.Name,
+		Target: c.Name,
+		Expr:   expr,
+		// validating constraint scans the whole table
+		LockHeavy: kind == StepAddConstraint,
+	}
+}
@@ -0,0 +1,212 @@
+package builder
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ForeignKeyDialect dialect supports foreign key constraints
+type ForeignKeyDialect interface {
+	AddForeignKey(*ForeignKey) SqlExpr
+	DropForeignKey(*ForeignKey) SqlExpr
+}
+
+// ForeignKey constraint of column referencing column of other table, which is
+// declared by Column.Rel and enabled by tag `fk` or actions, eg:
+// `db:"f_org_id,fk"` or `db:"f_org_id,rel_delete=cascade,rel_update=set_null"`.
+// Rel without them only declares relation of models.
+type ForeignKey struct {
+	Name     string
+	Col      *Column
+	Ref      *Column
+	OnDelete string
+	OnUpdate string
+}
+
+// ForeignKeyName returns name of foreign key of col, as postgres named
+func ForeignKeyName(col *Column) string {
+	return col.Table.Name + "_" + col.Name + "_fkey"
+}
+
+// RelAction returns action of foreign key from tag value, eg: set_null to
+// SET NULL. NO ACTION is the default action, and returned as empty.
+func RelAction(v string) string {
+	action := strings.ToUpper(strings.TrimSpace(strings.ReplaceAll(v, "_", " ")))
+	if action == "NO ACTION" {
+		return ""
+	}
+	return action
+}
+
+func (fk *ForeignKey) IsNil() bool { return fk == nil || fk.Col == nil || fk.Ref == nil }
+
+// Ex writes definition of foreign key, eg:
+// FOREIGN KEY (f_org_id) REFERENCES t_org (f_id) ON DELETE CASCADE
+func (fk *ForeignKey) Ex(ctx context.Context) *Ex {
+	e := Expr("FOREIGN KEY (")
+	e.WriteQuery(fk.Col.Name)
+	e.WriteQuery(") REFERENCES ")
+	e.WriteExpr(fk.Ref.Table)
+	e.WriteQuery(" (")
+	e.WriteQuery(fk.Ref.Name)
+	e.WriteQueryByte(')')
+	if fk.OnDelete != "" {
+		e.WriteQuery(" ON DELETE ")
+		e.WriteQuery(fk.OnDelete)
+	}
+	if fk.OnUpdate != "" {
+		e.WriteQuery(" ON UPDATE ")
+		e.WriteQuery(fk.OnUpdate)
+	}
+	return e.Ex(ctx)
+}
+
+// Equal if fk and other are the same constraint, schema is ignored
+func (fk *ForeignKey) Equal(other *ForeignKey) bool {
+	return fk.Name == other.Name &&
+		fk.Col.Name == other.Col.Name &&
+		fk.Ref.Table.Name == other.Ref.Table.Name &&
+		fk.Ref.Name == other.Ref.Name &&
+		fk.OnDelete == other.OnDelete &&
+		fk.OnUpdate == other.OnUpdate
+}
+
+// ForeignKeysOf returns foreign keys of table t declared by Column.Rel and
+// enabled, the referenced table is resolved by model name or table name
+func (t *Tables) ForeignKeysOf(tbl *Table) []*ForeignKey {
+	fks := make([]*ForeignKey, 0)
+	tbl.Columns.Range(func(col *Column, _ int) {
+		if col.DeprecatedActs != nil || !col.ForeignKeyEnabled() {
+			return
+		}
+		if ref := t.relCol(col); ref != nil {
+			fks = append(fks, &ForeignKey{
+				Name:     ForeignKeyName(col),
+				Col:      col,
+				Ref:      ref,
+				OnDelete: RelAction(col.RelOnDelete),
+				OnUpdate: RelAction(col.RelOnUpdate),
+			})
+		}
+	})
+	return fks
+}
+
+func (t *Tables) relCol(col *Column) *Column {
+	if len(col.Rel) != 2 {
+		return nil
+	}
+	ref := t.Model(col.Rel[0])
+	if ref == nil {
+		ref = t.Table(col.Rel[0])
+	}
+	if ref == nil {
+		return nil
+	}
+	if c := ref.ColByFieldName(col.Rel[1]); c != nil {
+		return c
+	}
+	return ref.Col(col.Rel[1])
+}
+
+// SortedTableNames returns table names ordered by relations, referenced
+// tables are ahead of tables referencing them. relations closing a cycle are
+// ignored
+func (t *Tables) SortedTableNames() []string {
+	sorted := make([]string, 0)
+	visited := map[string]bool{}
+
+	var visit func(tbl *Table)
+	visit = func(tbl *Table) {
+		if visited[tbl.Name] {
+			return
+		}
+		visited[tbl.Name] = true
+		tbl.Columns.Range(func(col *Column, _ int) {
+			if ref := t.relCol(col); ref != nil {
+				visit(ref.Table)
+			}
+		})
+		sorted = append(sorted, tbl.Name)
+	}
+
+	t.Range(func(tbl *Table, _ int) { visit(tbl) })
+	return sorted
+}
+
+// ForeignKeySteps returns steps migrating foreign keys of tbl from prev to
+// curr, steps are empty if dialect not supports foreign key. foreign keys of
+// columns without Rel are not managed and kept, and dropping foreign key no
+// longer enabled is destructive. it returns error if data types of column and
+// referenced column mismatch.
+func ForeignKeySteps(tbl *Table, curr, prev []*ForeignKey, d Dialect) (adds, drops []*MigrationStep, err error) {
+	fkd, ok := d.(ForeignKeyDialect)
+	if !ok {
+		return
+	}
+
+	prevs := make(map[string]*ForeignKey, len(prev))
+	for _, fk := range prev {
+		prevs[fk.Name] = fk
+	}
+	currs := make(map[string]bool, len(curr))
+
+	for _, fk := range curr {
+		col, ref := foreignKeyDataType(d, fk.Col), foreignKeyDataType(d, fk.Ref)
+		if col != "" && ref != "" && col != ref {
+			return nil, nil, errors.Errorf(
+				"foreign key %s: %s.%s %s mismatches %s.%s %s", fk.Name,
+				fk.Col.Table.Name, fk.Col.Name, col, fk.Ref.Table.Name, fk.Ref.Name, ref,
+			)
+		}
+		currs[fk.Name] = true
+		if p, ok := prevs[fk.Name]; ok {
+			if p.Equal(fk) {
+				continue
+			}
+			drops = appendStep(drops, foreignKeyStep(StepDropForeignKey, p, fkd.DropForeignKey(p)))
+		}
+		adds = appendStep(adds, foreignKeyStep(StepAddForeignKey, fk, fkd.AddForeignKey(fk)))
+	}
+	for _, fk := range prev {
+		if currs[fk.Name] {
+			continue
+		}
+		if col := tbl.Col(fk.Col.Name); col == nil || len(col.Rel) == 0 {
+			continue
+		}
+		step := foreignKeyStep(StepDropForeignKey, fk, fkd.DropForeignKey(fk))
+		step.Destructive = true
+		drops = appendStep(drops, step)
+	}
+	return
+}
+
+// foreignKeyDataType returns data type of col without size and modifiers,
+// eg: bigint of bigserial NOT NULL, varchar of varchar(255). returns empty if
+// type of col is unknown
+func foreignKeyDataType(d Dialect, col *Column) string {
+	if col.ColumnType == nil || (col.ColumnType.Type == nil && col.DataType == "") {
+		return ""
+	}
+	ct := *col.ColumnType
+	ct.AutoIncrement, ct.Null, ct.Default, ct.Generated = false, true, nil, ""
+	dataType := strings.ToLower(ResolveExpr(d.DataType(&ct)).Query())
+	if i := strings.IndexAny(dataType, "( "); i > 0 {
+		dataType = dataType[:i]
+	}
+	return dataType
+}
+
+func foreignKeyStep(kind MigrationStepKind, fk *ForeignKey, expr SqlExpr) *MigrationStep {
+	return &MigrationStep{
+		Kind:   kind,
+		Table:  fk.Col.Table.Name,
+		Target: fk.Name,
+		Expr:   expr,
+		// validating constraint scans the whole table
+		LockHeavy: kind == StepAddForeignKey,
+	}
+}
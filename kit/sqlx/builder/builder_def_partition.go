@@ -0,0 +1,37 @@
+package builder
+
+type PartitionMethod string
+
+const (
+	PartitionByRange PartitionMethod = "RANGE"
+	PartitionByList  PartitionMethod = "LIST"
+	PartitionByHash  PartitionMethod = "HASH"
+)
+
+// Partition declares table partitioned by method on key. Key refers fields by
+// #FieldName as IndexDef.Expr, eg: #CreatedAt
+type Partition struct {
+	Method PartitionMethod
+	Key    string
+}
+
+func (p *Partition) IsNil() bool { return p == nil || p.Key == "" }
+
+// TableExpr writes partition of t, eg: PARTITION BY RANGE (f_created_at)
+func (p *Partition) TableExpr(t *Table) *Ex {
+	e := Expr("PARTITION BY ")
+	e.WriteQuery(string(p.Method))
+	e.WriteQueryByte(' ')
+	e.WriteGroup(func(e *Ex) {
+		e.WriteExpr(t.Expr(p.Key))
+	})
+	return e
+}
+
+// KeyCol returns column of partition key if key is a single field
+func (p *Partition) KeyCol(t *Table) *Column {
+	if len(p.Key) < 2 || p.Key[0] != '#' {
+		return nil
+	}
+	return t.ColByFieldName(p.Key[1:])
+}
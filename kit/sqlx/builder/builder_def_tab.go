@@ -6,7 +6,6 @@ import (
 	"context"
 	"fmt"
 	"sort"
-	"strings"
 	"text/scanner"
 )
 
@@ -22,6 +21,9 @@ func T(tblName string, defs ...TableDefinition) *Table {
 		if k, ok := def.(*Key); ok {
 			t.AddKey(k)
 		}
+		if c, ok := def.(*Constraint); ok {
+			t.AddConstraint(c)
+		}
 	}
 	return t
 }
@@ -32,9 +34,22 @@ type Table struct {
 	Schema    string
 	ModelName string
 	Model     Model
+	// SoftDelete field name of soft delete flag, rows soft deleted are
+	// excluded from select and update statements by default
+	SoftDelete string
+	// Audit if changes of rows should be recorded
+	Audit bool
+	// Partition of partitioned table, declared when table created and not
+	// altered by diff
+	Partition *Partition
+
+	// derived select statement of table, see Derived
+	derived SelectStatement
 
 	Columns
 	Keys
+	// Constraints CHECK and EXCLUDE constraints of table
+	Constraints Constraints
 }
 
 func (t *Table) TableName() string { return t.Name }
@@ -42,12 +57,27 @@ func (t *Table) TableName() string { return t.Name }
 func (t *Table) IsNil() bool { return t == nil || t.Name == "" }
 
 func (t *Table) Ex(ctx context.Context) *Ex {
+	if t.derived != nil {
+		e := Expr("")
+		e.WriteExpr(subquery(t.derived))
+		e.WriteQuery(" AS ")
+		e.WriteQuery(t.Name)
+		return e.Ex(ctx)
+	}
 	if t.Schema != "" {
 		return Expr(t.Schema + "." + t.Name).Ex(ctx)
 	}
 	return Expr(t.Name).Ex(ctx)
 }
 
+// ref returns expr refers table, which is name of derived table
+func (t *Table) ref() SqlExpr {
+	if t.derived != nil {
+		return Expr(t.Name)
+	}
+	return t
+}
+
 func (t Table) WithSchema(schema string) *Table {
 	t.Schema = schema
 
@@ -62,6 +92,12 @@ func (t Table) WithSchema(schema string) *Table {
 		keys.Add(k.On(&t))
 	})
 	t.Keys = keys
+
+	constraints := Constraints{}
+	t.Constraints.Range(func(c *Constraint, idx int) {
+		constraints.Add(c.On(&t))
+	})
+	t.Constraints = constraints
 	return &t
 }
 
@@ -77,6 +113,12 @@ func (t *Table) AddKey(k *Key) {
 	}
 }
 
+func (t *Table) AddConstraint(c *Constraint) {
+	if c != nil {
+		t.Constraints.Add(c.On(t))
+	}
+}
+
 func (t *Table) Expr(query string, args ...interface{}) *Ex {
 	if query == "" {
 		return nil
@@ -119,7 +161,7 @@ func (t *Table) Expr(query string, args ...interface{}) *Ex {
 				break
 			}
 			if b.Len() == 0 {
-				e.AppendArgs(t)
+				e.AppendArgs(t.ref())
 				continue
 			}
 			name := b.String()
@@ -134,78 +176,9 @@ func (t *Table) Expr(query string, args ...interface{}) *Ex {
 }
 
 func (t *Table) Diff(prevT *Table, d Dialect) (exprList []SqlExpr) {
-	// diff columns
-	t.Columns.Range(func(currC *Column, idx int) {
-		if prevC := prevT.Col(currC.Name); prevC != nil {
-			if currC != nil {
-				if currC.DeprecatedActs != nil {
-					renameTo := currC.DeprecatedActs.RenameTo
-					if renameTo != "" {
-						prevCol := prevT.Col(renameTo)
-						if prevCol != nil {
-							exprList = append(exprList, d.DropColumn(prevCol))
-						}
-						targetCol := t.Col(renameTo)
-						if targetCol == nil {
-							panic(fmt.Errorf("col `%s` is not declared", renameTo))
-						}
-						exprList = append(exprList, d.RenameColumn(currC, targetCol))
-						prevT.AddCol(targetCol)
-						return
-					}
-					exprList = append(exprList, d.DropColumn(currC))
-					return
-				}
-
-				prevCT := d.DataType(prevC.ColumnType).Ex(context.Background()).Query()
-				currCT := d.DataType(currC.ColumnType).Ex(context.Background()).Query()
-
-				if currCT != prevCT {
-					exprList = append(exprList, d.ModifyColumn(currC, prevC))
-				}
-				return
-			}
-			exprList = append(exprList, d.DropColumn(currC))
-			return
-		}
-
-		if currC.DeprecatedActs == nil {
-			exprList = append(exprList, d.AddColumn(currC))
-		}
-	})
-
-	// indexes
-	indexes := map[string]bool{}
-
-	t.Keys.Range(func(key *Key, idx int) {
-		name := key.Name
-		if key.IsPrimary() {
-			name = d.PrimaryKeyName()
-		}
-		indexes[name] = true
-
-		prevKey := prevT.Key(name)
-		if prevKey == nil {
-			exprList = append(exprList, d.AddIndex(key))
-		} else {
-			if !key.IsPrimary() {
-				indexDef := key.Def.TableExpr(key.Table).Ex(context.Background()).Query()
-				prevIndexDef := prevKey.Def.TableExpr(prevKey.Table).Ex(context.Background()).Query()
-
-				if !strings.EqualFold(indexDef, prevIndexDef) {
-					exprList = append(exprList, d.DropIndex(key))
-					exprList = append(exprList, d.AddIndex(key))
-				}
-			}
-		}
-	})
-
-	prevT.Keys.Range(func(key *Key, idx int) {
-		if _, ok := indexes[strings.ToLower(key.Name)]; !ok {
-			exprList = append(exprList, d.DropIndex(key))
-		}
-	})
-
+	for _, step := range t.DiffSteps(prevT, d) {
+		exprList = append(exprList, step.Expr)
+	}
 	return
 }
 
@@ -221,7 +194,7 @@ func (t *Table) ColumnsAndValuesByFieldValues(fvs FieldValues) (*Columns, []inte
 	args := make([]interface{}, 0, len(fvs))
 
 	for _, fieldName := range fields {
-		if col := t.ColByFieldName(fieldName); col != nil {
+		if col := t.ColByFieldName(fieldName); col != nil && col.Generated == "" {
 			cols.Add(col)
 			args = append(args, fvs[fieldName])
 		}
@@ -232,8 +205,9 @@ func (t *Table) ColumnsAndValuesByFieldValues(fvs FieldValues) (*Columns, []inte
 func (t *Table) AssignmentsByFieldValues(fvs FieldValues) Assignments {
 	var assignments Assignments
 	for name, value := range fvs {
+		// generated columns are computed by database
 		col := t.ColByFieldName(name)
-		if col != nil {
+		if col != nil && col.Generated == "" {
 			assignments = append(assignments, col.ValueBy(value))
 		}
 	}
@@ -0,0 +1,243 @@
+package builder
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+type MigrationStepKind string
+
+const (
+	StepCreateTable  MigrationStepKind = "create table"
+	StepAddColumn    MigrationStepKind = "add column"
+	StepModifyColumn MigrationStepKind = "modify column"
+	StepRenameColumn MigrationStepKind = "rename column"
+	StepDropColumn   MigrationStepKind = "drop column"
+	StepAddIndex     MigrationStepKind = "add index"
+	StepDropIndex    MigrationStepKind = "drop index"
+
+	StepAddForeignKey  MigrationStepKind = "add foreign key"
+	StepDropForeignKey MigrationStepKind = "drop foreign key"
+
+	StepAddConstraint  MigrationStepKind = "add constraint"
+	StepDropConstraint MigrationStepKind = "drop constraint"
+)
+
+// MigrationStep is a single schema change of table diff
+type MigrationStep struct {
+	Kind MigrationStepKind
+	// Table name of changed table
+	Table string
+	// Target name of changed column or index, empty when create table
+	Target string
+	Expr   SqlExpr
+	// Destructive if step may lose data or break running code, eg: drop or
+	// rename column, change column type
+	Destructive bool
+	// LockHeavy if step holds a lock blocking reads or writes while scanning
+	// or rewriting the whole table, eg: build index, change column type
+	LockHeavy bool
+}
+
+// Subject returns `table.target` or `table` which the step changes
+func (s *MigrationStep) Subject() string {
+	if s.Target == "" {
+		return s.Table
+	}
+	return s.Table + "." + s.Target
+}
+
+func (s *MigrationStep) String() string {
+	b := strings.Builder{}
+	b.WriteString(string(s.Kind))
+	b.WriteByte(' ')
+	b.WriteString(s.Subject())
+	if s.Destructive {
+		b.WriteString(" [destructive]")
+	}
+	if s.LockHeavy {
+		b.WriteString(" [lock-heavy]")
+	}
+	return b.String()
+}
+
+// CreateTableSteps returns steps of creating table t and its indexes
+func CreateTableSteps(t *Table, d Dialect) (steps []*MigrationStep) {
+	for i, expr := range d.CreateTableIsNotExists(t) {
+		if i == 0 {
+			steps = appendStep(steps, &MigrationStep{Kind: StepCreateTable, Table: t.Name, Expr: expr})
+			continue
+		}
+		// indexes of new table are built on empty table
+		steps = appendStep(steps, &MigrationStep{Kind: StepAddIndex, Table: t.Name, Expr: expr})
+	}
+	return
+}
+
+// DiffSteps returns steps migrating prevT to t. partition of table is not
+// diffed, which could not be altered without recreating table and moving
+// rows, columns and indexes of partitioned table are propagated to its
+// partitions by database
+func (t *Table) DiffSteps(prevT *Table, d Dialect) (steps []*MigrationStep) {
+	// diff columns
+	t.Columns.Range(func(currC *Column, idx int) {
+		if prevC := prevT.Col(currC.Name); prevC != nil {
+			if currC.DeprecatedActs != nil {
+				renameTo := currC.DeprecatedActs.RenameTo
+				if renameTo != "" {
+					prevCol := prevT.Col(renameTo)
+					if prevCol != nil {
+						steps = appendStep(steps, dropColumnStep(prevCol, d))
+					}
+					targetCol := t.Col(renameTo)
+					if targetCol == nil {
+						panic(fmt.Errorf("col `%s` is not declared", renameTo))
+					}
+					steps = appendStep(steps, &MigrationStep{
+						Kind:        StepRenameColumn,
+						Table:       t.Name,
+						Target:      currC.Name,
+						Expr:        d.RenameColumn(currC, targetCol),
+						Destructive: true,
+					})
+					prevT.AddCol(targetCol)
+					return
+				}
+				steps = appendStep(steps, dropColumnStep(currC, d))
+				return
+			}
+
+			if !sameGenerated(currC.ColumnType, prevC.ColumnType) {
+				// generated column could not be altered, re-add it to compute
+				// by new expression
+				steps = appendStep(steps, dropColumnStep(prevC, d))
+				steps = appendStep(steps, &MigrationStep{
+					Kind:      StepAddColumn,
+					Table:     t.Name,
+					Target:    currC.Name,
+					Expr:      d.AddColumn(currC),
+					LockHeavy: currC.Generated != "",
+				})
+				return
+			}
+
+			prevCT := columnDataType(prevC.ColumnType, d)
+			currCT := columnDataType(currC.ColumnType, d)
+
+			if currCT != prevCT {
+				typeChanged := baseDataType(currC.ColumnType, d) != baseDataType(prevC.ColumnType, d)
+				steps = appendStep(steps, &MigrationStep{
+					Kind:        StepModifyColumn,
+					Table:       t.Name,
+					Target:      currC.Name,
+					Expr:        d.ModifyColumn(currC, prevC),
+					Destructive: typeChanged,
+					// changing type rewrites table and setting not null scans it
+					LockHeavy: typeChanged || (prevC.Null && !currC.Null),
+				})
+			}
+			return
+		}
+
+		if currC.DeprecatedActs == nil {
+			steps = appendStep(steps, &MigrationStep{
+				Kind:   StepAddColumn,
+				Table:  t.Name,
+				Target: currC.Name,
+				Expr:   d.AddColumn(currC),
+			})
+		}
+	})
+
+	// indexes
+	indexes := map[string]bool{}
+
+	t.Keys.Range(func(key *Key, idx int) {
+		name := key.Name
+		if key.IsPrimary() {
+			name = d.PrimaryKeyName()
+		}
+		indexes[name] = true
+
+		prevKey := prevT.Key(name)
+		if prevKey == nil {
+			steps = appendStep(steps, addIndexStep(key, d))
+		} else {
+			if !key.IsPrimary() {
+				indexDef := key.Def.TableExpr(key.Table).Ex(context.Background()).Query()
+				prevIndexDef := prevKey.Def.TableExpr(prevKey.Table).Ex(context.Background()).Query()
+
+				if !strings.EqualFold(indexDef, prevIndexDef) {
+					steps = appendStep(steps, dropIndexStep(key, d))
+					steps = appendStep(steps, addIndexStep(key, d))
+				}
+			}
+		}
+	})
+
+	prevT.Keys.Range(func(key *Key, idx int) {
+		if _, ok := indexes[strings.ToLower(key.Name)]; !ok {
+			steps = appendStep(steps, dropIndexStep(key, d))
+		}
+	})
+
+	steps = append(steps, t.constraintSteps(prevT, d)...)
+
+	return
+}
+
+func appendStep(steps []*MigrationStep, step *MigrationStep) []*MigrationStep {
+	if IsNilExpr(step.Expr) {
+		return steps
+	}
+	return append(steps, step)
+}
+
+func dropColumnStep(col *Column, d Dialect) *MigrationStep {
+	return &MigrationStep{
+		Kind:        StepDropColumn,
+		Table:       col.Table.Name,
+		Target:      col.Name,
+		Expr:        d.DropColumn(col),
+		Destructive: true,
+	}
+}
+
+func addIndexStep(key *Key, d Dialect) *MigrationStep {
+	return &MigrationStep{
+		Kind:      StepAddIndex,
+		Table:     key.Table.Name,
+		Target:    key.Name,
+		Expr:      d.AddIndex(key),
+		LockHeavy: true,
+	}
+}
+
+func dropIndexStep(key *Key, d Dialect) *MigrationStep {
+	return &MigrationStep{
+		Kind:   StepDropIndex,
+		Table:  key.Table.Name,
+		Target: key.Name,
+		Expr:   d.DropIndex(key),
+	}
+}
+
+// columnDataType returns data type without generated expression, which is
+// compared by sameGenerated
+func columnDataType(ct *ColumnType, d Dialect) string {
+	c := *ct
+	c.Generated = ""
+	return d.DataType(&c).Ex(context.Background()).Query()
+}
+
+// baseDataType returns data type without null and default constraints
+func baseDataType(ct *ColumnType, d Dialect) string {
+	base := *ct
+	base.Null, base.Default, base.OnUpdate, base.Generated = false, nil, nil, ""
+	return d.DataType(&base).Ex(context.Background()).Query()
+}
+
+func sameGenerated(curr, prev *ColumnType) bool {
+	return normalizeDef(curr.Generated) == normalizeDef(prev.Generated)
+}
@@ -5,6 +5,10 @@ import "context"
 type Function struct {
 	name  string
 	exprs []SqlExpr
+	// noArgs if function called without args, eg: ROW_NUMBER()
+	noArgs bool
+	filter SqlCondition
+	over   SqlExpr
 }
 
 func Func(name string, es ...SqlExpr) *Function {
@@ -16,10 +20,33 @@ func Func(name string, es ...SqlExpr) *Function {
 
 func (f *Function) IsNil() bool { return f == nil || f.name == "" }
 
+// Filter returns aggregate function only aggregating rows matched by cond, eg:
+// COUNT(1) FILTER (WHERE f_status = ?)
+func (f Function) Filter(cond SqlCondition) *Function {
+	f.filter = cond
+	return &f
+}
+
+// Over returns window function over window defined by PartitionBy, OrderBy
+// and Frame, eg: ROW_NUMBER() OVER (PARTITION BY f_org_id ORDER BY (f_id) ASC)
+func (f Function) Over(defs ...SqlExpr) *Function {
+	f.over = windowDef(defs)
+	return &f
+}
+
+// OverWindow returns window function over window named by Window of select
+func (f Function) OverWindow(name string) *Function {
+	f.over = Expr(name)
+	return &f
+}
+
 func (f *Function) Ex(ctx context.Context) *Ex {
+	// columns in function are not aliased
+	ctx = ContextWithToggleNeedAutoAlias(ctx, false)
+
 	e := Expr(f.name)
 	e.WriteGroup(func(e *Ex) {
-		if len(f.exprs) == 0 {
+		if len(f.exprs) == 0 && !f.noArgs {
 			e.WriteQueryByte('*')
 		}
 		for i := range f.exprs {
@@ -29,6 +56,17 @@ func (f *Function) Ex(ctx context.Context) *Ex {
 			e.WriteExpr(f.exprs[i])
 		}
 	})
+	if !IsNilExpr(f.filter) {
+		e.WriteQuery(" FILTER ")
+		e.WriteGroup(func(e *Ex) {
+			e.WriteQuery("WHERE ")
+			e.WriteExpr(f.filter)
+		})
+	}
+	if !IsNilExpr(f.over) {
+		e.WriteQuery(" OVER ")
+		e.WriteExpr(f.over)
+	}
 	return e.Ex(ctx)
 }
 
@@ -52,3 +90,17 @@ func First(es ...SqlExpr) *Function { return Func("FIRST", es...) }
 func Last(es ...SqlExpr) *Function { return Func("LAST", es...) }
 
 func Sum(es ...SqlExpr) *Function { return Func("SUM", es...) }
+
+func RowNumber() *Function { return &Function{name: "ROW_NUMBER", noArgs: true} }
+
+func Rank() *Function { return &Function{name: "RANK", noArgs: true} }
+
+func DenseRank() *Function { return &Function{name: "DENSE_RANK", noArgs: true} }
+
+// Lag value of row offset rows before current row, es are value, offset and
+// default value
+func Lag(es ...SqlExpr) *Function { return Func("LAG", es...) }
+
+// Lead value of row offset rows after current row, es are value, offset and
+// default value
+func Lead(es ...SqlExpr) *Function { return Func("LEAD", es...) }
@@ -0,0 +1,44 @@
+package builder
+
+// Seek returns keyset condition of rows after values ordered by cols ascending,
+// eg: `(a > ?) OR ((a = ?) AND (b > ?))`; rows before values if desc. values
+// should be of the last (or first) row of previous page and match cols.
+func Seek(desc bool, cols []*Column, values ...interface{}) SqlCondition {
+	if len(cols) == 0 || len(cols) != len(values) {
+		return EmptyCondition
+	}
+
+	seek := func(i int) SqlCondition {
+		if desc {
+			return cols[i].Lt(values[i])
+		}
+		return cols[i].Gt(values[i])
+	}
+
+	if len(cols) == 1 {
+		return seek(0)
+	}
+
+	conds := []SqlCondition{seek(0)}
+	for i := 1; i < len(cols); i++ {
+		cond := make([]SqlCondition, 0, i+1)
+		for j := 0; j < i; j++ {
+			cond = append(cond, cols[j].Eq(values[j]))
+		}
+		conds = append(conds, And(append(cond, seek(i))...))
+	}
+	return Or(conds...)
+}
+
+// SeekOrderBy orders by cols for Seek
+func SeekOrderBy(desc bool, cols ...*Column) *orderby {
+	orders := make([]*Order, 0, len(cols))
+	for _, col := range cols {
+		if desc {
+			orders = append(orders, DescOrder(col))
+		} else {
+			orders = append(orders, AscOrder(col))
+		}
+	}
+	return OrderBy(orders...)
+}
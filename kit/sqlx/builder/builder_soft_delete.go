@@ -0,0 +1,80 @@
+package builder
+
+import "context"
+
+type softDeleteScope int
+
+const (
+	softDeleteScopeAlive softDeleteScope = iota
+	softDeleteScopeWithDeleted
+	softDeleteScopeOnlyDeleted
+)
+
+type softDelete struct {
+	AdditionType
+	scope softDeleteScope
+}
+
+func (s *softDelete) IsNil() bool { return true }
+
+func (s *softDelete) Ex(ctx context.Context) *Ex { return nil }
+
+// WithDeleted includes soft deleted rows of table with soft delete policy
+func WithDeleted() Addition {
+	return &softDelete{AdditionOther, softDeleteScopeWithDeleted}
+}
+
+// OnlyDeleted selects or updates soft deleted rows only, eg: restore
+func OnlyDeleted() Addition {
+	return &softDelete{AdditionOther, softDeleteScopeOnlyDeleted}
+}
+
+// SoftDeleteCondition returns condition of soft delete scope in adds, nil if
+// table has no soft delete policy or scope is WithDeleted
+func SoftDeleteCondition(t *Table, adds ...Addition) SqlCondition {
+	if t == nil || t.SoftDelete == "" {
+		return nil
+	}
+	col := t.ColByFieldName(t.SoftDelete)
+	if col == nil {
+		return nil
+	}
+
+	scope := softDeleteScopeAlive
+	for _, add := range adds {
+		if s, ok := add.(*softDelete); ok {
+			scope = s.scope
+		}
+	}
+
+	switch scope {
+	case softDeleteScopeAlive:
+		return col.Eq(0)
+	case softDeleteScopeOnlyDeleted:
+		return col.Neq(0)
+	default:
+		return nil
+	}
+}
+
+// withSoftDeleteScope attaches soft delete condition to where of adds
+func withSoftDeleteScope(t *Table, adds []Addition) []Addition {
+	cond := SoftDeleteCondition(t, adds...)
+	if cond == nil {
+		return adds
+	}
+
+	final := make([]Addition, 0, len(adds)+1)
+	for _, add := range adds {
+		if w, ok := add.(*where); ok && cond != nil {
+			final = append(final, Where(And(cond, w.SqlCondition)))
+			cond = nil
+			continue
+		}
+		final = append(final, add)
+	}
+	if cond != nil {
+		final = append(final, Where(cond))
+	}
+	return final
+}
@@ -0,0 +1,139 @@
+package builder
+
+import "context"
+
+const (
+	combineUnion     = "UNION"
+	combineUnionAll  = "UNION ALL"
+	combineIntersect = "INTERSECT"
+	combineExcept    = "EXCEPT"
+)
+
+// Union combines results of stmts without duplicated rows
+func Union(stmts ...SelectStatement) *StmtCombination {
+	return (&StmtCombination{}).combine(combineUnion, stmts...)
+}
+
+// UnionAll combines results of stmts with duplicated rows
+func UnionAll(stmts ...SelectStatement) *StmtCombination {
+	return (&StmtCombination{}).combine(combineUnionAll, stmts...)
+}
+
+// Intersect returns rows in all results of stmts
+func Intersect(stmts ...SelectStatement) *StmtCombination {
+	return (&StmtCombination{}).combine(combineIntersect, stmts...)
+}
+
+// Except returns rows in result of the first stmt but not in the others
+func Except(stmts ...SelectStatement) *StmtCombination {
+	return (&StmtCombination{}).combine(combineExcept, stmts...)
+}
+
+// StmtCombination combines results of select statements by set operations,
+// statements are combined from left to right, and combination as operand is
+// written in parentheses. INTERSECT binds tighter than UNION and EXCEPT, so
+// result combined by them is written in parentheses before INTERSECT to keep
+// the order. ORDER BY and LIMIT of combination are applied to
+// the combined result, statements ordered or limited should be wrapped by
+// Derived.
+type StmtCombination struct {
+	SelectStatement
+
+	stmts  []SelectStatement
+	ops    []string
+	orders []*Order
+	limit  *limit
+}
+
+func (c *StmtCombination) Union(stmts ...SelectStatement) *StmtCombination {
+	return c.combine(combineUnion, stmts...)
+}
+
+func (c *StmtCombination) UnionAll(stmts ...SelectStatement) *StmtCombination {
+	return c.combine(combineUnionAll, stmts...)
+}
+
+func (c *StmtCombination) Intersect(stmts ...SelectStatement) *StmtCombination {
+	return c.combine(combineIntersect, stmts...)
+}
+
+func (c *StmtCombination) Except(stmts ...SelectStatement) *StmtCombination {
+	return c.combine(combineExcept, stmts...)
+}
+
+// OrderBy orders combined result, targets are columns of result
+func (c StmtCombination) OrderBy(orders ...*Order) *StmtCombination {
+	c.orders = orders
+	return &c
+}
+
+// Limit limits count of combined result
+func (c StmtCombination) Limit(count int64) *StmtCombination {
+	c.limit = Limit(count)
+	return &c
+}
+
+// Offset skips rows of combined result, only applied with Limit
+func (c StmtCombination) Offset(offset int64) *StmtCombination {
+	if c.limit == nil {
+		c.limit = Limit(0)
+	}
+	c.limit = c.limit.Offset(offset)
+	return &c
+}
+
+func (c StmtCombination) combine(op string, stmts ...SelectStatement) *StmtCombination {
+	c.stmts = append([]SelectStatement{}, c.stmts...)
+	c.ops = append([]string{}, c.ops...)
+
+	for _, s := range stmts {
+		if IsNilExpr(s) {
+			continue
+		}
+		if len(c.stmts) > 0 {
+			c.ops = append(c.ops, op)
+		}
+		c.stmts = append(c.stmts, s)
+	}
+	return &c
+}
+
+func (c *StmtCombination) IsNil() bool { return c == nil || len(c.stmts) == 0 }
+
+func (c *StmtCombination) Ex(ctx context.Context) *Ex {
+	e := Expr("")
+	e.Grow(len(c.stmts) + 2)
+
+	// if e combined by operator binds looser than INTERSECT
+	loose := false
+
+	for i, s := range c.stmts {
+		if i > 0 {
+			op := c.ops[i-1]
+			if op == combineIntersect && loose {
+				left := e
+				e = Expr("")
+				e.WriteGroup(func(e *Ex) {
+					e.WriteExpr(left)
+				})
+				loose = false
+			}
+			if op != combineIntersect {
+				loose = true
+			}
+			e.WriteQueryByte('\n')
+			e.WriteQuery(op)
+			e.WriteQueryByte('\n')
+		}
+		if _, ok := s.(*StmtCombination); ok {
+			e.WriteGroup(func(e *Ex) {
+				e.WriteExpr(s)
+			})
+			continue
+		}
+		e.WriteExpr(s)
+	}
+
+	WriteAdditions(e, OrderBy(c.orders...), c.limit)
+	return e.Ex(ctx)
+}
@@ -5,6 +5,7 @@ import "context"
 func Delete() *StmtDelete { return &StmtDelete{} }
 
 type StmtDelete struct {
+	with *StmtWith
 	tbl  *Table
 	adds Additions
 }
@@ -14,10 +15,27 @@ func (s StmtDelete) From(tbl *Table, adds ...Addition) *StmtDelete {
 	return &s
 }
 
+// Table returns table deleted from by s
+func (s *StmtDelete) Table() *Table { return s.tbl }
+
+// Selection selects e from rows would be deleted by s, soft deleted rows are
+// included as delete statement is not scoped. common tables of s are kept for
+// condition referring them
+func (s *StmtDelete) Selection(e SqlExpr, adds ...Addition) *StmtSelect {
+	final := make([]Addition, 0, len(s.adds)+len(adds)+1)
+	final = append(final, s.adds...)
+	final = append(final, adds...)
+	sel := Select(e).From(s.tbl, append(final, WithDeleted())...)
+	sel.with = s.with
+	return sel
+}
+
 func (s *StmtDelete) IsNil() bool { return s == nil || IsNilExpr(s.tbl) }
 
 func (s *StmtDelete) Ex(ctx context.Context) *Ex {
-	e := Expr("DELETE FROM ")
+	e := Expr("")
+	writeWith(e, s.with)
+	e.WriteQuery("DELETE FROM ")
 	e.WriteExpr(s.tbl)
 	WriteAdditions(e, s.adds...)
 	return e.Ex(ctx)
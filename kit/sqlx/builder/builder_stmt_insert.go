@@ -3,10 +3,12 @@ package builder
 import "context"
 
 type StmtInsert struct {
+	with        *StmtWith
 	tbl         *Table
 	modifiers   []string
 	assignments []*Assignment
 	adds        Additions
+	returning   *Columns
 }
 
 func Insert(modifiers ...string) *StmtInsert {
@@ -23,12 +25,40 @@ func (s StmtInsert) Values(cols *Columns, values ...interface{}) *StmtInsert {
 	return &s
 }
 
+// Table returns table inserted into by s
+func (s *StmtInsert) Table() *Table { return s.tbl }
+
+// Rows returns columns and values grouped by row of s, rows is nil if values
+// are selected from other statement
+func (s *StmtInsert) Rows() (*Columns, [][]interface{}) {
+	if s.IsNil() {
+		return nil, nil
+	}
+	a := s.assignments[0]
+	cols, ok := a.columns.(*Columns)
+	if !ok || a.colc == 0 {
+		return nil, nil
+	}
+	if len(a.values) == 1 {
+		if _, ok := a.values[0].(SelectStatement); ok {
+			return cols, nil
+		}
+	}
+	rows := make([][]interface{}, 0, len(a.values)/a.colc)
+	for i := 0; i+a.colc <= len(a.values); i += a.colc {
+		rows = append(rows, a.values[i:i+a.colc])
+	}
+	return cols, rows
+}
+
 func (s *StmtInsert) IsNil() bool {
 	return s == nil || s.tbl == nil || len(s.assignments) == 0
 }
 
 func (s *StmtInsert) Ex(ctx context.Context) *Ex {
-	e := Expr("INSERT")
+	e := Expr("")
+	writeWith(e, s.with)
+	e.WriteQuery("INSERT")
 	if len(s.modifiers) > 0 {
 		for i := range s.modifiers {
 			e.WriteQueryByte(' ')
@@ -47,8 +77,17 @@ func (s *StmtInsert) Ex(ctx context.Context) *Ex {
 		return e.Ex(ctx)
 	}))
 	WriteAdditions(e, s.adds...)
+	if !IsNilExpr(s.returning) {
+		e.WriteQuery("\nRETURNING ")
+		e.WriteExpr(s.returning)
+	}
 	return e.Ex(ctx)
 }
 
+// Returning returns values of cols of inserted rows
+func (s StmtInsert) Returning(cols *Columns) *StmtInsert {
+	s.returning = cols
+	return &s
+}
+
 // TODO OnDuplicateKeyUpdate (mysql feature)
-// TODO Returning
@@ -10,6 +10,7 @@ type SelectStatement interface {
 type StmtSelect struct {
 	SelectStatement
 
+	with      *StmtWith
 	expr      SqlExpr
 	tbl       *Table
 	modifiers []string
@@ -43,8 +44,11 @@ func (s *StmtSelect) Ex(ctx context.Context) *Ex {
 		ctx = ContextWithToggleMultiTable(ctx, true)
 	}
 
-	e := Expr("SELECT")
-	e.Grow(len(s.adds) + 2)
+	e := Expr("")
+	e.Grow(len(s.adds) + 3)
+
+	writeWith(e, s.with)
+	e.WriteQuery("SELECT")
 
 	if len(s.modifiers) > 0 {
 		for i := range s.modifiers {
@@ -63,7 +67,7 @@ func (s *StmtSelect) Ex(ctx context.Context) *Ex {
 		e.WriteQuery(" FROM ")
 		e.WriteExpr(s.tbl)
 	}
-	WriteAdditions(e, s.adds...)
+	WriteAdditions(e, withSoftDeleteScope(s.tbl, s.adds)...)
 	return e.Ex(ctx)
 }
 
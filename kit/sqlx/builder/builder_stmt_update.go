@@ -3,6 +3,7 @@ package builder
 import "context"
 
 type StmtUpdate struct {
+	with        *StmtWith
 	tbl         *Table
 	modifiers   []string
 	assignments []*Assignment
@@ -17,6 +18,17 @@ func (s *StmtUpdate) IsNil() bool {
 	return s == nil || IsNilExpr(s.tbl) || len(s.assignments) == 0
 }
 
+// Table returns table updated by s
+func (s *StmtUpdate) Table() *Table { return s.tbl }
+
+// Selection selects e from rows would be updated by s, eg: capturing rows
+// before changed. common tables of s are kept for condition referring them
+func (s *StmtUpdate) Selection(e SqlExpr, adds ...Addition) *StmtSelect {
+	sel := Select(e).From(s.tbl, append(append([]Addition{}, s.adds...), adds...)...)
+	sel.with = s.with
+	return sel
+}
+
 func (s StmtUpdate) Set(assignments ...*Assignment) *StmtUpdate {
 	s.assignments = assignments
 	return &s
@@ -31,7 +43,9 @@ func (s StmtUpdate) Where(c SqlCondition, adds ...Addition) *StmtUpdate {
 }
 
 func (s *StmtUpdate) Ex(ctx context.Context) *Ex {
-	e := Expr("UPDATE")
+	e := Expr("")
+	writeWith(e, s.with)
+	e.WriteQuery("UPDATE")
 	if len(s.modifiers) > 0 {
 		for i := range s.modifiers {
 			e.WriteQueryByte(' ')
@@ -43,7 +57,7 @@ func (s *StmtUpdate) Ex(ctx context.Context) *Ex {
 	e.WriteQuery(" SET ")
 
 	WriteAssignments(e, s.assignments...)
-	WriteAdditions(e, s.adds...)
+	WriteAdditions(e, withSoftDeleteScope(s.tbl, s.adds)...)
 	return e.Ex(ctx)
 }
 
@@ -1,3 +1,121 @@
 package builder
 
-// TODO with clause https://www.sqlite.org/lang_with.html
+import "context"
+
+// CTE modifiers, https://www.postgresql.org/docs/current/queries-with.html
+const (
+	Materialized    = "MATERIALIZED"
+	NotMaterialized = "NOT MATERIALIZED"
+)
+
+// With starts a WITH clause by common table expression named as tbl, columns
+// of tbl are written as column list of the cte if defined.
+// https://www.sqlite.org/lang_with.html
+func With(tbl *Table, as SelectStatement, modifiers ...string) *StmtWith {
+	return (&StmtWith{}).With(tbl, as, modifiers...)
+}
+
+// WithRecursive starts a WITH RECURSIVE clause, the cte can refer to itself
+// by tbl in as
+func WithRecursive(tbl *Table, as SelectStatement, modifiers ...string) *StmtWith {
+	return (&StmtWith{recursive: true}).With(tbl, as, modifiers...)
+}
+
+type StmtWith struct {
+	recursive bool
+	ctes      []*cte
+}
+
+type cte struct {
+	tbl       *Table
+	as        SelectStatement
+	modifiers []string
+}
+
+// With appends common table expression, modifiers are written before `as`
+// such as Materialized and NotMaterialized
+func (w StmtWith) With(tbl *Table, as SelectStatement, modifiers ...string) *StmtWith {
+	w.ctes = append(
+		append(make([]*cte, 0, len(w.ctes)+1), w.ctes...),
+		&cte{tbl: tbl, as: as, modifiers: modifiers},
+	)
+	return &w
+}
+
+func (w *StmtWith) IsNil() bool {
+	if w == nil || len(w.ctes) == 0 {
+		return true
+	}
+	for _, c := range w.ctes {
+		if IsNilExpr(c.tbl) || IsNilExpr(c.as) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *StmtWith) Ex(ctx context.Context) *Ex {
+	// cte is an independent statement
+	ctx = ContextWithToggles(ctx, Toggles{
+		ToggleMultiTable:    false,
+		ToggleNeedAutoAlias: false,
+	})
+
+	e := Expr("WITH ")
+	if w.recursive {
+		e.WriteQuery("RECURSIVE ")
+	}
+
+	for i := range w.ctes {
+		c := w.ctes[i]
+		if i > 0 {
+			e.WriteQuery(", ")
+		}
+		e.WriteExpr(c.tbl)
+		if c.tbl.Columns.Len() > 0 {
+			e.WriteGroup(func(e *Ex) {
+				e.WriteExpr(&c.tbl.Columns)
+			})
+		}
+		e.WriteQuery(" AS ")
+		for _, m := range c.modifiers {
+			e.WriteQuery(m)
+			e.WriteQueryByte(' ')
+		}
+		e.WriteGroup(func(e *Ex) {
+			e.WriteExpr(c.as)
+		})
+	}
+	return e.Ex(ctx)
+}
+
+func (w *StmtWith) Select(expr SqlExpr, modifiers ...string) *StmtSelect {
+	s := Select(expr, modifiers...)
+	s.with = w
+	return s
+}
+
+func (w *StmtWith) Update(tbl *Table, modifiers ...string) *StmtUpdate {
+	s := Update(tbl, modifiers...)
+	s.with = w
+	return s
+}
+
+func (w *StmtWith) Delete() *StmtDelete {
+	s := Delete()
+	s.with = w
+	return s
+}
+
+func (w *StmtWith) Insert(modifiers ...string) *StmtInsert {
+	s := Insert(modifiers...)
+	s.with = w
+	return s
+}
+
+func writeWith(e *Ex, w *StmtWith) {
+	if !IsNilExpr(w) {
+		e.WriteExpr(w)
+		e.WriteQueryByte('\n')
+	}
+}
@@ -0,0 +1,71 @@
+package builder
+
+import "context"
+
+// Exists if select statement s returns any row
+func Exists(s SelectStatement) SqlCondition {
+	if IsNilExpr(s) {
+		return nil
+	}
+	return AsCond(Expr("EXISTS ?", subquery(s)))
+}
+
+// NotExists if select statement s returns no row
+func NotExists(s SelectStatement) SqlCondition {
+	if IsNilExpr(s) {
+		return nil
+	}
+	return AsCond(Expr("NOT EXISTS ?", subquery(s)))
+}
+
+// Any compares to any value of select statement or array, eg:
+// col.Eq(Any(stmt)) is `f_id = ANY (SELECT ...)`
+func Any(e SqlExpr) SqlExpr { return Expr("ANY ?", subquery(e)) }
+
+// All compares to all values of select statement or array, eg:
+// col.Gt(All(stmt)) is `f_id > ALL (SELECT ...)`
+func All(e SqlExpr) SqlExpr { return Expr("ALL ?", subquery(e)) }
+
+// InSelect if value of c in result of select statement s
+func (c *Column) InSelect(s SelectStatement) SqlCondition {
+	if IsNilExpr(s) {
+		return nil
+	}
+	return AsCond(Expr("? IN ?", c, subquery(s)))
+}
+
+// NotInSelect if value of c not in result of select statement s
+func (c *Column) NotInSelect(s SelectStatement) SqlCondition {
+	if IsNilExpr(s) {
+		return nil
+	}
+	return AsCond(Expr("? NOT IN ?", c, subquery(s)))
+}
+
+// Derived returns table derived from select statement as, which is written as
+// `(SELECT ...) AS name` in From or Join, and its columns are referred by
+// name. columns of tbl should be selected by as, eg: by Alias
+func Derived(tbl *Table, as SelectStatement) *Table {
+	t := *tbl
+	t.derived = as
+	// rows of derived table are scoped by as
+	t.SoftDelete = ""
+
+	cols := Columns{}
+	t.Columns.Range(func(c *Column, idx int) {
+		cols.Add(c.On(&t))
+	})
+	t.Columns = cols
+	return &t
+}
+
+// subquery writes e in parentheses, columns in subquery are not aliased
+func subquery(e SqlExpr) SqlExpr {
+	return ExprBy(func(ctx context.Context) *Ex {
+		ex := Expr("")
+		ex.WriteGroup(func(ex *Ex) {
+			ex.WriteExpr(e)
+		})
+		return ex.Ex(ContextWithToggleNeedAutoAlias(ctx, false))
+	})
+}
@@ -174,6 +174,7 @@ func TableFromModel(m Model) *Table {
 	}
 	tbl := T(m.TableName())
 	tbl.Model = m
+	tbl.ModelName = t.Name()
 	ScanDefToTable(tbl, m)
 	return tbl
 }
@@ -191,6 +192,12 @@ func ScanDefToTable(tbl *Table, i interface{}) {
 		},
 	)
 
+	tbl.Columns.Range(func(col *Column, _ int) {
+		if col.Check != "" && col.DeprecatedActs == nil {
+			tbl.AddConstraint(Check(ColumnCheckName(col), col.Check))
+		}
+	})
+
 	if with, ok := i.(WithTableDesc); ok {
 		tbl.Desc = with.TableDesc()
 	}
@@ -215,6 +222,15 @@ func ScanDefToTable(tbl *Table, i interface{}) {
 			}
 		}
 	}
+	if with, ok := i.(WithSoftDelete); ok {
+		tbl.SoftDelete = with.SoftDeleteFieldName()
+	}
+	if with, ok := i.(WithAudit); ok {
+		tbl.Audit = with.AuditEnabled()
+	}
+	if with, ok := i.(WithPartition); ok {
+		tbl.Partition = with.Partition()
+	}
 	if with, ok := i.(WithPrimaryKey); ok {
 		tbl.AddKey(&Key{
 			Name:     "primary",
@@ -233,6 +249,16 @@ func ScanDefToTable(tbl *Table, i interface{}) {
 			})
 		}
 	}
+	if with, ok := i.(WithChecks); ok {
+		for name, expr := range with.Checks() {
+			tbl.AddConstraint(Check(name, expr))
+		}
+	}
+	if with, ok := i.(WithExclusions); ok {
+		for name, def := range with.Exclusions() {
+			tbl.AddConstraint(Exclude(name, def))
+		}
+	}
 	if with, ok := i.(WithIndexes); ok {
 		for _index, names := range with.Indexes() {
 			name, method := SplitIndexNameAndMethod(_index)
@@ -167,3 +167,46 @@ ORDER BY (f_a) ASC,(f_b) DESC
 		))
 	})
 }
+
+func TestSeek(t *testing.T) {
+	tbl := T("T")
+	cols := []*Column{Col("F_a"), Col("F_b")}
+
+	t.Run("Asc", func(t *testing.T) {
+		g.NewWithT(t).Expect(
+			Select(nil).
+				From(
+					tbl,
+					Where(Seek(false, cols, 1, 2)),
+					SeekOrderBy(false, cols...),
+					Limit(10),
+				),
+		).To(BeExpr(`
+SELECT * FROM T
+WHERE (f_a > ?) OR ((f_a = ?) AND (f_b > ?))
+ORDER BY (f_a) ASC,(f_b) ASC
+LIMIT 10
+`, 1, 1, 2,
+		))
+	})
+	t.Run("Desc", func(t *testing.T) {
+		g.NewWithT(t).Expect(
+			Select(nil).
+				From(
+					tbl,
+					Where(Seek(true, cols[:1], 1)),
+					SeekOrderBy(true, cols[:1]...),
+				),
+		).To(BeExpr(`
+SELECT * FROM T
+WHERE f_a < ?
+ORDER BY (f_a) DESC
+`, 1,
+		))
+	})
+	t.Run("Mismatched", func(t *testing.T) {
+		g.NewWithT(t).Expect(
+			Select(nil).From(tbl, Where(Seek(false, cols, 1))),
+		).To(BeExpr(`SELECT * FROM T`))
+	})
+}
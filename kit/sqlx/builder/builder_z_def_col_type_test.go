@@ -33,6 +33,14 @@ func TestAnalyzeColumnType(t *testing.T) {
 				AutoIncrement: true,
 			},
 		}, {
+			"Version",
+			`,version`,
+			&ColumnType{
+				Type:    typesx.FromReflectType(reflect.TypeOf(uint64(0))),
+				Version: true,
+				Default: ptrx.String(`'0'`),
+			},
+		}, {
 
 			"Null",
 			`,null`,
@@ -61,6 +69,28 @@ func TestAnalyzeColumnType(t *testing.T) {
 				Type:    typesx.FromReflectType(reflect.TypeOf("")),
 				Default: ptrx.String(`'1'`),
 			},
+		}, {
+			"Check",
+			`,check=f_age >= 0`,
+			&ColumnType{
+				Type:  typesx.FromReflectType(reflect.TypeOf(0)),
+				Check: "f_age >= 0",
+			},
+		}, {
+			"CheckWithComma",
+			`,check=f_status IN (1, 2) AND f_name <> 'a,b',null`,
+			&ColumnType{
+				Type:  typesx.FromReflectType(reflect.TypeOf(0)),
+				Check: "f_status IN (1, 2) AND f_name <> 'a,b'",
+				Null:  true,
+			},
+		}, {
+			"Generated",
+			`,generated=f_price * f_quantity`,
+			&ColumnType{
+				Type:      typesx.FromReflectType(reflect.TypeOf(float64(0))),
+				Generated: "f_price * f_quantity",
+			},
 		},
 	}
 
@@ -72,3 +102,9 @@ func TestAnalyzeColumnType(t *testing.T) {
 		})
 	}
 }
+
+func TestAnalyzeColumnType_Unbalanced(t *testing.T) {
+	g.NewWithT(t).Expect(func() {
+		AnalyzeColumnType(typesx.FromReflectType(reflect.TypeOf(0)), `,check=f_status IN (1, 2`)
+	}).To(g.PanicWith(g.ContainSubstring("unbalanced")))
+}
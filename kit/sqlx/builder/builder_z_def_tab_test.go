@@ -53,3 +53,77 @@ WHERE t_user.f_id > 1
 
 	// TODO table diff
 }
+
+func TestTables_SortedTableNames(t *testing.T) {
+	tA := T("t_a", Col("f_b_id").Field("BID"))
+	tB := T("t_b", Col("f_id").Field("ID"), Col("f_c_id").Field("CID"))
+	tC := T("t_c", Col("f_id").Field("ID"), Col("f_b_id").Field("BID"))
+	tD := T("t_d", Col("f_id").Field("ID"))
+
+	tA.ColByFieldName("BID").Rel = []string{"t_b", "ID"}
+	tA.ColByFieldName("BID").ForeignKey = true
+	// cycle of t_b and t_c
+	tB.ColByFieldName("CID").Rel = []string{"t_c", "ID"}
+	tC.ColByFieldName("BID").Rel = []string{"t_b", "ID"}
+
+	tables := Tables{}
+	tables.Add(tA, tB, tC, tD)
+
+	g.NewWithT(t).Expect(tables.SortedTableNames()).To(g.Equal([]string{"t_c", "t_b", "t_a", "t_d"}))
+	g.NewWithT(t).Expect(tables.ForeignKeysOf(tA)[0].Ref).To(g.Equal(tB.Col("f_id")))
+}
+
+type Booking struct {
+	RoomID    uint64  `db:"f_room_id"`
+	StartedAt int64   `db:"f_started_at"`
+	EndedAt   int64   `db:"f_ended_at"`
+	Price     float64 `db:"f_price,check=f_price >= 0"`
+	Total     float64 `db:"f_total,generated=f_price * (f_ended_at - f_started_at)"`
+}
+
+func (Booking) TableName() string { return "t_booking" }
+
+func (Booking) Checks() map[string]string {
+	return map[string]string{"period": "#StartedAt < #EndedAt"}
+}
+
+func (Booking) Exclusions() map[string]string {
+	return map[string]string{"room_period": "USING gist (#RoomID WITH =, int8range(#StartedAt, #EndedAt) WITH &&)"}
+}
+
+func TestTableFromModel_Constraints(t *testing.T) {
+	tbl := TableFromModel(&Booking{})
+
+	g.NewWithT(t).Expect(tbl.Constraints.Len()).To(g.Equal(3))
+	g.NewWithT(t).Expect(tbl.Constraints.Constraint("f_price_check")).
+		To(BeExpr("CHECK (f_price >= 0)"))
+	g.NewWithT(t).Expect(tbl.Constraints.Constraint("period")).
+		To(BeExpr("CHECK (f_started_at < f_ended_at)"))
+	g.NewWithT(t).Expect(tbl.Constraints.Constraint("room_period")).
+		To(BeExpr("EXCLUDE USING gist (f_room_id WITH =, int8range(f_started_at, f_ended_at) WITH &&)"))
+
+	g.NewWithT(t).Expect(tbl.ColByFieldName("Total").Generated).
+		To(g.Equal("f_price * (f_ended_at - f_started_at)"))
+	cols, _ := tbl.ColumnsAndValuesByFieldValues(FieldValues{"Price": 1, "Total": 2})
+	g.NewWithT(t).Expect(cols.FieldNames()).To(g.Equal([]string{"Price"}))
+}
+
+func TestConstraint_Equal(t *testing.T) {
+	tbl := T("t_booking", Col("f_status").Field("Status"), Col("f_price").Field("Price"))
+
+	// definitions as postgres rewrites them
+	cases := map[string]string{
+		"#Status IN (1, 2)":                       "((f_status = ANY (ARRAY[1, 2])))",
+		"#Status NOT IN ('a', 'b')":               "(((f_status)::text <> ALL ((ARRAY['a'::character varying, 'b'::character varying])::text[])))",
+		"#Price BETWEEN 0 AND 100":                "(((f_price >= (0)::double precision) AND (f_price <= (100)::double precision)))",
+		"#Price NOT BETWEEN 0 AND 100":            "(((f_price < (0)::double precision) OR (f_price > (100)::double precision)))",
+		"#Price >= 0 AND #Status IN ('a', 'b')":   "(((f_price >= (0)::double precision) AND ((f_status)::text = ANY ((ARRAY['a'::character varying, 'b'::character varying])::text[]))))",
+		"#Price > 0 AND #Price::numeric < 100.00": "(((f_price > (0)::double precision) AND ((f_price)::numeric < 100.00)))",
+	}
+	for def, stored := range cases {
+		c := Check("c", def).On(tbl)
+		g.NewWithT(t).Expect(c.Equal(Check("c", stored).On(tbl))).To(g.BeTrue(), def)
+	}
+	g.NewWithT(t).Expect(Check("c", "#Status IN (1, 2)").On(tbl).
+		Equal(Check("c", "((f_status = ANY (ARRAY[1, 3])))").On(tbl))).To(g.BeFalse())
+}
@@ -1,6 +1,7 @@
 package builder_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/onsi/gomega"
@@ -20,3 +21,50 @@ func TestFunc(t *testing.T) {
 		gomega.NewWithT(t).Expect(builder.Avg()).To(buildertestutil.BeExpr("AVG(*)"))
 	})
 }
+
+func TestWindowFunc(t *testing.T) {
+	table := builder.T("t_user",
+		builder.Col("f_id"),
+		builder.Col("f_org_id"),
+		builder.Col("f_score"),
+	)
+	orgID, score := table.Col("f_org_id"), table.Col("f_score")
+
+	t.Run("Over", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(
+			builder.RowNumber().Over(
+				builder.PartitionBy(orgID),
+				builder.OrderBy(builder.DescOrder(score)),
+			),
+		).To(buildertestutil.BeExpr("ROW_NUMBER() OVER (PARTITION BY f_org_id ORDER BY (f_score) DESC)"))
+	})
+	t.Run("OverWithFrame", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(
+			builder.Sum(score).Over(
+				builder.OrderBy(builder.AscOrder(table.Col("f_id"))),
+				builder.Frame("ROWS BETWEEN ? PRECEDING AND CURRENT ROW", 2),
+			),
+		).To(buildertestutil.BeExpr("SUM(f_score) OVER (ORDER BY (f_id) ASC ROWS BETWEEN ? PRECEDING AND CURRENT ROW)", 2))
+	})
+	t.Run("OverWindow", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(builder.Lag(score, builder.Expr("1")).OverWindow("w")).
+			To(buildertestutil.BeExpr("LAG(f_score,1) OVER w"))
+	})
+	t.Run("Filter", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(builder.Count().Filter(score.Gt(60))).
+			To(buildertestutil.BeExpr("COUNT(1) FILTER (WHERE f_score > ?)", 60))
+	})
+	t.Run("FilterOver", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(builder.Avg(score).Filter(score.Gt(0)).Over(builder.PartitionBy(orgID))).
+			To(buildertestutil.BeExpr("AVG(f_score) FILTER (WHERE f_score > ?) OVER (PARTITION BY f_org_id)", 0))
+	})
+	t.Run("AutoAlias", func(t *testing.T) {
+		f := builder.DenseRank().Over(builder.PartitionBy(orgID), builder.OrderBy(builder.DescOrder(score)))
+		gomega.NewWithT(t).Expect(builder.ExprBy(func(ctx context.Context) *builder.Ex {
+			return f.Ex(builder.ContextWithToggles(ctx, builder.Toggles{
+				builder.ToggleMultiTable:    true,
+				builder.ToggleNeedAutoAlias: true,
+			}))
+		})).To(buildertestutil.BeExpr("DENSE_RANK() OVER (PARTITION BY t_user.f_org_id ORDER BY (t_user.f_score) DESC)"))
+	})
+}
@@ -57,6 +57,18 @@ INSERT INTO T (f_a,f_b) VALUES (?,?)
 		).To(BeExpr("INSERT INTO T (f_a,f_b) VALUES (?,?),(?,?),(?,?)", 1, 2, 1, 2, 1, 2))
 	})
 
+	t.Run("Returning", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(
+			Insert().
+				Into(table).
+				Values(Cols("f_a"), 1, 2).
+				Returning(Cols("f_a", "f_b")),
+		).To(BeExpr(`
+INSERT INTO T (f_a) VALUES (?),(?)
+RETURNING f_a,f_b
+`, 1, 2))
+	})
+
 	t.Run("FromSelect", func(t *testing.T) {
 		gomega.NewWithT(t).Expect(
 			Insert().
@@ -131,6 +143,30 @@ FOR UPDATE
 	})
 }
 
+func TestSelectWindow(t *testing.T) {
+	table := T("t_user", Col("f_id"), Col("f_org_id"), Col("f_score"))
+	org := T("t_org", Col("f_id"), Col("f_name"))
+
+	gomega.NewWithT(t).Expect(
+		Select(MultiWith(",",
+			table.Col("f_id"),
+			Alias(Rank().OverWindow("w"), "f_rank"),
+			Alias(Sum(table.Col("f_score")).OverWindow("w_all"), "f_total"),
+		)).From(
+			table,
+			Join(org).On(table.Col("f_org_id").Eq(org.Col("f_id"))),
+			OrderBy(AscOrder(table.Col("f_id"))),
+			Window("w", PartitionBy(table.Col("f_org_id")), OrderBy(DescOrder(table.Col("f_score")))).
+				Window("w_all", PartitionBy(table.Col("f_org_id"))),
+		),
+	).To(BeExpr(`
+SELECT t_user.f_id,RANK() OVER w AS f_rank,SUM(t_user.f_score) OVER w_all AS f_total FROM t_user
+JOIN t_org ON t_user.f_org_id = t_org.f_id
+WINDOW w AS (PARTITION BY t_user.f_org_id ORDER BY (t_user.f_score) DESC), w_all AS (PARTITION BY t_user.f_org_id)
+ORDER BY (t_user.f_id) ASC
+`))
+}
+
 func TestStmtUpdate(t *testing.T) {
 	table := T("T")
 
@@ -151,3 +187,371 @@ WHERE f_a = ?
 /* Comment */`, 1, 2, 1))
 	})
 }
+
+func TestStmtWith(t *testing.T) {
+	org := T("t_org", Col("f_id"), Col("f_parent_id"), Col("f_name"))
+	tree := T("t_org_tree", Col("f_id"), Col("f_parent_id"))
+
+	t.Run("Select", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(
+			With(
+				tree,
+				Select(MultiWith(",", org.Col("f_id"), org.Col("f_parent_id"))).
+					From(org, Where(org.Col("f_parent_id").Eq(0))),
+				Materialized,
+			).Select(nil).From(tree),
+		).To(BeExpr(`
+WITH t_org_tree(f_id,f_parent_id) AS MATERIALIZED (SELECT f_id,f_parent_id FROM t_org
+WHERE f_parent_id = ?)
+SELECT * FROM t_org_tree`, 0))
+	})
+
+	t.Run("Recursive", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(
+			WithRecursive(
+				tree,
+				Select(MultiWith(",", org.Col("f_id"), org.Col("f_parent_id"))).
+					From(org, Where(org.Col("f_id").Eq(1))),
+			).Select(nil).From(tree),
+		).To(BeExpr(`
+WITH RECURSIVE t_org_tree(f_id,f_parent_id) AS (SELECT f_id,f_parent_id FROM t_org
+WHERE f_id = ?)
+SELECT * FROM t_org_tree`, 1))
+	})
+
+	t.Run("MultiCTEs", func(t *testing.T) {
+		names := T("t_names")
+		gomega.NewWithT(t).Expect(
+			With(tree, Select(nil).From(org)).
+				With(names, Select(org.Col("f_name")).From(org), NotMaterialized).
+				Select(nil).
+				From(tree, Join(names).On(tree.Col("f_id").Eq(1))),
+		).To(BeExpr(`
+WITH t_org_tree(f_id,f_parent_id) AS (SELECT * FROM t_org), t_names AS NOT MATERIALIZED (SELECT f_name FROM t_org)
+SELECT * FROM t_org_tree
+JOIN t_names ON t_org_tree.f_id = ?`, 1))
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(
+			With(tree, Select(nil).From(org)).
+				Update(org).
+				Set(org.Col("f_name").ValueBy("x")).
+				Where(org.Col("f_id").In(Select(tree.Col("f_id")).From(tree))),
+		).To(BeExpr(`
+WITH t_org_tree(f_id,f_parent_id) AS (SELECT * FROM t_org)
+UPDATE t_org SET f_name = ?
+WHERE f_id IN (SELECT f_id FROM t_org_tree)`, "x"))
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(
+			With(tree, Select(nil).From(org)).
+				Delete().
+				From(org, Where(org.Col("f_id").In(Select(tree.Col("f_id")).From(tree)))),
+		).To(BeExpr(`
+WITH t_org_tree(f_id,f_parent_id) AS (SELECT * FROM t_org)
+DELETE FROM t_org
+WHERE f_id IN (SELECT f_id FROM t_org_tree)`))
+	})
+
+	t.Run("Insert", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(
+			With(tree, Select(nil).From(org)).
+				Insert().
+				Into(org).
+				Values(Cols("f_id", "f_parent_id"), Select(nil).From(tree)),
+		).To(BeExpr(`
+WITH t_org_tree(f_id,f_parent_id) AS (SELECT * FROM t_org)
+INSERT INTO t_org (f_id,f_parent_id) SELECT * FROM t_org_tree`))
+	})
+}
+
+func TestSoftDeleteScope(t *testing.T) {
+	table := T("T", Col("F_a").Field("A"), Col("F_deleted_at").Field("DeletedAt"))
+	table.SoftDelete = "DeletedAt"
+
+	t.Run("SelectAlive", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(
+			Select(nil).From(table, Where(Col("F_a").Eq(1))),
+		).To(BeExpr(`
+SELECT * FROM T
+WHERE (f_deleted_at = ?) AND (f_a = ?)
+`, 0, 1))
+	})
+
+	t.Run("SelectWithoutWhere", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(
+			Select(nil).From(table, Limit(1)),
+		).To(BeExpr(`
+SELECT * FROM T
+WHERE f_deleted_at = ?
+LIMIT 1
+`, 0))
+	})
+
+	t.Run("SelectWithDeleted", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(
+			Select(nil).From(table, Where(Col("F_a").Eq(1)), WithDeleted()),
+		).To(BeExpr(`
+SELECT * FROM T
+WHERE f_a = ?
+`, 1))
+	})
+
+	t.Run("UpdateOnlyDeleted", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(
+			Update(table).
+				Where(Col("F_a").Eq(1), OnlyDeleted()).
+				Set(Col("F_deleted_at").ValueBy(0)),
+		).To(BeExpr(`
+UPDATE T SET f_deleted_at = ?
+WHERE (f_deleted_at <> ?) AND (f_a = ?)
+`, 0, 0, 1))
+	})
+
+	t.Run("DeleteNotScoped", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(
+			Delete().From(table, Where(Col("F_a").Eq(1))),
+		).To(BeExpr(`
+DELETE FROM T
+WHERE f_a = ?
+`, 1))
+	})
+}
+
+func TestStmtSelection(t *testing.T) {
+	table := T("T", Col("F_a").Field("A"), Col("F_deleted_at").Field("DeletedAt"))
+	table.SoftDelete = "DeletedAt"
+
+	t.Run("Update", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(
+			Update(table).
+				Where(Col("F_a").Eq(1)).
+				Set(Col("F_a").ValueBy(2)).
+				Selection(nil),
+		).To(BeExpr(`
+SELECT * FROM T
+WHERE (f_deleted_at = ?) AND (f_a = ?)
+`, 0, 1))
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(
+			Delete().From(table, Where(Col("F_a").Eq(1))).Selection(nil),
+		).To(BeExpr(`
+SELECT * FROM T
+WHERE f_a = ?
+`, 1))
+	})
+
+	t.Run("ForUpdate", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(
+			Delete().From(table, Where(Col("F_a").Eq(1))).Selection(nil, ForUpdate()),
+		).To(BeExpr(`
+SELECT * FROM T
+WHERE f_a = ?
+FOR UPDATE
+`, 1))
+	})
+
+	t.Run("With", func(t *testing.T) {
+		tree := T("t_tree", Col("f_a"))
+		sub := Select(tree.Col("f_a")).From(tree)
+
+		gomega.NewWithT(t).Expect(
+			With(tree, Select(nil).From(table)).
+				Update(table).
+				Where(Col("F_a").In(sub)).
+				Set(Col("F_a").ValueBy(2)).
+				Selection(nil),
+		).To(BeExpr(`
+WITH t_tree(f_a) AS (SELECT * FROM T
+WHERE f_deleted_at = ?)
+SELECT * FROM T
+WHERE (f_deleted_at = ?) AND (f_a IN (SELECT f_a FROM t_tree))
+`, 0, 0))
+
+		gomega.NewWithT(t).Expect(
+			With(tree, Select(nil).From(table)).
+				Delete().
+				From(table, Where(Col("F_a").In(sub))).
+				Selection(nil),
+		).To(BeExpr(`
+WITH t_tree(f_a) AS (SELECT * FROM T
+WHERE f_deleted_at = ?)
+SELECT * FROM T
+WHERE f_a IN (SELECT f_a FROM t_tree)
+`, 0))
+	})
+
+	t.Run("InsertRows", func(t *testing.T) {
+		cols, rows := Insert().Into(table).
+			Values(Cols("F_a", "F_deleted_at"), 1, 0, 2, 0).
+			Rows()
+		gomega.NewWithT(t).Expect(cols.ColNames()).To(gomega.Equal([]string{"f_a", "f_deleted_at"}))
+		gomega.NewWithT(t).Expect(rows).To(gomega.Equal([][]interface{}{{1, 0}, {2, 0}}))
+	})
+}
+
+func TestStmtCombination(t *testing.T) {
+	user := T("t_user", Col("f_id"), Col("f_name"), Col("f_org_id"))
+	admin := T("t_admin", Col("f_id"), Col("f_name"))
+	banned := T("t_banned", Col("f_id"), Col("f_name"))
+
+	t.Run("UnionAll", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(
+			UnionAll(
+				Select(Cols("f_id", "f_name")).From(user, Where(user.Col("f_org_id").Eq(1))),
+				Select(Cols("f_id", "f_name")).From(admin),
+			).OrderBy(AscOrder(Col("f_name"))).Limit(10).Offset(20),
+		).To(BeExpr(`
+SELECT f_id,f_name FROM t_user
+WHERE f_org_id = ?
+UNION ALL
+SELECT f_id,f_name FROM t_admin
+ORDER BY (f_name) ASC
+LIMIT 10 OFFSET 20
+`, 1))
+	})
+
+	t.Run("Nested", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(
+			Except(
+				Select(Cols("f_id")).From(user),
+				Union(
+					Select(Cols("f_id")).From(admin),
+					Select(Cols("f_id")).From(banned),
+				),
+			).Intersect(Select(Cols("f_id")).From(user, Where(user.Col("f_org_id").Eq(2)))),
+		).To(BeExpr(`
+(SELECT f_id FROM t_user
+EXCEPT
+(SELECT f_id FROM t_admin
+UNION
+SELECT f_id FROM t_banned))
+INTERSECT
+SELECT f_id FROM t_user
+WHERE f_org_id = ?
+`, 2))
+	})
+
+	t.Run("IntersectFirst", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(
+			Intersect(
+				Select(Cols("f_id")).From(user),
+				Select(Cols("f_id")).From(admin),
+			).Union(Select(Cols("f_id")).From(banned)).Intersect(Select(Cols("f_id")).From(admin)),
+		).To(BeExpr(`
+(SELECT f_id FROM t_user
+INTERSECT
+SELECT f_id FROM t_admin
+UNION
+SELECT f_id FROM t_banned)
+INTERSECT
+SELECT f_id FROM t_admin
+`))
+	})
+
+	t.Run("AsSubquery", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(
+			Select(nil).From(user, Where(user.Col("f_id").InSelect(
+				Union(Select(Cols("f_id")).From(admin), Select(Cols("f_id")).From(banned)),
+			))),
+		).To(BeExpr(`
+SELECT * FROM t_user
+WHERE f_id IN (SELECT f_id FROM t_admin
+UNION
+SELECT f_id FROM t_banned)
+`))
+	})
+}
+
+func TestSubquery(t *testing.T) {
+	user := T("t_user", Col("f_id"), Col("f_org_id"))
+	org := T("t_org", Col("f_id"), Col("f_name"))
+
+	t.Run("Exists", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(
+			Select(nil).From(org, Where(And(
+				Exists(Select(Expr("1")).From(user, Where(user.Col("f_org_id").Eq(1)))),
+				NotExists(Select(Expr("1")).From(user, Where(user.Col("f_org_id").Eq(2)))),
+			))),
+		).To(BeExpr(`
+SELECT * FROM t_org
+WHERE (EXISTS (SELECT 1 FROM t_user
+WHERE f_org_id = ?)) AND (NOT EXISTS (SELECT 1 FROM t_user
+WHERE f_org_id = ?))
+`, 1, 2))
+	})
+
+	t.Run("Any", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(
+			org.Col("f_id").Eq(Any(Select(user.Col("f_org_id")).From(user))),
+		).To(BeExpr(`f_id = ANY (SELECT f_org_id FROM t_user)`))
+		gomega.NewWithT(t).Expect(
+			org.Col("f_id").Gt(All(Select(user.Col("f_id")).From(user, Where(user.Col("f_org_id").Eq(1))))),
+		).To(BeExpr(`f_id > ALL (SELECT f_id FROM t_user
+WHERE f_org_id = ?)`, 1))
+	})
+
+	t.Run("NotInSelect", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(
+			org.Col("f_id").NotInSelect(Select(user.Col("f_org_id")).From(user)),
+		).To(BeExpr(`f_id NOT IN (SELECT f_org_id FROM t_user)`))
+		gomega.NewWithT(t).Expect(org.Col("f_id").InSelect(nil)).To(gomega.BeNil())
+	})
+
+	t.Run("Derived", func(t *testing.T) {
+		counts := Derived(
+			T("t_counts", Col("f_org_id"), Col("f_count")),
+			Select(MultiWith(",", user.Col("f_org_id"), Alias(Count(), "f_count"))).
+				From(user, GroupBy(user.Col("f_org_id"))),
+		)
+		gomega.NewWithT(t).Expect(
+			Select(MultiWith(",", org.Col("f_name"), counts.Col("f_count"))).
+				From(org, LeftJoin(counts).On(counts.Col("f_org_id").Eq(org.Col("f_id")))),
+		).To(BeExpr(`
+SELECT t_org.f_name,t_counts.f_count FROM t_org
+LEFT JOIN (SELECT t_user.f_org_id,COUNT(1) AS f_count FROM t_user
+GROUP BY t_user.f_org_id) AS t_counts ON t_counts.f_org_id = t_org.f_id
+`))
+	})
+
+	t.Run("FromDerived", func(t *testing.T) {
+		latest := Derived(
+			T("t_latest", Col("f_id")),
+			Select(user.Col("f_id")).From(user, OrderBy(DescOrder(user.Col("f_id"))), Limit(10)),
+		)
+		gomega.NewWithT(t).Expect(
+			UnionAll(Select(nil).From(latest), Select(org.Col("f_id")).From(org)),
+		).To(BeExpr(`
+SELECT * FROM (SELECT f_id FROM t_user
+ORDER BY (f_id) DESC
+LIMIT 10) AS t_latest
+UNION ALL
+SELECT f_id FROM t_org
+`))
+	})
+
+	t.Run("Lateral", func(t *testing.T) {
+		last := Derived(
+			T("t_last", Col("f_id")),
+			Select(user.Col("f_id")).From(user,
+				Where(user.Col("f_org_id").Eq(org.Col("f_id"))),
+				OrderBy(DescOrder(user.Col("f_id"))),
+				Limit(1),
+			),
+		)
+		gomega.NewWithT(t).Expect(
+			Select(MultiWith(",", org.Col("f_id"), last.Col("f_id"))).
+				From(org, LeftJoin(last).Lateral()),
+		).To(BeExpr(`
+SELECT t_org.f_id,t_last.f_id FROM t_org
+LEFT JOIN LATERAL (SELECT t_user.f_id FROM t_user
+WHERE t_user.f_org_id = t_org.f_id
+ORDER BY (t_user.f_id) DESC
+LIMIT 1) AS t_last ON TRUE
+`))
+	})
+}
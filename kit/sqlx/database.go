@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"database/sql/driver"
+	"fmt"
 	"time"
 
 	"github.com/saitofun/qkit/kit/sqlx/builder"
@@ -49,6 +50,13 @@ type TxExecutor interface {
 	Rollback() error
 }
 
+// SavepointExecutor nests transaction scope by savepoints
+type SavepointExecutor interface {
+	Savepoint() (string, error)
+	RollbackToSavepoint(string) error
+	ReleaseSavepoint(string) error
+}
+
 type Migrator interface {
 	Migrate(ctx context.Context, db DBExecutor) error
 }
@@ -108,16 +116,34 @@ func (d *Database) Register(m builder.Model) *builder.Table {
 	return t
 }
 
-func (d *Database) AddTable(t *builder.Table) { d.Tables.Add(t) }
+// AddTable adds table to database, AuditLog is registered when audit of table
+// enabled
+func (d *Database) AddTable(t *builder.Table) {
+	d.Tables.Add(t)
+	if t != nil && t.Audit && d.Table(AuditLog{}.TableName()) == nil {
+		audit := auditLogTable()
+		audit.Schema = d.Schema
+		d.Tables.Add(audit)
+	}
+}
 
 type DB struct {
 	ctx context.Context
+	tx  *txScope
 
 	dialect builder.Dialect
 	*Database
 	SqlExecutor
 }
 
+// txScope states shared by executors of the same transaction
+type txScope struct {
+	savepoints int
+	hooks      []func()
+	// marks of hooks length when savepoint created
+	marks map[string]int
+}
+
 func (d *DB) WithContext(ctx context.Context) DBExecutor {
 	e := new(DB)
 	*e = *d
@@ -149,6 +175,13 @@ func (d *DB) Migrate(ctx context.Context, db DBExecutor) error {
 }
 
 func (d *DB) Exec(e builder.SqlExpr) (sql.Result, error) {
+	if t := auditedTableOf(e); t != nil {
+		return d.execAudited(t, e)
+	}
+	return d.exec(e)
+}
+
+func (d *DB) exec(e builder.SqlExpr) (sql.Result, error) {
 	ex := builder.ResolveExprContext(d.Context(), e)
 	if builder.IsNilExpr(ex) {
 		return nil, nil
@@ -200,6 +233,7 @@ func (d *DB) BeginTx(opt *sql.TxOptions) (DBExecutor, error) {
 	e := *d
 	e.SqlExecutor = db
 	e.ctx = d.Context()
+	e.tx = &txScope{marks: map[string]int{}}
 	return &e, nil
 }
 
@@ -210,7 +244,15 @@ func (d *DB) Commit() error {
 	if d.Context().Err() == context.Canceled {
 		return context.Canceled
 	}
-	return d.SqlExecutor.(*sql.Tx).Commit()
+	if err := d.SqlExecutor.(*sql.Tx).Commit(); err != nil {
+		return err
+	}
+	hooks := d.tx.hooks
+	d.tx.hooks = nil
+	for _, hook := range hooks {
+		hook()
+	}
+	return nil
 }
 
 func (d *DB) Rollback() error {
@@ -220,9 +262,60 @@ func (d *DB) Rollback() error {
 	if d.Context().Err() == context.Canceled {
 		return context.Canceled
 	}
+	d.tx.hooks = nil
 	return d.SqlExecutor.(*sql.Tx).Rollback()
 }
 
+func (d *DB) Savepoint() (string, error) {
+	if !d.IsTx() {
+		return "", ErrNotTx
+	}
+	d.tx.savepoints++
+	name := fmt.Sprintf("sp_%d", d.tx.savepoints)
+	if _, err := d.Exec(d.dialect.Savepoint(name)); err != nil {
+		return "", err
+	}
+	d.tx.marks[name] = len(d.tx.hooks)
+	return name, nil
+}
+
+// RollbackToSavepoint rollbacks to savepoint, hooks registered after the
+// savepoint created are discarded
+func (d *DB) RollbackToSavepoint(name string) error {
+	if !d.IsTx() {
+		return ErrNotTx
+	}
+	if _, err := d.Exec(d.dialect.RollbackToSavepoint(name)); err != nil {
+		return err
+	}
+	if mark, ok := d.tx.marks[name]; ok {
+		d.tx.hooks = d.tx.hooks[0:mark]
+		delete(d.tx.marks, name)
+	}
+	return nil
+}
+
+func (d *DB) ReleaseSavepoint(name string) error {
+	if !d.IsTx() {
+		return ErrNotTx
+	}
+	if _, err := d.Exec(d.dialect.ReleaseSavepoint(name)); err != nil {
+		return err
+	}
+	delete(d.tx.marks, name)
+	return nil
+}
+
+// AfterCommit registers hook called after transaction committed, the hook is
+// called immediately if d is not in transaction
+func (d *DB) AfterCommit(hook func()) {
+	if !d.IsTx() {
+		hook()
+		return
+	}
+	d.tx.hooks = append(d.tx.hooks, hook)
+}
+
 func (d *DB) SetMaxOpenConns(n int) { d.SqlExecutor.(*sql.DB).SetMaxOpenConns(n) }
 
 func (d *DB) SetMaxIdleConns(n int) { d.SqlExecutor.(*sql.DB).SetMaxIdleConns(n) }
@@ -0,0 +1,213 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+	"time"
+
+	"github.com/saitofun/qkit/kit/sqlx/builder"
+	"github.com/saitofun/qkit/x/contextx"
+)
+
+type ReplicaPolicy int
+
+const (
+	// RoundRobin picks healthy replicas in turn
+	RoundRobin ReplicaPolicy = iota
+	// LeastConn picks healthy replica with the fewest in use connections
+	LeastConn
+)
+
+type Replica struct {
+	Name string
+	*DB
+
+	// unhealthy is shared by copies of replica, eg: RoutingDB.WithSchema
+	unhealthy *int32
+}
+
+func (r *Replica) Healthy() bool {
+	return r.unhealthy == nil || atomic.LoadInt32(r.unhealthy) == 0
+}
+
+// SetHealthy marks replica healthy or not, eg: replica failed to connect is
+// registered unhealthy and routed after probed healthy
+func (r *Replica) SetHealthy(healthy bool) {
+	if r.unhealthy == nil {
+		r.unhealthy = new(int32)
+	}
+	if healthy {
+		atomic.StoreInt32(r.unhealthy, 0)
+	} else {
+		atomic.StoreInt32(r.unhealthy, 1)
+	}
+}
+
+func (r *Replica) inUse() int {
+	if db, ok := r.SqlExecutor.(*sql.DB); ok {
+		return db.Stats().InUse
+	}
+	return 0
+}
+
+type keyForcePrimary struct{}
+
+// ContextWithForcePrimary makes RoutingDB send queries to primary, eg: for
+// reading just written rows
+func ContextWithForcePrimary(ctx context.Context) context.Context {
+	return contextx.WithValue(ctx, keyForcePrimary{}, true)
+}
+
+func ForcePrimaryFromContext(ctx context.Context) bool {
+	v, _ := ctx.Value(keyForcePrimary{}).(bool)
+	return v
+}
+
+// NewRoutingDB creates RoutingDB with primary and replicas
+func NewRoutingDB(primary *DB, policy ReplicaPolicy, replicas ...*Replica) *RoutingDB {
+	for _, r := range replicas {
+		if r.unhealthy == nil {
+			r.unhealthy = new(int32)
+		}
+	}
+	return &RoutingDB{
+		DB:     primary,
+		router: &router{policy: policy, replicas: replicas, next: new(uint64)},
+	}
+}
+
+// RoutingDB sends Query and QueryAndScan to one of healthy replicas, Exec and
+// transactions to primary. if no replica is healthy, queries are sent to
+// primary.
+type RoutingDB struct {
+	*DB // primary
+	*router
+}
+
+var _ interface {
+	DBExecutor
+	TxExecutor
+} = (*RoutingDB)(nil)
+
+type router struct {
+	policy   ReplicaPolicy
+	replicas []*Replica
+	// next is shared by routers of RoutingDB.WithSchema
+	next *uint64
+}
+
+func (d *RoutingDB) WithContext(ctx context.Context) DBExecutor {
+	return &RoutingDB{DB: d.DB.WithContext(ctx).(*DB), router: d.router}
+}
+
+func (d *RoutingDB) WithSchema(schema string) DBExecutor {
+	replicas := make([]*Replica, 0, len(d.replicas))
+	for _, r := range d.replicas {
+		replicas = append(replicas, &Replica{
+			Name:      r.Name,
+			DB:        r.DB.WithSchema(schema).(*DB),
+			unhealthy: r.unhealthy,
+		})
+	}
+	return &RoutingDB{
+		DB:     d.DB.WithSchema(schema).(*DB),
+		router: &router{policy: d.policy, replicas: replicas, next: d.next},
+	}
+}
+
+// Replicas returns replicas routed to
+func (d *RoutingDB) Replicas() []*Replica { return d.replicas }
+
+// Reader returns the executor queries are sent to
+func (d *RoutingDB) Reader() *DB {
+	if ForcePrimaryFromContext(d.Context()) {
+		return d.DB
+	}
+	r := d.pick()
+	if r == nil {
+		return d.DB
+	}
+	return r.DB.WithContext(d.Context()).(*DB)
+}
+
+func (d *RoutingDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if ForcePrimaryFromContext(ctx) {
+		return d.DB.QueryContext(ctx, query, args...)
+	}
+	return d.Reader().QueryContext(ctx, query, args...)
+}
+
+func (d *RoutingDB) Query(e builder.SqlExpr) (*sql.Rows, error) {
+	return d.Reader().Query(e)
+}
+
+func (d *RoutingDB) QueryAndScan(e builder.SqlExpr, v interface{}) error {
+	return d.Reader().QueryAndScan(e, v)
+}
+
+func (d *RoutingDB) Migrate(ctx context.Context, db DBExecutor) error {
+	return d.DB.Migrate(ctx, db)
+}
+
+// LivenessCheck pings replicas and marks unhealthy ones to be skipped until
+// next check passed. statuses are keyed by replica name
+func (d *RoutingDB) LivenessCheck() map[string]string {
+	s := map[string]string{}
+	for _, r := range d.replicas {
+		_, err := r.ExecContext(context.Background(), "SELECT 1")
+		r.SetHealthy(err == nil)
+		if err != nil {
+			s[r.Name] = err.Error()
+		} else {
+			s[r.Name] = "ok"
+		}
+	}
+	return s
+}
+
+// Probe checks liveness of replicas every interval until ctx done, so
+// replicas recovered are routed again and failed ones are skipped
+func (d *RoutingDB) Probe(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.LivenessCheck()
+		}
+	}
+}
+
+func (r *router) pick() *Replica {
+	n := len(r.replicas)
+	if n == 0 {
+		return nil
+	}
+
+	switch r.policy {
+	case LeastConn:
+		var picked *Replica
+		for _, replica := range r.replicas {
+			if !replica.Healthy() {
+				continue
+			}
+			if picked == nil || replica.inUse() < picked.inUse() {
+				picked = replica
+			}
+		}
+		return picked
+	default:
+		start := atomic.AddUint64(r.next, 1)
+		for i := 0; i < n; i++ {
+			replica := r.replicas[(start+uint64(i))%uint64(n)]
+			if replica.Healthy() {
+				return replica
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,98 @@
+package sqlx_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/saitofun/qkit/kit/sqlx"
+	"github.com/saitofun/qkit/kit/sqlx/driver/postgres"
+	"github.com/saitofun/qkit/kit/sqlx/driver/sqlite"
+)
+
+func TestRoutingDB(t *testing.T) {
+	open := func() *sqlx.DB {
+		// unreachable, connections are established lazily
+		return sqlx.NewDatabase("test").OpenDB(&postgres.Connector{
+			Host:  "postgres://127.0.0.1:1",
+			Extra: "sslmode=disable&connect_timeout=1",
+		})
+	}
+
+	primary := open()
+	r1 := &sqlx.Replica{Name: "r1", DB: open()}
+	r2 := &sqlx.Replica{Name: "r2", DB: open()}
+
+	t.Run("RoundRobin", func(t *testing.T) {
+		db := sqlx.NewRoutingDB(primary, sqlx.RoundRobin, r1, r2)
+
+		first := db.Reader().SqlExecutor
+		second := db.Reader().SqlExecutor
+		NewWithT(t).Expect(first).NotTo(Equal(second))
+		NewWithT(t).Expect([]interface{}{first, second}).
+			To(ConsistOf(r1.SqlExecutor, r2.SqlExecutor))
+		NewWithT(t).Expect(db.Reader().SqlExecutor).To(Equal(first))
+	})
+
+	t.Run("LeastConn", func(t *testing.T) {
+		db := sqlx.NewRoutingDB(primary, sqlx.LeastConn, r1, r2)
+		NewWithT(t).Expect(db.Reader().SqlExecutor).To(Equal(r1.SqlExecutor))
+	})
+
+	t.Run("ForcePrimary", func(t *testing.T) {
+		db := sqlx.NewRoutingDB(primary, sqlx.RoundRobin, r1, r2).
+			WithContext(sqlx.ContextWithForcePrimary(context.Background()))
+		NewWithT(t).Expect(db.(*sqlx.RoutingDB).Reader().SqlExecutor).
+			To(Equal(primary.SqlExecutor))
+	})
+
+	t.Run("SkipUnhealthy", func(t *testing.T) {
+		db := sqlx.NewRoutingDB(primary, sqlx.RoundRobin, r1, r2)
+		statuses := db.LivenessCheck()
+		NewWithT(t).Expect(statuses).To(HaveLen(2))
+		NewWithT(t).Expect(statuses["r1"]).NotTo(Equal("ok"))
+		NewWithT(t).Expect(r1.Healthy()).To(BeFalse())
+		NewWithT(t).Expect(db.Reader().SqlExecutor).To(Equal(primary.SqlExecutor))
+	})
+
+	t.Run("SharedHealth", func(t *testing.T) {
+		r := &sqlx.Replica{Name: "r", DB: open()}
+		db := sqlx.NewRoutingDB(primary, sqlx.RoundRobin, r)
+		scoped := db.WithSchema("scoped").(*sqlx.RoutingDB)
+
+		r.SetHealthy(false)
+		NewWithT(t).Expect(scoped.Reader().SqlExecutor).To(Equal(primary.SqlExecutor))
+
+		r.SetHealthy(true)
+		NewWithT(t).Expect(scoped.Reader().SqlExecutor).To(Equal(r.SqlExecutor))
+
+		scoped.LivenessCheck()
+		NewWithT(t).Expect(r.Healthy()).To(BeFalse())
+	})
+
+	t.Run("SharedRoundRobin", func(t *testing.T) {
+		ra, rb := &sqlx.Replica{Name: "ra", DB: open()}, &sqlx.Replica{Name: "rb", DB: open()}
+		db := sqlx.NewRoutingDB(primary, sqlx.RoundRobin, ra, rb)
+		scoped := db.WithSchema("scoped").(*sqlx.RoutingDB)
+
+		NewWithT(t).Expect(db.Reader().SqlExecutor).NotTo(Equal(scoped.Reader().SqlExecutor))
+	})
+
+	t.Run("Probe", func(t *testing.T) {
+		r := &sqlx.Replica{
+			Name: "r",
+			DB:   sqlx.NewDatabase("test_probe").OpenDB(&sqlite.Connector{}),
+		}
+		r.SetHealthy(false)
+		db := sqlx.NewRoutingDB(primary, sqlx.RoundRobin, r)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go db.Probe(ctx, 10*time.Millisecond)
+
+		NewWithT(t).Eventually(r.Healthy).Should(BeTrue())
+		NewWithT(t).Expect(db.Reader().SqlExecutor).To(Equal(r.SqlExecutor))
+	})
+}
@@ -2,6 +2,7 @@ package sqlx_test
 
 import (
 	"context"
+	"database/sql"
 	"database/sql/driver"
 	"fmt"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	. "github.com/onsi/gomega"
 
 	"github.com/saitofun/qkit/base/types"
@@ -17,8 +19,10 @@ import (
 	"github.com/saitofun/qkit/kit/sqlx"
 	"github.com/saitofun/qkit/kit/sqlx/builder"
 	"github.com/saitofun/qkit/kit/sqlx/driver/postgres"
+	"github.com/saitofun/qkit/kit/sqlx/driver/sqlite"
 	"github.com/saitofun/qkit/kit/sqlx/migration"
 	"github.com/saitofun/qkit/testutil/postgrestestutil"
+	"github.com/saitofun/qkit/x/misc/retry"
 )
 
 var connectors map[string]driver.Connector
@@ -31,6 +35,8 @@ func init() {
 	// 	Extra: "charset=utf8mb4&parseTime=true&interpolateParams=true&autocommit=true&loc=Local",
 	// }
 
+	connectors["sqlite"] = &sqlite.Connector{}
+
 	// postgres
 	{
 		ep := postgrestestutil.Endpoint
@@ -45,6 +51,13 @@ func init() {
 	}
 }
 
+// requirePostgres skips case relies on features of postgres
+func requirePostgres(t *testing.T, name string) {
+	if name != "postgres" {
+		t.Skipf("not supported by %s", name)
+	}
+}
+
 func Background() context.Context {
 	return log.WithLogger(context.Background(), log.Std())
 }
@@ -158,7 +171,12 @@ func TestMigrate(t *testing.T) {
 
 	for name, connector := range connectors {
 		t.Run(name, func(t *testing.T) {
-			for _, schema := range []string{"import", "public", "backup"} {
+			schemas := []string{"import", "public", "backup"}
+			if name != "postgres" {
+				// schemas are supported by postgres only
+				schemas = []string{""}
+			}
+			for _, schema := range schemas {
 				dbTest.Tables.Range(func(table *builder.Table, idx int) {
 					db := dbTest.OpenDB(connector).WithSchema(schema)
 					_, _ = db.Exec(db.Dialect().DropTable(table))
@@ -218,6 +236,69 @@ func TestMigrate(t *testing.T) {
 	}
 }
 
+func TestMigrateVersioned(t *testing.T) {
+	dbTest := sqlx.NewDatabase("test_for_migrate_versioned")
+
+	for name, connector := range connectors {
+		t.Run(name, func(t *testing.T) {
+			db := dbTest.OpenDB(connector)
+			userTable := dbTest.Register(&User{})
+			defer func() {
+				_, _ = db.Exec(db.Dialect().DropTable(userTable))
+				_, _ = db.Exec(db.Dialect().DropTable(
+					builder.TableFromModel(&migration.SqlMigrationHistory{}),
+				))
+			}()
+
+			migrations := []*migration.Migration{
+				migration.NewMigration(1, "backfill",
+					func(db sqlx.DBExecutor) error {
+						_, err := db.Exec(builder.Update(userTable).Set(
+							userTable.ColByFieldName("Nickname").ValueBy("backfill"),
+						).Where(userTable.ColByFieldName("Nickname").Eq("")))
+						return err
+					},
+					nil,
+				),
+				migration.NewSqlMigration(2, "noop", "SELECT 1;", "SELECT 2;"),
+			}
+
+			err := migration.Migrate(db, nil, migrations...)
+			NewWithT(t).Expect(err).To(BeNil())
+
+			list, err := migration.Status(db, migrations...)
+			NewWithT(t).Expect(err).To(BeNil())
+			NewWithT(t).Expect(list).To(HaveLen(2))
+			NewWithT(t).Expect(list[0].Applied).To(BeTrue())
+			NewWithT(t).Expect(list[1].Applied).To(BeTrue())
+
+			t.Run("Down", func(t *testing.T) {
+				err := migration.Down(db, 1, migrations...)
+				NewWithT(t).Expect(err).To(BeNil())
+
+				list, err := migration.Status(db, migrations...)
+				NewWithT(t).Expect(err).To(BeNil())
+				NewWithT(t).Expect(list[1].Applied).To(BeFalse())
+
+				err = migration.Down(db, 1, migrations...)
+				NewWithT(t).Expect(err).NotTo(BeNil())
+			})
+
+			t.Run("ChecksumMismatch", func(t *testing.T) {
+				err := migration.Up(db, nil, migrations...)
+				NewWithT(t).Expect(err).To(BeNil())
+
+				changed := []*migration.Migration{
+					migrations[0],
+					migration.NewSqlMigration(2, "noop", "SELECT 3;", ""),
+				}
+				err = migration.Up(db, nil, changed...)
+				NewWithT(t).Expect(err).NotTo(BeNil())
+			})
+		})
+	}
+}
+
 func TestCRUD(t *testing.T) {
 	dbTest := sqlx.NewDatabase("test_crud")
 
@@ -237,6 +318,12 @@ func TestCRUD(t *testing.T) {
 				}
 
 				t.Run("Canceled", func(t *testing.T) {
+					// database/sql discards connection of transaction canceled
+					// and rolls it back asynchronously, sqlite in memory
+					// database is dropped with its last connection, or its
+					// tables are locked until rolled back in shared cache
+					requirePostgres(t, name)
+
 					ctx, cancel := context.WithCancel(Background())
 					db2 := db.WithContext(ctx)
 
@@ -303,6 +390,115 @@ func TestCRUD(t *testing.T) {
 	}
 }
 
+func TestNestedTasks(t *testing.T) {
+	dbTest := sqlx.NewDatabase("test_nested_tasks")
+
+	for name, connector := range connectors {
+		t.Run(name, func(t *testing.T) {
+			db := dbTest.OpenDB(connector)
+			userTable := dbTest.Register(&User{})
+			NewWithT(t).Expect(migration.Migrate(db, nil)).To(BeNil())
+
+			insert := func(name string) sqlx.Task {
+				return func(db sqlx.DBExecutor) error {
+					_, err := db.Exec(sqlx.InsertToDB(db, &User{Name: name}, nil))
+					return err
+				}
+			}
+
+			count := func(names ...string) int {
+				cnt := 0
+				err := db.QueryAndScan(
+					builder.Select(builder.Count()).From(
+						userTable,
+						builder.Where(userTable.ColByFieldName("Name").In(names)),
+					),
+					&cnt,
+				)
+				NewWithT(t).Expect(err).To(BeNil())
+				return cnt
+			}
+
+			outer, inner, failed := uuid.New().String(), uuid.New().String(), uuid.New().String()
+			published := make([]string, 0)
+
+			err := sqlx.NewTasks(db).With(
+				insert(outer),
+				func(db sqlx.DBExecutor) error {
+					sqlx.AfterCommit(db, func() { published = append(published, outer) })
+					return nil
+				},
+				func(db sqlx.DBExecutor) error {
+					// failed inner tasks rolled back to savepoint only
+					err := sqlx.NewTasks(db).With(
+						insert(failed),
+						func(db sqlx.DBExecutor) error {
+							sqlx.AfterCommit(db, func() { published = append(published, failed) })
+							return nil
+						},
+						insert(failed),
+					).Do()
+					NewWithT(t).Expect(err).NotTo(BeNil())
+					NewWithT(t).Expect(published).To(BeEmpty())
+
+					return sqlx.NewTasks(db).With(insert(inner)).Do()
+				},
+			).Do()
+
+			NewWithT(t).Expect(err).To(BeNil())
+			NewWithT(t).Expect(count(outer, inner)).To(Equal(2))
+			NewWithT(t).Expect(count(failed)).To(Equal(0))
+			NewWithT(t).Expect(published).To(Equal([]string{outer}))
+
+			db.Tables.Range(func(table *builder.Table, idx int) {
+				_, err := db.Exec(db.Dialect().DropTable(table))
+				NewWithT(t).Expect(err).To(BeNil())
+			})
+		})
+	}
+}
+
+func TestTasksRetry(t *testing.T) {
+	dbTest := sqlx.NewDatabase("test_tasks_retry")
+
+	for name, connector := range connectors {
+		t.Run(name, func(t *testing.T) {
+			// errors retried are classified by postgres error codes
+			requirePostgres(t, name)
+
+			db := dbTest.OpenDB(connector)
+
+			attempts := 0
+			err := sqlx.NewTasks(db).
+				WithTxOptions(&sql.TxOptions{Isolation: sql.LevelSerializable}).
+				WithRetry(&retry.Retry{Repeats: 3, Interval: types.Duration(time.Millisecond), Multiplier: 2}).
+				With(func(db sqlx.DBExecutor) error {
+					attempts++
+					if attempts < 3 {
+						return &pq.Error{Code: "40001"}
+					}
+					return nil
+				}).
+				Do()
+
+			NewWithT(t).Expect(err).To(BeNil())
+			NewWithT(t).Expect(attempts).To(Equal(3))
+
+			attempts = 0
+			err = sqlx.NewTasks(db).
+				WithRetry(&retry.Retry{Repeats: 3, Interval: types.Duration(time.Millisecond)}).
+				With(func(db sqlx.DBExecutor) error {
+					attempts++
+					return &pq.Error{Code: "23505"}
+				}).
+				Do()
+
+			NewWithT(t).Expect(err).NotTo(BeNil())
+			NewWithT(t).Expect(attempts).To(Equal(1))
+		})
+	}
+}
+
 type UserSet map[string]*User
 
 func (UserSet) New() interface{} {
@@ -0,0 +1,106 @@
+package datatypes
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	"github.com/saitofun/qkit/kit/sqlx/builder"
+)
+
+const (
+	CursorDirectionNext = "next"
+	CursorDirectionPrev = "prev"
+)
+
+// Cursor keyset pagination. Cursor is opaque and encoded by key values of the
+// last row (for next page) or the first row (for prev page) of current page,
+// the first page is listed if Cursor is empty
+type Cursor struct {
+	Cursor    string `name:"cursor,omitempty"    in:"query"`
+	Size      int64  `name:"size,omitempty"      in:"query" default:"10"   validate:"@int64[1,]"`
+	Direction string `name:"direction,omitempty" in:"query" default:"next" validate:"@string{next,prev}"`
+}
+
+// DefaultCursorSize page size of Cursor if Size is not set
+const DefaultCursorSize = 10
+
+// WithDefault returns copy of c with Size and Direction defaulted, the first
+// page is listed if c is nil
+func (c *Cursor) WithDefault() *Cursor {
+	cur := Cursor{}
+	if c != nil {
+		cur = *c
+	}
+	if cur.Size <= 0 {
+		cur.Size = DefaultCursorSize
+	}
+	if cur.Direction == "" {
+		cur.Direction = CursorDirectionNext
+	}
+	return &cur
+}
+
+// IsPrev if listing rows before cursor
+func (c *Cursor) IsPrev() bool { return c.Direction == CursorDirectionPrev }
+
+// Addition limits Size+1 rows to detect if more rows after the page
+func (c *Cursor) Addition() builder.Addition {
+	return builder.Limit(c.Size + 1)
+}
+
+// Decode unmarshal key values of cursor to dst
+func (c *Cursor) Decode(dst ...interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(c.Cursor)
+	if err != nil {
+		return errors.Wrap(err, "invalid cursor")
+	}
+	values := make([]json.RawMessage, 0, len(dst))
+	if err = json.Unmarshal(raw, &values); err != nil {
+		return errors.Wrap(err, "invalid cursor")
+	}
+	if len(values) != len(dst) {
+		return errors.Errorf("invalid cursor: expect %d values, but got %d", len(dst), len(values))
+	}
+	for i := range dst {
+		d := json.NewDecoder(bytes.NewReader(values[i]))
+		d.UseNumber()
+		if err = d.Decode(dst[i]); err != nil {
+			return errors.Wrap(err, "invalid cursor")
+		}
+	}
+	return nil
+}
+
+// EncodeCursor encodes key values of row to opaque cursor
+func EncodeCursor(values ...interface{}) (string, error) {
+	raw, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// Cursors of pages around listed page, empty if no more rows
+type Cursors struct {
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// Page trims rows, which fetched by limit Size+1, to page size and returns
+// cursors should be set. rows should be reversed by caller if IsPrev.
+func (c *Cursor) Page(n int) (size int, next, prev bool) {
+	size, more := n, false
+	if int64(n) > c.Size {
+		size, more = int(c.Size), true
+	}
+	if size == 0 {
+		return
+	}
+	if c.IsPrev() {
+		return size, c.Cursor != "", more
+	}
+	return size, more, c.Cursor != ""
+}
@@ -9,6 +9,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/lib/pq"
 
@@ -20,7 +21,10 @@ import (
 
 var _ interface {
 	driver.Connector
+	io.Closer
 	builder.Dialect
+	builder.ForeignKeyDialect
+	builder.ConstraintDialect
 } = (*Connector)(nil)
 
 type Connector struct {
@@ -28,6 +32,10 @@ type Connector struct {
 	DBName     string
 	Extra      string
 	Extensions []string
+	// SlowQueryThreshold see Driver.SlowQueryThreshold
+	SlowQueryThreshold time.Duration
+	// ExplainSlowQuery see Driver.ExplainSlowQuery
+	ExplainSlowQuery bool
 }
 
 func (c *Connector) Connect(ctx context.Context) (driver.Conn, error) {
@@ -70,7 +78,23 @@ func (c *Connector) Connect(ctx context.Context) (driver.Conn, error) {
 	return conn, nil
 }
 
-func (Connector) Driver() driver.Driver { return &Driver{} }
+func (c Connector) Driver() driver.Driver {
+	return &Driver{
+		SlowQueryThreshold: c.SlowQueryThreshold,
+		ExplainSlowQuery:   c.ExplainSlowQuery,
+	}
+}
+
+// Close stops explaining slow queries of database connected, it is called by
+// sql.DB.Close
+func (c *Connector) Close() error {
+	cfg, err := pq.ParseURL(c.dsn(c.DBName))
+	if err != nil {
+		// never connected
+		return nil
+	}
+	return explains.Close(cfg)
+}
 
 func (c Connector) dsn(name string) string {
 	extra := ""
@@ -84,11 +108,16 @@ func (c Connector) WithDBName(name string) driver.Connector { c.DBName = name; r
 
 func (c *Connector) Migrate(ctx context.Context, db sqlx.DBExecutor) error {
 	output := migration.OutputFromContext(ctx)
+	plan := migration.PlanFromContext(ctx)
 
 	prevDB, err := databaseFromSchema(db)
 	if err != nil {
 		return err
 	}
+	prevFKs, err := foreignKeysFromSchema(db)
+	if err != nil {
+		return err
+	}
 
 	d := db.D()
 	dialect := db.Dialect()
@@ -124,29 +153,45 @@ func (c *Connector) Migrate(ctx context.Context, db sqlx.DBExecutor) error {
 		prevDB = prevDB.WithSchema(d.Schema)
 	}
 
-	for _, name := range d.Tables.TableNames() {
-		table := d.Table(name)
+	// foreign keys are added after all tables migrated, so tables in cycle of
+	// relations could reference each other
+	var fkSteps []*builder.MigrationStep
 
-		prevTable := prevDB.Table(name)
+	for _, name := range d.Tables.SortedTableNames() {
+		table := d.Table(name)
 
-		if prevTable == nil {
-			for _, expr := range dialect.CreateTableIsNotExists(table) {
-				if err := exec(expr); err != nil {
-					return err
-				}
-			}
-			continue
+		adds, drops, err := builder.ForeignKeySteps(table, d.Tables.ForeignKeysOf(table), prevFKs[name], dialect)
+		if err != nil {
+			return err
+		}
+		steps := drops
+		if prevTable := prevDB.Table(name); prevTable == nil {
+			steps = append(steps, builder.CreateTableSteps(table, dialect)...)
+		} else {
+			steps = append(steps, table.DiffSteps(prevTable, dialect)...)
 		}
+		fkSteps = append(fkSteps, adds...)
 
-		exprList := table.Diff(prevTable, dialect)
+		if plan != nil {
+			plan.Add(steps...)
+		}
 
-		for _, expr := range exprList {
-			if err := exec(expr); err != nil {
+		for _, step := range steps {
+			if err := exec(step.Expr); err != nil {
 				return err
 			}
 		}
 	}
 
+	if plan != nil {
+		plan.Add(fkSteps...)
+	}
+	for _, step := range fkSteps {
+		if err := exec(step.Expr); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -168,6 +213,14 @@ func (Connector) IsErrorConflict(err error) bool {
 	return false
 }
 
+func (Connector) IsErrorRetryable(err error) bool {
+	if e, ok := sqlx.UnwrapAll(err).(*pq.Error); ok {
+		// serialization_failure, deadlock_detected
+		return e.Code == "40001" || e.Code == "40P01"
+	}
+	return false
+}
+
 func (c *Connector) CreateDatabase(dbName string) builder.SqlExpr {
 	e := builder.Expr("CREATE DATABASE ")
 	e.WriteQuery(dbName)
@@ -248,6 +301,57 @@ func (c *Connector) DropIndex(key *builder.Key) builder.SqlExpr {
 	return e
 }
 
+func (c *Connector) AddForeignKey(fk *builder.ForeignKey) builder.SqlExpr {
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(fk.Col.Table)
+	e.WriteQuery(" ADD CONSTRAINT ")
+	e.WriteQuery(fk.Name)
+	e.WriteQueryByte(' ')
+	e.WriteExpr(fk)
+	e.WriteEnd()
+	return e
+}
+
+func (c *Connector) DropForeignKey(fk *builder.ForeignKey) builder.SqlExpr {
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(fk.Col.Table)
+	e.WriteQuery(" DROP CONSTRAINT IF EXISTS ")
+	e.WriteQuery(fk.Name)
+	e.WriteEnd()
+	return e
+}
+
+func (c *Connector) AddConstraint(constraint *builder.Constraint) builder.SqlExpr {
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(constraint.Table)
+	e.WriteQuery(" ADD ")
+	e.WriteExpr(c.constraint(constraint))
+	e.WriteEnd()
+	return e
+}
+
+func (c *Connector) DropConstraint(constraint *builder.Constraint) builder.SqlExpr {
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(constraint.Table)
+	e.WriteQuery(" DROP CONSTRAINT IF EXISTS ")
+	e.WriteQuery(constraint.Table.Name)
+	e.WriteQueryByte('_')
+	e.WriteQuery(constraint.Name)
+	e.WriteEnd()
+	return e
+}
+
+// constraint writes named constraint, eg: CONSTRAINT t_f_age_check CHECK (f_age >= 0)
+func (c *Connector) constraint(constraint *builder.Constraint) builder.SqlExpr {
+	e := builder.Expr("CONSTRAINT ")
+	e.WriteQuery(constraint.Table.Name)
+	e.WriteQueryByte('_')
+	e.WriteQuery(constraint.Name)
+	e.WriteQueryByte(' ')
+	e.WriteExpr(constraint)
+	return e
+}
+
 func (c *Connector) CreateTableIsNotExists(t *builder.Table) (exprs []builder.SqlExpr) {
 	expr := builder.Expr("CREATE TABLE IF NOT EXISTS ")
 	expr.WriteExpr(t)
@@ -283,9 +387,21 @@ func (c *Connector) CreateTableIsNotExists(t *builder.Table) (exprs []builder.Sq
 			}
 		})
 
+		t.Constraints.Range(func(constraint *builder.Constraint, idx int) {
+			e.WriteQueryByte(',')
+			e.WriteQueryByte('\n')
+			e.WriteQueryByte('\t')
+			e.WriteExpr(c.constraint(constraint))
+		})
+
 		expr.WriteQueryByte('\n')
 	})
 
+	if !t.Partition.IsNil() {
+		expr.WriteQueryByte(' ')
+		expr.WriteExpr(t.Partition.TableExpr(t))
+	}
+
 	expr.WriteEnd()
 	exprs = append(exprs, expr)
 
@@ -305,6 +421,36 @@ func (c *Connector) DropTable(t *builder.Table) builder.SqlExpr {
 	return e
 }
 
+// CreatePartition creates partition named name of partitioned table t for
+// values of bound, eg: FROM ('0') TO ('86400'), IN ('a', 'b')
+func (c *Connector) CreatePartition(t *builder.Table, name string, bound string) builder.SqlExpr {
+	e := builder.Expr("CREATE TABLE IF NOT EXISTS ")
+	e.WriteExpr(partitionOf(t, name))
+	e.WriteQuery(" PARTITION OF ")
+	e.WriteExpr(t)
+	e.WriteQuery(" FOR VALUES ")
+	e.WriteQuery(bound)
+	e.WriteEnd()
+	return e
+}
+
+// DetachPartition detaches partition named name from partitioned table t, the
+// partition is kept as a standalone table
+func (c *Connector) DetachPartition(t *builder.Table, name string) builder.SqlExpr {
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(t)
+	e.WriteQuery(" DETACH PARTITION ")
+	e.WriteExpr(partitionOf(t, name))
+	e.WriteEnd()
+	return e
+}
+
+func partitionOf(t *builder.Table, name string) *builder.Table {
+	p := builder.T(name)
+	p.Schema = t.Schema
+	return p
+}
+
 func (c *Connector) TruncateTable(t *builder.Table) builder.SqlExpr {
 	e := builder.Expr("TRUNCATE TABLE ")
 	e.WriteExpr(t)
@@ -384,7 +530,8 @@ func (c *Connector) ModifyColumn(col *builder.Column, prev *builder.Column) buil
 	defaultValue := normalizeDefaultValue(col.Default, dbDataType)
 	prevDefaultValue := normalizeDefaultValue(prev.Default, prevDbDataType)
 
-	if defaultValue != prevDefaultValue {
+	// generated column has no default value
+	if defaultValue != prevDefaultValue && col.Generated == "" {
 		prepareAppendSubCmd()
 
 		e.WriteQuery(" ALTER COLUMN ")
@@ -410,6 +557,27 @@ func (c *Connector) ModifyColumn(col *builder.Column, prev *builder.Column) buil
 	return e
 }
 
+func (c *Connector) Savepoint(name string) builder.SqlExpr {
+	e := builder.Expr("SAVEPOINT ")
+	e.WriteQuery(name)
+	e.WriteEnd()
+	return e
+}
+
+func (c *Connector) RollbackToSavepoint(name string) builder.SqlExpr {
+	e := builder.Expr("ROLLBACK TO SAVEPOINT ")
+	e.WriteQuery(name)
+	e.WriteEnd()
+	return e
+}
+
+func (c *Connector) ReleaseSavepoint(name string) builder.SqlExpr {
+	e := builder.Expr("RELEASE SAVEPOINT ")
+	e.WriteQuery(name)
+	e.WriteEnd()
+	return e
+}
+
 func (c *Connector) DropColumn(col *builder.Column) builder.SqlExpr {
 	e := builder.Expr("ALTER TABLE ")
 	e.WriteExpr(col.Table)
@@ -506,6 +674,14 @@ func (c *Connector) dataTypeModify(columnType *builder.ColumnType, dataType stri
 		buf.WriteString(" NOT NULL")
 	}
 
+	if columnType.Generated != "" {
+		// generated column could not have default value
+		buf.WriteString(" GENERATED ALWAYS AS (")
+		buf.WriteString(columnType.Generated)
+		buf.WriteString(") STORED")
+		return buf.String()
+	}
+
 	if columnType.Default != nil {
 		buf.WriteString(" DEFAULT ")
 		buf.WriteString(normalizeDefaultValue(columnType.Default, dataType))
@@ -3,11 +3,15 @@ package postgres_test
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/lib/pq"
 	"github.com/onsi/gomega"
+	"github.com/pkg/errors"
 
 	"github.com/saitofun/qkit/kit/sqlx/builder"
 	"github.com/saitofun/qkit/kit/sqlx/driver/postgres"
+	"github.com/saitofun/qkit/kit/sqlx/migration"
 	"github.com/saitofun/qkit/testutil/buildertestutil"
 )
 
@@ -86,6 +90,18 @@ func TestConnector(t *testing.T) {
 			c.DropColumn(table.Col("F_name")),
 			builder.Expr( /* language=PostgreSQL */ "ALTER TABLE t DROP COLUMN f_name;"),
 		},
+		"Savepoint": {
+			c.Savepoint("sp_1"),
+			builder.Expr( /* language=PostgreSQL */ "SAVEPOINT sp_1;"),
+		},
+		"RollbackToSavepoint": {
+			c.RollbackToSavepoint("sp_1"),
+			builder.Expr( /* language=PostgreSQL */ "ROLLBACK TO SAVEPOINT sp_1;"),
+		},
+		"ReleaseSavepoint": {
+			c.ReleaseSavepoint("sp_1"),
+			builder.Expr( /* language=PostgreSQL */ "RELEASE SAVEPOINT sp_1;"),
+		},
 	}
 
 	for name, c := range cases {
@@ -94,4 +110,247 @@ func TestConnector(t *testing.T) {
 				To(buildertestutil.BeExpr(c.expr.Ex(context.Background()).Query()))
 		})
 	}
+
+	t.Run("Notify", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(postgres.Notify("events", "payload")).
+			To(buildertestutil.BeExpr( /* language=PostgreSQL */ "SELECT pg_notify(?, ?);", "events", "payload"))
+	})
+}
+
+func TestConnector_DiffSteps(t *testing.T) {
+	c := &postgres.Connector{}
+
+	prev := builder.T("t",
+		builder.Col("f_id").Type(uint64(0), ",autoincrement"),
+		builder.Col("f_name").Type("", ",size=128,default=''"),
+		builder.Col("f_desc").Type("", ",null"),
+		builder.Col("f_removed").Type("", ",default=''"),
+		// primary key loaded from schema is named by dialect
+		builder.UniqueIndex(c.PrimaryKeyName(), builder.Cols("f_id")),
+		builder.Index("i_name", builder.Cols("f_name")),
+	)
+
+	curr := builder.T("t",
+		builder.Col("f_id").Type(uint64(0), ",autoincrement"),
+		builder.Col("f_name").Type(int64(0), ",default='0'"),
+		builder.Col("f_desc").Type("", ""),
+		builder.Col("f_removed").Type("", ",deprecated"),
+		builder.Col("f_created_at").Type(int64(0), ",default='0'"),
+		builder.PrimaryKey(builder.Cols("f_id")),
+		builder.Index("i_created_at", builder.Cols("f_created_at")),
+	)
+
+	steps := curr.DiffSteps(prev, c)
+
+	subjects := make([]string, 0, len(steps))
+	for _, s := range steps {
+		subjects = append(subjects, s.String())
+	}
+
+	gomega.NewWithT(t).Expect(subjects).To(gomega.Equal([]string{
+		"modify column t.f_name [destructive] [lock-heavy]",
+		"modify column t.f_desc [lock-heavy]",
+		"drop column t.f_removed [destructive]",
+		"add column t.f_created_at",
+		"add index t.i_created_at [lock-heavy]",
+		"drop index t.i_name",
+	}))
+
+	plan := &migration.MigrationPlan{Steps: steps}
+	gomega.NewWithT(t).Expect(plan.Check()).
+		To(gomega.MatchError(gomega.ContainSubstring("drop column t.f_removed")))
+	gomega.NewWithT(t).Expect(plan.Check("t.f_name")).NotTo(gomega.BeNil())
+	gomega.NewWithT(t).Expect(plan.Check("t.f_name", "t.f_removed")).To(gomega.BeNil())
+	gomega.NewWithT(t).Expect(plan.Check("t")).To(gomega.BeNil())
+}
+
+func TestConnector_ForeignKeys(t *testing.T) {
+	c := &postgres.Connector{}
+
+	org := builder.T("t_org",
+		builder.Col("f_id").Field("ID").Type(uint64(0), ",autoincrement"),
+	)
+	org.ModelName = "Org"
+
+	user := builder.T("t_user",
+		builder.Col("f_id").Field("ID").Type(uint64(0), ",autoincrement"),
+		builder.Col("f_org_id").Field("OrgID").Type(uint64(0), ",rel_delete=cascade"),
+		builder.Col("f_owner_org_id").Field("OwnerOrgID").Type(uint64(0), ""),
+		builder.Col("f_manual_id").Field("ManualID").Type(uint64(0), ""),
+	)
+	user.ColByFieldName("OrgID").Rel = []string{"Org", "ID"}
+	// relation only, foreign key not enabled
+	user.ColByFieldName("OwnerOrgID").Rel = []string{"Org", "ID"}
+
+	tables := builder.Tables{}
+	tables.Add(user, org)
+
+	gomega.NewWithT(t).Expect(tables.SortedTableNames()).To(gomega.Equal([]string{"t_org", "t_user"}))
+
+	fks := tables.ForeignKeysOf(user)
+	gomega.NewWithT(t).Expect(fks).To(gomega.HaveLen(1))
+	gomega.NewWithT(t).Expect(c.AddForeignKey(fks[0])).To(buildertestutil.BeExpr( /* language=PostgreSQL */
+		"ALTER TABLE t_user ADD CONSTRAINT t_user_f_org_id_fkey FOREIGN KEY (f_org_id) REFERENCES t_org (f_id) ON DELETE CASCADE;",
+	))
+	gomega.NewWithT(t).Expect(c.DropForeignKey(fks[0])).To(buildertestutil.BeExpr( /* language=PostgreSQL */
+		"ALTER TABLE t_user DROP CONSTRAINT IF EXISTS t_user_f_org_id_fkey;",
+	))
+
+	prev := []*builder.ForeignKey{
+		// action changed
+		{Name: "t_user_f_org_id_fkey", Col: user.Col("f_org_id"), Ref: org.Col("f_id")},
+		// foreign key disabled
+		{Name: "t_user_f_owner_org_id_fkey", Col: user.Col("f_owner_org_id"), Ref: org.Col("f_id")},
+		// not declared by Rel, kept
+		{Name: "t_user_f_manual_id_fkey", Col: user.Col("f_manual_id"), Ref: org.Col("f_id")},
+	}
+
+	adds, drops, err := builder.ForeignKeySteps(user, fks, prev, c)
+	gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+
+	subjects := make([]string, 0, len(adds)+len(drops))
+	for _, s := range append(drops, adds...) {
+		subjects = append(subjects, s.String())
+	}
+	gomega.NewWithT(t).Expect(subjects).To(gomega.Equal([]string{
+		"drop foreign key t_user.t_user_f_org_id_fkey",
+		"drop foreign key t_user.t_user_f_owner_org_id_fkey [destructive]",
+		"add foreign key t_user.t_user_f_org_id_fkey [lock-heavy]",
+	}))
+
+	adds, drops, err = builder.ForeignKeySteps(user, fks, fks, c)
+	gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+	gomega.NewWithT(t).Expect(append(adds, drops...)).To(gomega.BeEmpty())
+
+	t.Run("TypeMismatch", func(t *testing.T) {
+		member := builder.T("t_member",
+			builder.Col("f_org_id").Field("OrgID").Type("", ",fk"),
+		)
+		member.ColByFieldName("OrgID").Rel = []string{"Org", "ID"}
+		tables.Add(member)
+
+		_, _, err := builder.ForeignKeySteps(member, tables.ForeignKeysOf(member), nil, c)
+		gomega.NewWithT(t).Expect(err).To(gomega.MatchError(gomega.ContainSubstring("t_member.f_org_id")))
+	})
+}
+
+func TestConnector_Constraints(t *testing.T) {
+	c := &postgres.Connector{}
+
+	table := builder.T("t_booking",
+		builder.Col("f_room_id").Field("RoomID").Type(uint64(0), ""),
+		builder.Col("f_during").Field("During").Type("", ",size=64"),
+		builder.Col("f_price").Field("Price").Type(float64(0), ",check=f_price >= 0"),
+		builder.Col("f_quantity").Field("Quantity").Type(float64(0), ""),
+		builder.Col("f_total").Field("Total").Type(float64(0), ",generated=f_price * f_quantity"),
+		builder.Check("price", "#Price <= #Total OR #Quantity < 1"),
+		builder.Exclude("room_during", "USING gist (#RoomID WITH =, #During WITH &&)"),
+	)
+
+	gomega.NewWithT(t).Expect(c.CreateTableIsNotExists(table)[0]).To(buildertestutil.BeExpr( /* language=PostgreSQL */ `CREATE TABLE IF NOT EXISTS t_booking (
+	f_room_id bigint NOT NULL,
+	f_during character varying(64) NOT NULL,
+	f_price double precision NOT NULL,
+	f_quantity double precision NOT NULL,
+	f_total double precision NOT NULL GENERATED ALWAYS AS (f_price * f_quantity) STORED,
+	CONSTRAINT t_booking_price CHECK (f_price <= f_total OR f_quantity < 1),
+	CONSTRAINT t_booking_room_during EXCLUDE USING gist (f_room_id WITH =, f_during WITH &&)
+);`))
+
+	gomega.NewWithT(t).Expect(c.AddConstraint(table.Constraints.Constraint("room_during"))).To(buildertestutil.BeExpr( /* language=PostgreSQL */
+		"ALTER TABLE t_booking ADD CONSTRAINT t_booking_room_during EXCLUDE USING gist (f_room_id WITH =, f_during WITH &&);",
+	))
+	gomega.NewWithT(t).Expect(c.DropConstraint(table.Constraints.Constraint("price"))).To(buildertestutil.BeExpr( /* language=PostgreSQL */
+		"ALTER TABLE t_booking DROP CONSTRAINT IF EXISTS t_booking_price;",
+	))
+
+	// as loaded from schema
+	prev := builder.T("t_booking",
+		builder.Col("f_room_id").Type(uint64(0), ""),
+		builder.Col("f_during").Type("", ",size=64"),
+		builder.Col("f_price").Type(float64(0), ""),
+		builder.Col("f_quantity").Type(float64(0), ""),
+		builder.Col("f_total").Type(float64(0), ",generated=(f_price + f_quantity)"),
+		builder.Check("price", "(((f_price <= f_total) OR (f_quantity < (1)::double precision)))"),
+		builder.Check("removed", "((f_price > (0)::double precision))"),
+		builder.Exclude("room_during", "USING gist (f_room_id WITH =, f_during WITH &&)"),
+	)
+
+	steps := table.DiffSteps(prev, c)
+
+	subjects := make([]string, 0, len(steps))
+	for _, s := range steps {
+		subjects = append(subjects, s.String())
+	}
+	gomega.NewWithT(t).Expect(subjects).To(gomega.Equal([]string{
+		"drop column t_booking.f_total [destructive]",
+		"add column t_booking.f_total [lock-heavy]",
+		"drop constraint t_booking.removed",
+	}))
+}
+
+type Telemetry struct{}
+
+func (Telemetry) TableName() string { return "t_telemetry" }
+
+func TestConnector_Partition(t *testing.T) {
+	c := &postgres.Connector{}
+
+	table := builder.T("t_telemetry",
+		builder.Col("f_device_id").Field("DeviceID").Type("", ",size=64"),
+		builder.Col("f_created_at").Field("CreatedAt").Type(int64(0), ""),
+		builder.PrimaryKey(builder.Cols("f_device_id", "f_created_at")),
+	)
+	table.Partition = &builder.Partition{Method: builder.PartitionByRange, Key: "#CreatedAt"}
+
+	gomega.NewWithT(t).Expect(c.CreateTableIsNotExists(table)[0]).To(buildertestutil.BeExpr( /* language=PostgreSQL */ `CREATE TABLE IF NOT EXISTS t_telemetry (
+	f_device_id character varying(64) NOT NULL,
+	f_created_at bigint NOT NULL,
+	PRIMARY KEY (f_device_id,f_created_at)
+) PARTITION BY RANGE (f_created_at);`))
+	gomega.NewWithT(t).Expect(table.Partition.KeyCol(table)).To(gomega.Equal(table.Col("f_created_at")))
+
+	gomega.NewWithT(t).Expect(c.CreatePartition(table, "t_telemetry_p20261018", "FROM ('1760745600') TO ('1760832000')")).
+		To(buildertestutil.BeExpr( /* language=PostgreSQL */
+			"CREATE TABLE IF NOT EXISTS t_telemetry_p20261018 PARTITION OF t_telemetry FOR VALUES FROM ('1760745600') TO ('1760832000');",
+		))
+	gomega.NewWithT(t).Expect(c.DetachPartition(table, "t_telemetry_p20261018")).
+		To(buildertestutil.BeExpr( /* language=PostgreSQL */
+			"ALTER TABLE t_telemetry DETACH PARTITION t_telemetry_p20261018;",
+		))
+
+	t.Run("PartitionManager", func(t *testing.T) {
+		pm := postgres.NewPartitionManager(nil, &Telemetry{},
+			postgres.WithPartitionPremake(1),
+			postgres.WithPartitionRetention(48*time.Hour),
+		)
+		now := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+		day := 24 * time.Hour
+
+		gomega.NewWithT(t).Expect(pm.Partitions(now)).To(gomega.Equal([]postgres.RangePartition{
+			{Name: "t_telemetry_p20261018", From: now.Truncate(day), To: now.Truncate(day).Add(day)},
+			{Name: "t_telemetry_p20261019", From: now.Truncate(day).Add(day), To: now.Truncate(day).Add(2 * day)},
+		}))
+		gomega.NewWithT(t).Expect(pm.Expired(now, []string{
+			"t_telemetry_p20261014",
+			"t_telemetry_p20261015",
+			"t_telemetry_p20261016",
+			"t_telemetry_p20261017",
+			"t_telemetry_archive",
+		})).To(gomega.Equal([]string{"t_telemetry_p20261014", "t_telemetry_p20261015"}))
+	})
+}
+
+func TestConnector_IsErrorRetryable(t *testing.T) {
+	c := &postgres.Connector{}
+
+	for code, retryable := range map[pq.ErrorCode]bool{
+		"40001": true,
+		"40P01": true,
+		"23505": false,
+	} {
+		err := errors.Wrap(&pq.Error{Code: code}, "tx")
+		gomega.NewWithT(t).Expect(c.IsErrorRetryable(err)).To(gomega.Equal(retryable))
+	}
+	gomega.NewWithT(t).Expect(c.IsErrorRetryable(errors.New("any"))).To(gomega.BeFalse())
 }
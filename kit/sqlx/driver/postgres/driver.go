@@ -6,6 +6,7 @@ import (
 	"database/sql/driver"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/lib/pq"
 	"github.com/pkg/errors"
@@ -17,6 +18,13 @@ import (
 
 type Driver struct {
 	drv pq.Driver
+	// SlowQueryThreshold statements cost longer are logged as slow queries,
+	// disabled if zero
+	SlowQueryThreshold time.Duration
+	// ExplainSlowQuery if plans of slow queries are logged. queries are
+	// explained in background one by one by a pooled connection, and skipped
+	// if too many queued
+	ExplainSlowQuery bool
 }
 
 func (d *Driver) Open(dsn string) (driver.Conn, error) {
@@ -32,12 +40,15 @@ func (d *Driver) Open(dsn string) (driver.Conn, error) {
 	if err != nil {
 		return nil, errors.Wrapf(err, "Driver.Open")
 	}
-	return &LoggingConn{opts, conn}, nil
+	return &LoggingConn{opts: opts, Conn: conn, drv: d, cfg: cfg}, nil
 }
 
 type LoggingConn struct {
 	opts Opts
 	driver.Conn
+
+	drv *Driver
+	cfg string
 }
 
 var _ interface {
@@ -75,11 +86,12 @@ func (c *LoggingConn) QueryContext(ctx context.Context, query string, args []dri
 		} else {
 			l.WithValues("cost", cost().String()).Debug("%s", qs)
 		}
+		c.observe(ctx, "query", query, args, cost(), err)
 
 		l.End()
 	}()
 
-	rows, err = c.Conn.(driver.QueryerContext).QueryContext(_ctx, replaceValueHolder(query), args)
+	rows, err = c.Conn.(driver.QueryerContext).QueryContext(_ctx, replaceValueHolder(query, args), args)
 	return
 }
 
@@ -89,6 +101,7 @@ func (c *LoggingConn) ExecContext(ctx context.Context, query string, args []driv
 
 	defer func() {
 		qs := interpolate(query, args)
+		c.observe(ctx, "exec", query, args, cost(), err)
 
 		if err != nil {
 			if pgError, ok := sqlx.UnwrapAll(err).(*pq.Error); !ok {
@@ -98,15 +111,14 @@ func (c *LoggingConn) ExecContext(ctx context.Context, query string, args []driv
 			} else {
 				l.Error(errors.Wrapf(pgError, "exec failed: %s", qs))
 			}
-			return
+		} else {
+			l.WithValues("cost", cost().String()).Debug(qs.String())
 		}
 
-		l.WithValues("cost", cost().String()).Debug(qs.String())
-
 		l.End()
 	}()
 
-	res, err = c.Conn.(driver.ExecerContext).ExecContext(_ctx, replaceValueHolder(query), args)
+	res, err = c.Conn.(driver.ExecerContext).ExecContext(_ctx, replaceValueHolder(query, args), args)
 	return
 }
 
@@ -134,7 +146,12 @@ func (tx *LoggingTx) Rollback() error {
 	return nil
 }
 
-func replaceValueHolder(query string) string {
+// replaceValueHolder replaces `?` holders of query to `$n`, query without
+// args is executed verbatim, eg: raw sql with jsonb `?` operators
+func replaceValueHolder(query string, args []driver.NamedValue) string {
+	if len(args) == 0 {
+		return query
+	}
 	qc := 0
 	buf := bytes.NewBuffer(nil)
 
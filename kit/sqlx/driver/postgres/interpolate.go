@@ -29,6 +29,9 @@ func (p *SqlPrinter) String() string {
 }
 
 func InterpolateParams(query string, args []driver.NamedValue, loc *time.Location) (string, error) {
+	if len(args) == 0 {
+		return query, nil
+	}
 	if strings.Count(query, "?") != len(args) {
 		return "", driver.ErrSkip
 	}
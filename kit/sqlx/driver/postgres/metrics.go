@@ -0,0 +1,201 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/saitofun/qkit/conf/log"
+	"github.com/saitofun/qkit/kit/metrics"
+)
+
+var (
+	// QueryDuration cost of statements in seconds labeled by comment of
+	// statement, eg: User.FetchByID, and op, query or exec
+	QueryDuration = metrics.Default.Histogram(
+		"postgres_query_duration_seconds", "cost of sql statements",
+		metrics.DefBuckets, "name", "op",
+	)
+	// QueryErrors count of failed statements
+	QueryErrors = metrics.Default.Counter(
+		"postgres_query_errors_total", "count of failed sql statements",
+		"name", "op",
+	)
+	// SlowQueries count of statements cost over Driver.SlowQueryThreshold
+	SlowQueries = metrics.Default.Counter(
+		"postgres_slow_queries_total", "count of slow sql statements",
+		"name", "op",
+	)
+)
+
+// observe records metrics of statement, and logs it if slow
+func (c *LoggingConn) observe(ctx context.Context, op, query string, args []driver.NamedValue, cost time.Duration, err error) {
+	name := CommentOf(query)
+
+	QueryDuration.Observe(cost.Seconds(), name, op)
+	if err != nil {
+		QueryErrors.Inc(name, op)
+	}
+
+	if c.drv == nil || c.drv.SlowQueryThreshold <= 0 || cost < c.drv.SlowQueryThreshold {
+		return
+	}
+	SlowQueries.Inc(name, op)
+
+	msg := fmt.Sprintf("slow %s cost %s: %s", op, cost, interpolate(query, args))
+	l := log.FromContext(ctx)
+
+	if !c.drv.ExplainSlowQuery || err != nil || !explainable(query) {
+		l.Warn(errors.New(msg))
+		return
+	}
+
+	// explained by another connection, current connection may be busy with
+	// rows not read or in transaction
+	args = append([]driver.NamedValue(nil), args...)
+	queued := explains.Explain(c.cfg, query, args, func(plan string, err error) {
+		if err != nil {
+			l.Warn(errors.Wrapf(err, "%s, explain failed", msg))
+			return
+		}
+		l.Warn(errors.Errorf("%s\n%s", msg, plan))
+	})
+	if !queued {
+		l.Warn(errors.Errorf("%s, explain skipped", msg))
+	}
+}
+
+const (
+	explainQueueSize = 16
+	explainTimeout   = 10 * time.Second
+)
+
+// explains explains slow queries of all drivers, in background by a single
+// worker, so explaining never piles up connections on database
+var explains = &explainer{dbs: make(map[string]*sql.DB)}
+
+// explainer explains queries in order of queued with a connection pooled for
+// each dsn. queries are skipped if queue is full. the worker is started when
+// the first query queued, and stopped when all pooled connections closed
+type explainer struct {
+	mtx   sync.Mutex
+	queue chan func()
+	dbs   map[string]*sql.DB
+}
+
+// Explain queues explaining query on database of cfg, done is called with the
+// plan after explained. returns false if skipped
+func (e *explainer) Explain(cfg, query string, args []driver.NamedValue, done func(string, error)) bool {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	db, ok := e.dbs[cfg]
+	if !ok {
+		c, err := pq.NewConnector(cfg)
+		if err != nil {
+			done("", err)
+			return true
+		}
+		db = sql.OpenDB(c)
+		db.SetMaxOpenConns(1)
+		db.SetMaxIdleConns(1)
+		e.dbs[cfg] = db
+	}
+
+	if e.queue == nil {
+		e.queue = make(chan func(), explainQueueSize)
+		go func(queue <-chan func()) {
+			for f := range queue {
+				f()
+			}
+		}(e.queue)
+	}
+
+	select {
+	case e.queue <- func() { done(explain(db, query, args)) }:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close closes pooled connection of cfg, and stops the worker if no pooled
+// connections left. queries queued are failed if connection closed
+func (e *explainer) Close(cfg string) error {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	db, ok := e.dbs[cfg]
+	if !ok {
+		return nil
+	}
+	delete(e.dbs, cfg)
+	if len(e.dbs) == 0 && e.queue != nil {
+		close(e.queue)
+		e.queue = nil
+	}
+	return db.Close()
+}
+
+func explain(db *sql.DB, query string, args []driver.NamedValue) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), explainTimeout)
+	defer cancel()
+
+	values := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		values = append(values, arg.Value)
+	}
+
+	rows, err := db.QueryContext(ctx, "EXPLAIN "+replaceValueHolder(query, args), values...)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	lines := make([]string, 0)
+	for rows.Next() {
+		line := ""
+		if err := rows.Scan(&line); err != nil {
+			return "", err
+		}
+		lines = append(lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func explainable(query string) bool {
+	q := strings.ToUpper(strings.TrimSpace(query))
+	for _, prefix := range []string{"SELECT", "INSERT", "UPDATE", "DELETE", "WITH"} {
+		if strings.HasPrefix(q, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// CommentOf returns the last comment of query, which is attached by
+// builder.Comment, eg: User.FetchByID. returns `unknown` if no comment
+func CommentOf(query string) string {
+	end := strings.LastIndex(query, "*/")
+	if end < 0 {
+		return "unknown"
+	}
+	start := strings.LastIndex(query[:end], "/*")
+	if start < 0 {
+		return "unknown"
+	}
+	if name := strings.TrimSpace(query[start+2 : end]); name != "" {
+		return name
+	}
+	return "unknown"
+}
@@ -0,0 +1,28 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+
+	"github.com/saitofun/qkit/kit/sqlx/driver/postgres"
+)
+
+func TestCommentOf(t *testing.T) {
+	cases := map[string]string{
+		"SELECT * FROM t_user WHERE f_id = $1 /* User.FetchByID */": "User.FetchByID",
+		"SELECT 1":                           "unknown",
+		"SELECT /* hint */ 1 /* Org.List */": "Org.List",
+		"SELECT 1 /* */":                     "unknown",
+	}
+	for query, name := range cases {
+		gomega.NewWithT(t).Expect(postgres.CommentOf(query)).To(gomega.Equal(name))
+	}
+}
+
+func TestInterpolateParamsWithoutArgs(t *testing.T) {
+	query := "SELECT f_data ? 'key' FROM t_user;"
+	interpolated, err := postgres.InterpolateParams(query, nil, nil)
+	gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+	gomega.NewWithT(t).Expect(interpolated).To(gomega.Equal(query))
+}
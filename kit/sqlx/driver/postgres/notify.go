@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/saitofun/qkit/conf/log"
+	"github.com/saitofun/qkit/kit/sqlx/builder"
+)
+
+const (
+	listenMinReconnectInterval = 10 * time.Second
+	listenMaxReconnectInterval = time.Minute
+	listenPingInterval         = 90 * time.Second
+)
+
+// Notification received from channel listened
+type Notification struct {
+	Channel string
+	Payload string
+	// PID of server process sent the notification
+	PID int
+	// Reconnected is set when connection re-established, notifications sent
+	// during reconnecting are lost, listeners should resync state
+	Reconnected bool
+}
+
+// Notify sends payload to listeners of channel. if executed in transaction,
+// notification is sent after committed and discarded if rolled back
+func Notify(channel, payload string) builder.SqlExpr {
+	return builder.Expr("SELECT pg_notify(?, ?);", channel, payload)
+}
+
+// Listen listens channel by a dedicated connection, the connection is
+// re-established automatically when lost, and notifications sent during
+// reconnecting are lost, a Notification with Reconnected set is sent after
+// re-established. the returned chan is closed when ctx done
+func (c *Connector) Listen(ctx context.Context, channel string) (<-chan Notification, error) {
+	l := log.FromContext(ctx)
+
+	listener := pq.NewListener(
+		c.dsn(c.DBName),
+		listenMinReconnectInterval,
+		listenMaxReconnectInterval,
+		func(ev pq.ListenerEventType, err error) {
+			if err != nil {
+				l.Warn(errors.Wrapf(err, "listener of %s event %d", channel, ev))
+			}
+		},
+	)
+	if err := listener.Listen(channel); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+
+	notifications := make(chan Notification, cap(listener.Notify))
+
+	go func() {
+		defer close(notifications)
+		defer listener.Close()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case n := <-listener.Notify:
+				// nil is sent after connection re-established
+				notification := Notification{Channel: channel, Reconnected: true}
+				if n != nil {
+					notification = Notification{Channel: n.Channel, Payload: n.Extra, PID: n.BePid}
+				}
+				select {
+				case notifications <- notification:
+				case <-ctx.Done():
+					return
+				}
+			case <-time.After(listenPingInterval):
+				go func() { _ = listener.Ping() }()
+			}
+		}
+	}()
+
+	return notifications, nil
+}
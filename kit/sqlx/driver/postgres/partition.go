@@ -0,0 +1,239 @@
+package postgres
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/saitofun/qkit/conf/log"
+	"github.com/saitofun/qkit/kit/sqlx"
+	"github.com/saitofun/qkit/kit/sqlx/builder"
+)
+
+type PartitionOption func(*PartitionManager)
+
+// WithPartitionInterval sets time range covered by each partition, default 24
+// hours. partitions are aligned to interval in UTC
+func WithPartitionInterval(d time.Duration) PartitionOption {
+	return func(pm *PartitionManager) { pm.interval = d }
+}
+
+// WithPartitionPremake sets count of future partitions created ahead of the
+// current one, default 3
+func WithPartitionPremake(n int) PartitionOption {
+	return func(pm *PartitionManager) { pm.premake = n }
+}
+
+// WithPartitionRetention sets duration partitions kept after they ended,
+// expired partitions are detached. default 0 keeps all partitions
+func WithPartitionRetention(d time.Duration) PartitionOption {
+	return func(pm *PartitionManager) { pm.retention = d }
+}
+
+// WithPartitionDropExpired drops expired partitions after detached
+func WithPartitionDropExpired() PartitionOption {
+	return func(pm *PartitionManager) { pm.drop = true }
+}
+
+// WithPartitionSchedule sets interval of maintaining partitions, default 1
+// hour
+func WithPartitionSchedule(d time.Duration) PartitionOption {
+	return func(pm *PartitionManager) { pm.schedule = d }
+}
+
+func NewPartitionManager(db sqlx.DBExecutor, m builder.Model, options ...PartitionOption) *PartitionManager {
+	pm := &PartitionManager{
+		db:       db,
+		m:        m,
+		interval: 24 * time.Hour,
+		premake:  3,
+		schedule: time.Hour,
+	}
+	for _, opt := range options {
+		opt(pm)
+	}
+	return pm
+}
+
+// PartitionManager maintains partitions of table partitioned by RANGE on a
+// time field. partitions covering the current and next premake intervals are
+// created ahead, and partitions ended before retention are detached, or
+// dropped if configured. partitions are named by table and start of interval,
+// eg: t_telemetry_p20261018, partitions named otherwise are left untouched.
+// bounds are written as timestamp if key column is timestamp, otherwise as
+// unix seconds like types.Timestamp.
+type PartitionManager struct {
+	db        sqlx.DBExecutor
+	m         builder.Model
+	interval  time.Duration
+	premake   int
+	retention time.Duration
+	drop      bool
+	schedule  time.Duration
+}
+
+// RangePartition covers rows whose partition key in [From, To)
+type RangePartition struct {
+	Name string
+	From time.Time
+	To   time.Time
+}
+
+// Partitions returns partitions should exist at now
+func (pm *PartitionManager) Partitions(now time.Time) []RangePartition {
+	from := now.UTC().Truncate(pm.interval)
+	partitions := make([]RangePartition, 0, pm.premake+1)
+	for i := 0; i <= pm.premake; i++ {
+		partitions = append(partitions, RangePartition{
+			Name: pm.m.TableName() + "_p" + from.Format(pm.layout()),
+			From: from,
+			To:   from.Add(pm.interval),
+		})
+		from = from.Add(pm.interval)
+	}
+	return partitions
+}
+
+// Expired returns partitions of names expired at now, always empty if
+// retention not set
+func (pm *PartitionManager) Expired(now time.Time, names []string) []string {
+	if pm.retention <= 0 {
+		return nil
+	}
+	prefix := pm.m.TableName() + "_p"
+	deadline := now.Add(-pm.retention)
+
+	expired := make([]string, 0)
+	for _, name := range names {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		from, err := time.ParseInLocation(pm.layout(), name[len(prefix):], time.UTC)
+		if err != nil {
+			continue
+		}
+		if !from.Add(pm.interval).After(deadline) {
+			expired = append(expired, name)
+		}
+	}
+	return expired
+}
+
+// MaintainOnce creates partitions ahead and expires old ones
+func (pm *PartitionManager) MaintainOnce() error {
+	t := pm.db.T(pm.m)
+	if t == nil {
+		return errors.Errorf("table %s is not registered", pm.m.TableName())
+	}
+	c, ok := pm.db.Dialect().(*Connector)
+	if !ok {
+		return errors.Errorf("partitions of %s are not supported by %s", t.Name, pm.db.Dialect().DriverName())
+	}
+	if t.Partition.IsNil() || t.Partition.Method != builder.PartitionByRange {
+		return errors.Errorf("table %s is not partitioned by range", t.Name)
+	}
+	col := t.Partition.KeyCol(t)
+	if col == nil {
+		return errors.Errorf("partition key of %s is not a field", t.Name)
+	}
+	literal := pm.bound(c, col)
+
+	now := time.Now()
+
+	for _, p := range pm.Partitions(now) {
+		bound := "FROM (" + literal(p.From) + ") TO (" + literal(p.To) + ")"
+		if _, err := pm.db.Exec(c.CreatePartition(t, p.Name, bound)); err != nil {
+			return err
+		}
+	}
+
+	names, err := pm.partitionNames(t)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range pm.Expired(now, names) {
+		tasks := sqlx.NewTasks(pm.db).With(func(db sqlx.DBExecutor) error {
+			_, err := db.Exec(c.DetachPartition(t, name))
+			return err
+		})
+		if pm.drop {
+			tasks = tasks.With(func(db sqlx.DBExecutor) error {
+				_, err := db.Exec(c.DropTable(partitionOf(t, name)))
+				return err
+			})
+		}
+		if err := tasks.Do(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run maintains partitions on schedule until ctx done
+func (pm *PartitionManager) Run(ctx context.Context) {
+	l := log.FromContext(ctx)
+
+	for {
+		if err := pm.MaintainOnce(); err != nil {
+			l.Warn(errors.Wrap(err, "maintain partitions failed"))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(pm.schedule):
+		}
+	}
+}
+
+func (pm *PartitionManager) layout() string {
+	switch {
+	case pm.interval%(24*time.Hour) == 0:
+		return "20060102"
+	case pm.interval%time.Hour == 0:
+		return "2006010215"
+	default:
+		return "200601021504"
+	}
+}
+
+func (pm *PartitionManager) bound(c *Connector, col *builder.Column) func(time.Time) string {
+	dataType := builder.ResolveExpr(c.DataType(col.ColumnType)).Query()
+	if strings.HasPrefix(dataType, "timestamp") || strings.HasPrefix(dataType, "date") {
+		return func(t time.Time) string { return "'" + t.UTC().Format(time.RFC3339) + "'" }
+	}
+	return func(t time.Time) string { return "'" + strconv.FormatInt(t.Unix(), 10) + "'" }
+}
+
+func (pm *PartitionManager) partitionNames(t *builder.Table) ([]string, error) {
+	schema := "public"
+	if t.Schema != "" {
+		schema = t.Schema
+	}
+
+	rows := make([]PartitionSchema, 0)
+	err := pm.db.QueryAndScan(builder.Expr( /* language=PostgreSQL */ `
+SELECT c.relname AS partition_name
+FROM pg_inherits i
+JOIN pg_class c ON c.oid = i.inhrelid
+JOIN pg_class p ON p.oid = i.inhparent
+JOIN pg_namespace n ON n.oid = p.relnamespace
+WHERE p.relname = ? AND n.nspname = ?
+ORDER BY c.relname`, t.Name, schema), &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(rows))
+	for _, row := range rows {
+		names = append(names, row.PARTITION_NAME)
+	}
+	return names, nil
+}
+
+type PartitionSchema struct {
+	PARTITION_NAME string `db:"partition_name"`
+}
@@ -90,11 +90,94 @@ func databaseFromSchema(db sqlx.DBExecutor) (*sqlx.Database, error) {
 			}
 			table.AddKey(key)
 		}
+
+		constraints := make([]ConstraintSchema, 0)
+		err = db.QueryAndScan(builder.Expr( /* language=PostgreSQL */ `
+SELECT rel.relname AS table_name, con.conname AS constraint_name,
+	con.contype AS constraint_type, pg_get_constraintdef(con.oid) AS constraint_def
+FROM pg_constraint con
+JOIN pg_class rel ON rel.oid = con.conrelid
+JOIN pg_namespace nsp ON nsp.oid = rel.relnamespace
+WHERE con.contype IN ('c', 'x') AND nsp.nspname = ?
+ORDER BY rel.relname, con.conname`, schema), &constraints)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, cs := range constraints {
+			table := d.Table(cs.TABLE_NAME)
+			// constraints not created by table definition are ignored
+			if table == nil || !strings.HasPrefix(cs.CONSTRAINT_NAME, table.Name+"_") {
+				continue
+			}
+			table.AddConstraint(constraintFromSchema(table, &cs))
+		}
 	}
 
 	return d, nil
 }
 
+func constraintFromSchema(table *builder.Table, cs *ConstraintSchema) *builder.Constraint {
+	name := strings.ToLower(cs.CONSTRAINT_NAME[len(table.Name)+1:])
+	def := strings.TrimSuffix(cs.CONSTRAINT_DEF, " NOT VALID")
+	if cs.CONSTRAINT_TYPE == "x" {
+		return builder.Exclude(name, strings.TrimPrefix(def, "EXCLUDE "))
+	}
+	return builder.Check(name, strings.TrimPrefix(def, "CHECK "))
+}
+
+// foreignKeysFromSchema returns foreign keys of tables in database by table
+func foreignKeysFromSchema(db sqlx.DBExecutor) (map[string][]*builder.ForeignKey, error) {
+	d := db.D()
+
+	schema := "public"
+	if d.Schema != "" {
+		schema = d.Schema
+	}
+
+	rows := make([]ForeignKeySchema, 0)
+	err := db.QueryAndScan(builder.Expr( /* language=PostgreSQL */ `
+SELECT tc.table_name, tc.constraint_name, kcu.column_name,
+	ccu.table_name AS ref_table_name, ccu.column_name AS ref_column_name,
+	rc.delete_rule, rc.update_rule
+FROM information_schema.table_constraints tc
+JOIN information_schema.key_column_usage kcu
+	ON kcu.constraint_schema = tc.constraint_schema AND kcu.constraint_name = tc.constraint_name
+JOIN information_schema.constraint_column_usage ccu
+	ON ccu.constraint_schema = tc.constraint_schema AND ccu.constraint_name = tc.constraint_name
+JOIN information_schema.referential_constraints rc
+	ON rc.constraint_schema = tc.constraint_schema AND rc.constraint_name = tc.constraint_name
+WHERE tc.constraint_type = 'FOREIGN KEY' AND tc.table_schema = ?
+ORDER BY tc.table_name, tc.constraint_name`, schema), &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	fks := map[string][]*builder.ForeignKey{}
+	names := map[string]bool{}
+	for _, row := range rows {
+		// constraints of multi columns are identified by name only
+		if d.Table(row.TABLE_NAME) == nil || names[row.CONSTRAINT_NAME] {
+			continue
+		}
+		names[row.CONSTRAINT_NAME] = true
+
+		tbl, ref := builder.T(row.TABLE_NAME), builder.T(row.REF_TABLE_NAME)
+		tbl.Schema, ref.Schema = d.Schema, d.Schema
+		tbl.AddCol(builder.Col(row.COLUMN_NAME))
+		ref.AddCol(builder.Col(row.REF_COLUMN_NAME))
+
+		fks[row.TABLE_NAME] = append(fks[row.TABLE_NAME], &builder.ForeignKey{
+			Name:     row.CONSTRAINT_NAME,
+			Col:      tbl.Col(row.COLUMN_NAME),
+			Ref:      ref.Col(row.REF_COLUMN_NAME),
+			OnDelete: builder.RelAction(row.DELETE_RULE),
+			OnUpdate: builder.RelAction(row.UPDATE_RULE),
+		})
+	}
+	return fks, nil
+}
+
 func colFromSchema(columnSchema *ColumnSchema) *builder.Column {
 	col := builder.Col(columnSchema.COLUMN_NAME)
 
@@ -123,6 +206,10 @@ func colFromSchema(columnSchema *ColumnSchema) *builder.Column {
 
 	col.DataType = dataType
 
+	if columnSchema.IS_GENERATED == "ALWAYS" {
+		col.Generated = columnSchema.GENERATION_EXPRESSION
+	}
+
 	// numeric type
 	if columnSchema.NUMERIC_PRECISION > 0 {
 		col.Length = columnSchema.NUMERIC_PRECISION
@@ -148,6 +235,8 @@ type ColumnSchema struct {
 	CHARACTER_MAXIMUM_LENGTH uint64 `db:"character_maximum_length"`
 	NUMERIC_PRECISION        uint64 `db:"numeric_precision"`
 	NUMERIC_SCALE            uint64 `db:"numeric_scale"`
+	IS_GENERATED             string `db:"is_generated"`
+	GENERATION_EXPRESSION    string `db:"generation_expression"`
 }
 
 func (ColumnSchema) TableName() string { return "columns" }
@@ -161,6 +250,24 @@ type IndexSchema struct {
 
 func (IndexSchema) TableName() string { return "pg_indexes" }
 
+type ConstraintSchema struct {
+	TABLE_NAME      string `db:"table_name"`
+	CONSTRAINT_NAME string `db:"constraint_name"`
+	// CONSTRAINT_TYPE c for CHECK, x for EXCLUDE
+	CONSTRAINT_TYPE string `db:"constraint_type"`
+	CONSTRAINT_DEF  string `db:"constraint_def"`
+}
+
+type ForeignKeySchema struct {
+	TABLE_NAME      string `db:"table_name"`
+	CONSTRAINT_NAME string `db:"constraint_name"`
+	COLUMN_NAME     string `db:"column_name"`
+	REF_TABLE_NAME  string `db:"ref_table_name"`
+	REF_COLUMN_NAME string `db:"ref_column_name"`
+	DELETE_RULE     string `db:"delete_rule"`
+	UPDATE_RULE     string `db:"update_rule"`
+}
+
 var SchemaDB = sqlx.NewDatabase("INFORMATION_SCHEMA")
 
 func init() {
@@ -0,0 +1,386 @@
+package sqlite
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strconv"
+
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/saitofun/qkit/kit/sqlx"
+	"github.com/saitofun/qkit/kit/sqlx/builder"
+	"github.com/saitofun/qkit/kit/sqlx/migration"
+	"github.com/saitofun/qkit/x/typesx"
+)
+
+var _ interface {
+	driver.Connector
+	builder.Dialect
+	sqlx.Migrator
+} = (*Connector)(nil)
+
+// Connector of sqlite. sqlite has no schema, database schema should be empty.
+// columns cannot be modified by sqlite, changes of column type, null or
+// default should be done by versioned migration.
+type Connector struct {
+	// Dir of database file named `{DBName}.db`, database is in memory and
+	// shared by connections of the same DBName if Dir is empty
+	Dir    string
+	DBName string
+	// Extra query of dsn, eg: _busy_timeout=5000&_foreign_keys=1
+	Extra string
+}
+
+func (c *Connector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.Driver().Open(c.dsn())
+}
+
+func (Connector) Driver() driver.Driver { return &Driver{} }
+
+func (c Connector) dsn() string {
+	if c.Dir == "" {
+		dsn := "file:" + c.DBName + "?mode=memory&cache=shared"
+		if c.Extra != "" {
+			dsn += "&" + c.Extra
+		}
+		return dsn
+	}
+	dsn := "file:" + filepath.Join(c.Dir, c.DBName+".db")
+	if c.Extra != "" {
+		dsn += "?" + c.Extra
+	}
+	return dsn
+}
+
+func (c Connector) WithDBName(name string) driver.Connector { c.DBName = name; return &c }
+
+func (c *Connector) Migrate(ctx context.Context, db sqlx.DBExecutor) error {
+	output := migration.OutputFromContext(ctx)
+	plan := migration.PlanFromContext(ctx)
+
+	prevDB, err := databaseFromSchema(db)
+	if err != nil {
+		return err
+	}
+
+	d := db.D()
+	dialect := db.Dialect()
+
+	exec := func(expr builder.SqlExpr) error {
+		if expr == nil || expr.IsNil() {
+			return nil
+		}
+
+		if output != nil {
+			_, _ = io.WriteString(output, builder.ResolveExpr(expr).Query())
+			_, _ = io.WriteString(output, "\n")
+			return nil
+		}
+
+		_, err := db.Exec(expr)
+		return err
+	}
+
+	for _, name := range d.Tables.SortedTableNames() {
+		table := d.Table(name)
+
+		var steps []*builder.MigrationStep
+		if prevTable := prevDB.Table(name); prevTable == nil {
+			steps = builder.CreateTableSteps(table, dialect)
+		} else {
+			steps = table.DiffSteps(prevTable, dialect)
+		}
+
+		if plan != nil {
+			plan.Add(steps...)
+		}
+
+		for _, step := range steps {
+			if err := exec(step.Expr); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (Connector) DriverName() string { return "sqlite" }
+
+func (Connector) PrimaryKeyName() string { return "pkey" }
+
+func (Connector) IsErrorUnknownDatabase(err error) bool { return false }
+
+func (Connector) IsErrorConflict(err error) bool {
+	if e, ok := sqlx.UnwrapAll(err).(sqlite3.Error); ok {
+		return e.ExtendedCode == sqlite3.ErrConstraintUnique ||
+			e.ExtendedCode == sqlite3.ErrConstraintPrimaryKey
+	}
+	return false
+}
+
+func (Connector) IsErrorRetryable(err error) bool {
+	if e, ok := sqlx.UnwrapAll(err).(sqlite3.Error); ok {
+		return e.Code == sqlite3.ErrBusy || e.Code == sqlite3.ErrLocked
+	}
+	return false
+}
+
+// CreateDatabase database file is created when connected
+func (c *Connector) CreateDatabase(string) builder.SqlExpr { return nil }
+
+// CreateSchema sqlite has no schema
+func (c *Connector) CreateSchema(string) builder.SqlExpr { return nil }
+
+// DropDatabase database file should be removed by file system
+func (c *Connector) DropDatabase(string) builder.SqlExpr { return nil }
+
+func (c *Connector) AddIndex(key *builder.Key) builder.SqlExpr {
+	if key.IsPrimary() {
+		// primary key can only be declared when table created
+		return nil
+	}
+
+	e := builder.Expr("CREATE ")
+	if key.IsUnique {
+		e.WriteQuery("UNIQUE ")
+	}
+	e.WriteQuery("INDEX ")
+
+	e.WriteQuery(key.Table.Name)
+	e.WriteQuery("_")
+	e.WriteQuery(key.Name)
+
+	e.WriteQuery(" ON ")
+	e.WriteExpr(key.Table)
+
+	e.WriteQueryByte(' ')
+	e.WriteExpr(key.Def.TableExpr(key.Table))
+
+	e.WriteEnd()
+	return e
+}
+
+func (c *Connector) DropIndex(key *builder.Key) builder.SqlExpr {
+	if key.IsPrimary() {
+		return nil
+	}
+	e := builder.Expr("DROP INDEX IF EXISTS ")
+	e.WriteQuery(key.Table.Name)
+	e.WriteQueryByte('_')
+	e.WriteQuery(key.Name)
+	e.WriteEnd()
+	return e
+}
+
+func (c *Connector) CreateTableIsNotExists(t *builder.Table) (exprs []builder.SqlExpr) {
+	expr := builder.Expr("CREATE TABLE IF NOT EXISTS ")
+	expr.WriteExpr(t)
+	expr.WriteQueryByte(' ')
+	expr.WriteGroup(func(e *builder.Ex) {
+		if t.Columns.IsNil() {
+			return
+		}
+
+		autoIncrement := false
+
+		t.Columns.Range(func(col *builder.Column, idx int) {
+			if col.DeprecatedActs != nil {
+				return
+			}
+			if col.AutoIncrement {
+				autoIncrement = true
+			}
+
+			if idx > 0 {
+				e.WriteQueryByte(',')
+			}
+			e.WriteQueryByte('\n')
+			e.WriteQueryByte('\t')
+
+			e.WriteExpr(col)
+			e.WriteQueryByte(' ')
+			e.WriteExpr(c.DataType(col.ColumnType))
+		})
+
+		t.Keys.Range(func(key *builder.Key, idx int) {
+			// autoincrement column is declared as primary key
+			if key.IsPrimary() && !autoIncrement {
+				e.WriteQueryByte(',')
+				e.WriteQueryByte('\n')
+				e.WriteQueryByte('\t')
+				e.WriteQuery("PRIMARY KEY ")
+				e.WriteExpr(key.Def.TableExpr(key.Table))
+			}
+		})
+
+		expr.WriteQueryByte('\n')
+	})
+
+	expr.WriteEnd()
+	exprs = append(exprs, expr)
+
+	t.Keys.Range(func(key *builder.Key, idx int) {
+		if !key.IsPrimary() {
+			exprs = append(exprs, c.AddIndex(key))
+		}
+	})
+
+	return
+}
+
+func (c *Connector) DropTable(t *builder.Table) builder.SqlExpr {
+	e := builder.Expr("DROP TABLE IF EXISTS ")
+	e.WriteExpr(t)
+	e.WriteEnd()
+	return e
+}
+
+func (c *Connector) TruncateTable(t *builder.Table) builder.SqlExpr {
+	e := builder.Expr("DELETE FROM ")
+	e.WriteExpr(t)
+	e.WriteEnd()
+	return e
+}
+
+func (c *Connector) AddColumn(col *builder.Column) builder.SqlExpr {
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(col.Table)
+	e.WriteQuery(" ADD COLUMN ")
+	e.WriteExpr(col)
+	e.WriteQueryByte(' ')
+	e.WriteExpr(c.DataType(col.ColumnType))
+	e.WriteEnd()
+	return e
+}
+
+func (c *Connector) RenameColumn(col *builder.Column, target *builder.Column) builder.SqlExpr {
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(col.Table)
+	e.WriteQuery(" RENAME COLUMN ")
+	e.WriteExpr(col)
+	e.WriteQuery(" TO ")
+	e.WriteExpr(target)
+	e.WriteEnd()
+	return e
+}
+
+// ModifyColumn sqlite cannot alter column
+func (c *Connector) ModifyColumn(col *builder.Column, prev *builder.Column) builder.SqlExpr {
+	return nil
+}
+
+func (c *Connector) DropColumn(col *builder.Column) builder.SqlExpr {
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(col.Table)
+	e.WriteQuery(" DROP COLUMN ")
+	e.WriteQuery(col.Name)
+	e.WriteEnd()
+	return e
+}
+
+func (c *Connector) Savepoint(name string) builder.SqlExpr {
+	e := builder.Expr("SAVEPOINT ")
+	e.WriteQuery(name)
+	e.WriteEnd()
+	return e
+}
+
+func (c *Connector) RollbackToSavepoint(name string) builder.SqlExpr {
+	e := builder.Expr("ROLLBACK TO SAVEPOINT ")
+	e.WriteQuery(name)
+	e.WriteEnd()
+	return e
+}
+
+func (c *Connector) ReleaseSavepoint(name string) builder.SqlExpr {
+	e := builder.Expr("RELEASE SAVEPOINT ")
+	e.WriteQuery(name)
+	e.WriteEnd()
+	return e
+}
+
+func (c *Connector) DataType(columnType *builder.ColumnType) builder.SqlExpr {
+	return builder.Expr(c.dbDataType(columnType.Type, columnType) + c.dataTypeModify(columnType))
+}
+
+func (c *Connector) dbDataType(typ typesx.Type, columnType *builder.ColumnType) string {
+	if columnType.DataType != "" {
+		return columnType.DataType
+	}
+
+	if rv, ok := typesx.TryNew(typ); ok {
+		if dtd, ok := rv.Interface().(builder.DataTypeDescriber); ok {
+			return dtd.DataType(c.DriverName())
+		}
+	}
+
+	switch typ.Kind() {
+	case reflect.Ptr:
+		return c.dbDataType(typ.Elem(), columnType)
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return "integer"
+	case reflect.Int64, reflect.Uint64:
+		if columnType.AutoIncrement {
+			// only INTEGER PRIMARY KEY could be autoincrement
+			return "integer"
+		}
+		return "bigint"
+	case reflect.Float64:
+		return "double"
+	case reflect.Float32:
+		return "real"
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return "blob"
+		}
+	case reflect.String:
+		size := columnType.Length
+		if size == 0 {
+			size = 255
+		}
+		if size < 65535/3 {
+			return "varchar(" + strconv.FormatUint(size, 10) + ")"
+		}
+		return "text"
+	}
+
+	switch typ.Name() {
+	case "NullInt64":
+		return "bigint"
+	case "NullFloat64":
+		return "double"
+	case "NullBool":
+		return "boolean"
+	case "Time", "NullTime":
+		return "datetime"
+	}
+
+	panic(fmt.Errorf("unsupport type %s", typ))
+}
+
+func (c *Connector) dataTypeModify(columnType *builder.ColumnType) string {
+	buf := bytes.NewBuffer(nil)
+
+	if columnType.AutoIncrement {
+		buf.WriteString(" PRIMARY KEY AUTOINCREMENT")
+	}
+
+	if !columnType.Null {
+		buf.WriteString(" NOT NULL")
+	}
+
+	if columnType.Default != nil {
+		buf.WriteString(" DEFAULT ")
+		buf.WriteString(*columnType.Default)
+	}
+
+	return buf.String()
+}
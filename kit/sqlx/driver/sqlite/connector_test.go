@@ -0,0 +1,147 @@
+package sqlite_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/onsi/gomega"
+
+	"github.com/saitofun/qkit/kit/sqlx"
+	"github.com/saitofun/qkit/kit/sqlx/builder"
+	"github.com/saitofun/qkit/kit/sqlx/driver/sqlite"
+	"github.com/saitofun/qkit/kit/sqlx/migration"
+)
+
+type User struct {
+	ID       uint64 `db:"f_id,autoincrement"`
+	Name     string `db:"f_name,size=255,default=''"`
+	Nickname string `db:"f_nickname,size=255,default=''"`
+	Age      int32  `db:"f_age,default='0'"`
+}
+
+func (*User) TableName() string { return "t_user" }
+
+func (*User) PrimaryKey() []string { return []string{"ID"} }
+
+func (*User) UniqueIndexes() builder.Indexes {
+	return builder.Indexes{"i_name": {"Name"}}
+}
+
+type User2 struct {
+	ID       uint64 `db:"f_id,autoincrement"`
+	Name     string `db:"f_name,size=255,default=''"`
+	Nickname string `db:"f_nickname,size=255,default=''"`
+	Age      int32  `db:"f_age,deprecated"`
+	Email    string `db:"f_email,size=255,default=''"`
+}
+
+func (*User2) TableName() string { return "t_user" }
+
+func (*User2) PrimaryKey() []string { return []string{"ID"} }
+
+func (*User2) UniqueIndexes() builder.Indexes {
+	return builder.Indexes{"i_name": {"Name"}}
+}
+
+func (*User2) Indexes() builder.Indexes {
+	return builder.Indexes{"i_nickname": {"Nickname"}}
+}
+
+func TestConnector(t *testing.T) {
+	c := &sqlite.Connector{}
+
+	table := builder.T("t",
+		builder.Col("f_id").Type(uint64(0), ",autoincrement"),
+		builder.Col("f_name").Type("", ",size=128,default=''"),
+		builder.Col("f_created_at").Type(int64(0), ",default='0'"),
+		builder.PrimaryKey(builder.Cols("f_id")),
+		builder.UniqueIndex("i_name", builder.Cols("f_name")),
+	)
+
+	cases := map[string]struct {
+		expr   builder.SqlExpr
+		expect string
+	}{
+		"CreateTableIsNotExists": {
+			c.CreateTableIsNotExists(table)[0],
+			`CREATE TABLE IF NOT EXISTS t (
+	f_id integer PRIMARY KEY AUTOINCREMENT NOT NULL,
+	f_name varchar(128) NOT NULL DEFAULT '',
+	f_created_at bigint NOT NULL DEFAULT '0'
+);`,
+		},
+		"AddIndex": {
+			c.AddIndex(table.Key("i_name")),
+			"CREATE UNIQUE INDEX t_i_name ON t (f_name);",
+		},
+		"DropIndex": {
+			c.DropIndex(table.Key("i_name")),
+			"DROP INDEX IF EXISTS t_i_name;",
+		},
+		"AddColumn": {
+			c.AddColumn(table.Col("f_name")),
+			"ALTER TABLE t ADD COLUMN f_name varchar(128) NOT NULL DEFAULT '';",
+		},
+		"TruncateTable": {
+			c.TruncateTable(table),
+			"DELETE FROM t;",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			gomega.NewWithT(t).Expect(builder.ResolveExpr(c.expr).Query()).To(gomega.Equal(c.expect))
+		})
+	}
+}
+
+func TestMigrateAndCRUD(t *testing.T) {
+	d := sqlx.NewDatabase("test_sqlite")
+	db := d.OpenDB(&sqlite.Connector{})
+	tbl := d.Register(&User{})
+
+	gomega.NewWithT(t).Expect(migration.Migrate(db, nil)).To(gomega.BeNil())
+
+	t.Run("NoDiff", func(t *testing.T) {
+		plan, err := migration.Plan(db)
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(plan.Steps).To(gomega.BeEmpty())
+	})
+
+	t.Run("CRUD", func(t *testing.T) {
+		user := &User{Name: "a", Age: 10}
+		_, err := db.Exec(sqlx.InsertToDB(db, user, nil))
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+
+		_, err = db.Exec(sqlx.InsertToDB(db, user, nil))
+		gomega.NewWithT(t).Expect(sqlx.DBErr(err).IsConflict()).To(gomega.BeTrue())
+
+		found := &User{}
+		err = db.QueryAndScan(
+			builder.Select(nil).From(tbl, builder.Where(tbl.ColByFieldName("Name").Eq("a"))),
+			found,
+		)
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(found.ID).To(gomega.Equal(uint64(1)))
+		gomega.NewWithT(t).Expect(found.Age).To(gomega.Equal(int32(10)))
+	})
+
+	t.Run("Diff", func(t *testing.T) {
+		d2 := sqlx.NewDatabase("test_sqlite")
+		d2.Register(&User2{})
+		db2 := d2.OpenDB(&sqlite.Connector{})
+
+		buf := bytes.NewBuffer(nil)
+		gomega.NewWithT(t).Expect(migration.Migrate(db2, buf)).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(buf.String()).To(gomega.Equal(
+			"ALTER TABLE t_user DROP COLUMN f_age;\n" +
+				"ALTER TABLE t_user ADD COLUMN f_email varchar(255) NOT NULL DEFAULT '';\n" +
+				"CREATE INDEX t_user_i_nickname ON t_user (f_nickname);\n",
+		))
+
+		gomega.NewWithT(t).Expect(migration.Migrate(db2, nil)).To(gomega.BeNil())
+		plan, err := migration.Plan(db2)
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(plan.Steps).To(gomega.BeEmpty())
+	})
+}
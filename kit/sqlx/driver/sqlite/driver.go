@@ -0,0 +1,123 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql/driver"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/pkg/errors"
+
+	"github.com/saitofun/qkit/conf/log"
+	"github.com/saitofun/qkit/kit/sqlx"
+	"github.com/saitofun/qkit/x/misc/timer"
+)
+
+type Driver struct {
+	drv sqlite3.SQLiteDriver
+}
+
+func (d *Driver) Open(dsn string) (driver.Conn, error) {
+	conn, err := d.drv.Open(dsn)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Driver.Open")
+	}
+	return &LoggingConn{conn}, nil
+}
+
+type LoggingConn struct {
+	driver.Conn
+}
+
+var _ interface {
+	driver.ConnBeginTx
+	driver.ExecerContext
+	driver.QueryerContext
+} = (*LoggingConn)(nil)
+
+func (c *LoggingConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	l := log.FromContext(ctx)
+	l.Debug("=========== Beginning Transaction ===========")
+	tx, err := c.Conn.(driver.ConnBeginTx).BeginTx(ctx, opts)
+	if err != nil {
+		l.Error(errors.Wrap(err, "failed to begin transaction"))
+		return nil, err
+	}
+	return &LoggingTx{tx: tx, l: l}, nil
+}
+
+func (c *LoggingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (rows driver.Rows, err error) {
+	cost := timer.Start()
+	_ctx, l := log.Start(ctx, "Query")
+
+	defer func() {
+		if err != nil {
+			if e, ok := sqlx.UnwrapAll(err).(sqlite3.Error); !ok {
+				l.Error(errors.Wrapf(err, "query failed: %s %v", query, values(args)))
+			} else {
+				l.Warn(errors.Wrapf(e, "query failed: %s %v", query, values(args)))
+			}
+		} else {
+			l.WithValues("cost", cost().String()).Debug("%s %v", query, values(args))
+		}
+
+		l.End()
+	}()
+
+	rows, err = c.Conn.(driver.QueryerContext).QueryContext(_ctx, query, args)
+	return
+}
+
+func (c *LoggingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (res driver.Result, err error) {
+	cost := timer.Start()
+	_ctx, l := log.Start(ctx, "Exec")
+
+	defer func() {
+		if err != nil {
+			if e, ok := sqlx.UnwrapAll(err).(sqlite3.Error); !ok {
+				l.Error(errors.Wrapf(err, "exec failed: %s %v", query, values(args)))
+			} else if e.Code == sqlite3.ErrConstraint {
+				l.Warn(errors.Wrapf(e, "exec failed: %s %v", query, values(args)))
+			} else {
+				l.Error(errors.Wrapf(e, "exec failed: %s %v", query, values(args)))
+			}
+		} else {
+			l.WithValues("cost", cost().String()).Debug("%s %v", query, values(args))
+		}
+
+		l.End()
+	}()
+
+	res, err = c.Conn.(driver.ExecerContext).ExecContext(_ctx, query, args)
+	return
+}
+
+type LoggingTx struct {
+	l  log.Logger
+	tx driver.Tx
+}
+
+func (tx *LoggingTx) Commit() error {
+	if err := tx.tx.Commit(); err != nil {
+		tx.l.Debug("failed to commit transaction: %s", err)
+		return err
+	}
+	tx.l.Debug("=========== Committed Transaction ===========")
+	return nil
+}
+
+func (tx *LoggingTx) Rollback() error {
+	if err := tx.tx.Rollback(); err != nil {
+		tx.l.Debug("failed to rollback transaction: %s", err)
+		return err
+	}
+	tx.l.Debug("=========== Rollback Transaction ===========")
+	return nil
+}
+
+func values(args []driver.NamedValue) []interface{} {
+	vs := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		vs = append(vs, arg.Value)
+	}
+	return vs
+}
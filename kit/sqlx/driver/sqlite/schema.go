@@ -0,0 +1,156 @@
+package sqlite
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/saitofun/qkit/kit/sqlx"
+	"github.com/saitofun/qkit/kit/sqlx/builder"
+	"github.com/saitofun/qkit/x/misc/slice"
+)
+
+func databaseFromSchema(db sqlx.DBExecutor) (*sqlx.Database, error) {
+	d := db.D()
+
+	var (
+		tableNames = slice.ToAnySlice(d.Tables.TableNames()...)
+		master     = SchemaDB.T(&MasterSchema{})
+		tables     = make([]MasterSchema, 0)
+	)
+
+	d = sqlx.NewDatabase(d.Name)
+
+	err := db.QueryAndScan(
+		builder.Select(master.Columns.Clone()).
+			From(
+				master,
+				builder.Where(
+					builder.And(
+						master.ColByFieldName("Type").Eq("table"),
+						master.ColByFieldName("TblName").In(tableNames...),
+					),
+				),
+			),
+		&tables,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ts := range tables {
+		columns := make([]ColumnSchema, 0)
+		err := db.QueryAndScan(builder.Expr("SELECT * FROM pragma_table_info(?)", ts.TblName), &columns)
+		if err != nil {
+			return nil, err
+		}
+
+		tbl := builder.T(ts.TblName)
+		d.AddTable(tbl)
+
+		autoIncrement := strings.Contains(strings.ToUpper(ts.SQL), "AUTOINCREMENT")
+
+		pks := make([]ColumnSchema, 0)
+		for i := range columns {
+			cs := columns[i]
+			if cs.PK > 0 {
+				pks = append(pks, cs)
+			}
+			tbl.AddCol(colFromSchema(&cs, autoIncrement && cs.PK > 0))
+		}
+
+		if len(pks) > 0 {
+			sort.Slice(pks, func(i, j int) bool { return pks[i].PK < pks[j].PK })
+			names := make([]string, 0, len(pks))
+			for _, pk := range pks {
+				names = append(names, pk.Name)
+			}
+			tbl.AddKey(&builder.Key{
+				Name:     (&Connector{}).PrimaryKeyName(),
+				IsUnique: true,
+				Def:      builder.IndexDef{ColNames: names},
+			})
+		}
+	}
+
+	if len(tables) != 0 {
+		indexes := make([]MasterSchema, 0)
+
+		err = db.QueryAndScan(
+			builder.Select(master.Columns.Clone()).
+				From(
+					master,
+					builder.Where(
+						builder.And(
+							master.ColByFieldName("Type").Eq("index"),
+							master.ColByFieldName("TblName").In(tableNames...),
+							// indexes created by sqlite for constraints
+							master.ColByFieldName("SQL").IsNotNull(),
+						),
+					),
+				),
+			&indexes,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, index := range indexes {
+			table := d.Table(index.TblName)
+			if table == nil || !strings.HasPrefix(index.Name, table.Name+"_") {
+				continue
+			}
+			expr := index.SQL
+			if i := strings.Index(expr, "("); i >= 0 {
+				expr = expr[i:]
+			}
+			table.AddKey(&builder.Key{
+				Name:     strings.ToLower(index.Name[len(table.Name)+1:]),
+				IsUnique: strings.HasPrefix(strings.ToUpper(index.SQL), "CREATE UNIQUE"),
+				Def:      builder.IndexDef{Expr: strings.TrimSpace(expr)},
+			})
+		}
+	}
+
+	return d, nil
+}
+
+func colFromSchema(cs *ColumnSchema, autoIncrement bool) *builder.Column {
+	col := builder.Col(cs.Name)
+
+	col.DataType = strings.ToLower(cs.Type)
+	col.AutoIncrement = autoIncrement
+	col.Null = cs.NotNull == 0
+
+	if cs.DefaultValue != "" {
+		defaultValue := cs.DefaultValue
+		col.Default = &defaultValue
+	}
+
+	return col
+}
+
+// MasterSchema rows of tables and indexes
+type MasterSchema struct {
+	Type    string `db:"type"`
+	Name    string `db:"name"`
+	TblName string `db:"tbl_name"`
+	SQL     string `db:"sql"`
+}
+
+func (MasterSchema) TableName() string { return "sqlite_master" }
+
+// ColumnSchema rows of pragma_table_info
+type ColumnSchema struct {
+	CID          int    `db:"cid"`
+	Name         string `db:"name"`
+	Type         string `db:"type"`
+	NotNull      int    `db:"notnull"`
+	DefaultValue string `db:"dflt_value"`
+	PK           int    `db:"pk"`
+}
+
+var SchemaDB = sqlx.NewDatabase("sqlite")
+
+func init() {
+	SchemaDB.Register(&MasterSchema{})
+}
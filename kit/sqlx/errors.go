@@ -2,8 +2,11 @@ package sqlx
 
 import (
 	"fmt"
+	"net/http"
 
 	"github.com/pkg/errors"
+
+	"github.com/saitofun/qkit/kit/statusx"
 )
 
 var ErrNotTx = errors.New("db is not *sql.Tx")
@@ -28,10 +31,27 @@ func (e *SqlError) Error() string {
 type sqlErrType string
 
 var (
-	sqlErrTypeNotFound sqlErrType = "NotFound"
-	sqlErrTypeConflict sqlErrType = "Conflict"
+	sqlErrTypeNotFound        sqlErrType = "NotFound"
+	sqlErrTypeConflict        sqlErrType = "Conflict"
+	sqlErrTypeVersionConflict sqlErrType = "VersionConflict"
 )
 
+// NewVersionConflictError returns error when row updated by stale version of
+// optimistic locking, it responds http 409 as statusx.Error
+func NewVersionConflictError(msg string) *VersionConflictError {
+	return &VersionConflictError{NewSqlError(sqlErrTypeVersionConflict, msg)}
+}
+
+type VersionConflictError struct {
+	*SqlError
+}
+
+func (e *VersionConflictError) Unwrap() error { return e.SqlError }
+
+func (e *VersionConflictError) StatusErr() *statusx.StatusErr {
+	return statusx.Wrap(e.SqlError, http.StatusConflict, "VersionConflict")
+}
+
 var DuplicateEntryErrNumber uint16 = 1062
 
 func DBErr(err error) *dbErr {
@@ -43,9 +63,10 @@ func DBErr(err error) *dbErr {
 type dbErr struct {
 	err error
 
-	errDefault  error
-	errNotFound error
-	errConflict error
+	errDefault         error
+	errNotFound        error
+	errConflict        error
+	errVersionConflict error
 }
 
 func (r dbErr) WithNotFound(err error) *dbErr {
@@ -63,6 +84,11 @@ func (r dbErr) WithConflict(err error) *dbErr {
 	return &r
 }
 
+func (r dbErr) WithVersionConflict(err error) *dbErr {
+	r.errVersionConflict = err
+	return &r
+}
+
 func (r *dbErr) IsNotFound() bool {
 	if sqlErr, ok := UnwrapAll(r.err).(*SqlError); ok {
 		return sqlErr.Type == sqlErrTypeNotFound
@@ -77,6 +103,13 @@ func (r *dbErr) IsConflict() bool {
 	return false
 }
 
+func (r *dbErr) IsVersionConflict() bool {
+	if sqlErr, ok := UnwrapAll(r.err).(*SqlError); ok {
+		return sqlErr.Type == sqlErrTypeVersionConflict
+	}
+	return false
+}
+
 func (r *dbErr) Err() error {
 	if r.err == nil {
 		return nil
@@ -91,6 +124,10 @@ func (r *dbErr) Err() error {
 			if r.errConflict != nil {
 				return r.errConflict
 			}
+		case sqlErrTypeVersionConflict:
+			if r.errVersionConflict != nil {
+				return r.errVersionConflict
+			}
 		}
 		if r.errDefault != nil {
 			return r.errDefault
@@ -26,11 +26,28 @@ func FieldValuesFromModel(table *builder.Table, model builder.Model, zeros ...st
 	if autoIncrementCol := table.AutoIncrement(); autoIncrementCol != nil {
 		delete(fvs, autoIncrementCol.FieldName)
 	}
+	// generated columns are computed by database
+	table.Columns.Range(func(col *builder.Column, _ int) {
+		if col.Generated != "" {
+			delete(fvs, col.FieldName)
+		}
+	})
 	return fvs
 }
 
 type ScanIterator = scanner.ScanIterator
 
+// QueryStream queries e and returns iterator scans rows one by one to T,
+// instead of reading all rows in one call like QueryAndScan. rows should be
+// closed by caller to release the connection.
+func QueryStream[T any](db DBExecutor, e builder.SqlExpr) (*scanner.Rows[T], error) {
+	rows, err := db.Query(e)
+	if err != nil {
+		return nil, err
+	}
+	return scanner.NewRows[T](db.Context(), rows), nil
+}
+
 func Scan(ctx context.Context, rows *sql.Rows, v interface{}) error {
 	if err := scanner.Scan(ctx, rows, v); err != nil {
 		if err == scanner.RecordNotFound {
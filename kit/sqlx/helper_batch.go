@@ -0,0 +1,238 @@
+package sqlx
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/saitofun/qkit/base/types"
+	"github.com/saitofun/qkit/kit/sqlx/builder"
+)
+
+// MaxBatchParams limits parameters of a batch statement, postgres supports at
+// most 65535 parameters in one statement
+var MaxBatchParams = 65535
+
+// BatchInsertToDB inserts models by multi-row insert statements chunked under
+// MaxBatchParams in transaction. models are grouped by their none zero fields,
+// each group is inserted by its own columns, so zero fields are filled by
+// column default.
+func BatchInsertToDB(db DBExecutor, models []builder.Model, additions ...builder.Addition) error {
+	return batchInsertToDB(db, models, func(*builder.Columns) []builder.Addition {
+		return additions
+	})
+}
+
+func batchInsertToDB(db DBExecutor, models []builder.Model, additionsOf func(cols *builder.Columns) []builder.Addition) error {
+	if len(models) == 0 {
+		return nil
+	}
+
+	t := db.T(models[0])
+	tasks := make([]Task, 0)
+	for _, g := range batchGroups(t, models) {
+		cols, rows, additions := g.cols, g.rows, additionsOf(g.cols)
+		tasks = append(tasks, chunkTasks(len(rows), cols.Len(), func(from, to int) Task {
+			return func(db DBExecutor) error {
+				values := make([]interface{}, 0, (to-from)*cols.Len())
+				for _, row := range rows[from:to] {
+					values = append(values, row...)
+				}
+				_, err := db.Exec(builder.Insert().Into(t, additions...).Values(cols, values...))
+				return err
+			}
+		})...)
+	}
+	return NewTasks(db).With(tasks...).Do()
+}
+
+// BatchUpsertToDB inserts models like BatchInsertToDB, and updates inserted
+// columns of each group when conflict on unique index named conflict. fields
+// in excludes are not updated, eg: CreatedAt
+func BatchUpsertToDB(db DBExecutor, models []builder.Model, conflict string, excludes ...string) error {
+	if len(models) == 0 {
+		return nil
+	}
+
+	t := db.T(models[0])
+	key := t.Keys.Key(conflict)
+	if key == nil || !key.IsUnique {
+		return errors.Errorf("unique index %s of %s is not defined", conflict, t.Name)
+	}
+	conflictCols := t.MustColsByFieldNames(key.Def.FieldNames...)
+
+	skips := builder.ToMap(excludes)
+	for _, fn := range key.Def.FieldNames {
+		skips[fn] = true
+	}
+
+	return batchInsertToDB(db, models, func(cols *builder.Columns) []builder.Addition {
+		assignments := make([]*builder.Assignment, 0, cols.Len())
+		cols.Range(func(col *builder.Column, _ int) {
+			if !skips[col.FieldName] {
+				assignments = append(assignments, col.ValueBy(builder.Expr("EXCLUDED."+col.Name)))
+			}
+		})
+		if len(assignments) == 0 {
+			return []builder.Addition{builder.OnConflict(conflictCols).DoNothing()}
+		}
+		return []builder.Addition{builder.OnConflict(conflictCols).DoUpdateSet(assignments...)}
+	})
+}
+
+// BatchExistedByIndex reports if rows having the same values of unique index
+// named index with models are existed, soft deleted rows included. eg: to
+// tell records going to be updated from created ones before BatchUpsertToDB
+func BatchExistedByIndex(db DBExecutor, models []builder.Model, index string) ([]bool, error) {
+	existed := make([]bool, len(models))
+	if len(models) == 0 {
+		return existed, nil
+	}
+
+	t := db.T(models[0])
+	key := t.Keys.Key(index)
+	if key == nil || !key.IsUnique {
+		return nil, errors.Errorf("unique index %s of %s is not defined", index, t.Name)
+	}
+	fns := key.Def.FieldNames
+	cols := t.MustColsByFieldNames(fns...)
+
+	keyOf := func(v interface{}) string {
+		fvs := builder.FieldValueFromStruct(v, fns)
+		vs := make([]interface{}, 0, len(fns))
+		for _, fn := range fns {
+			fv := fvs[fn]
+			if valuer, ok := fv.(driver.Valuer); ok {
+				fv, _ = valuer.Value()
+			}
+			vs = append(vs, fv)
+		}
+		return fmt.Sprintf("%v", vs)
+	}
+
+	found := map[string]bool{}
+	typ := reflect.Indirect(reflect.ValueOf(models[0])).Type()
+	tasks := chunkTasks(len(models), len(fns), func(from, to int) Task {
+		return func(db DBExecutor) error {
+			conds := make([]builder.SqlCondition, 0, to-from)
+			for _, m := range models[from:to] {
+				fvs := builder.FieldValueFromStruct(m, fns)
+				eqs := make([]builder.SqlCondition, 0, len(fns))
+				cols.Range(func(col *builder.Column, _ int) {
+					eqs = append(eqs, col.Eq(fvs[col.FieldName]))
+				})
+				conds = append(conds, builder.And(eqs...))
+			}
+			rv := reflect.New(reflect.SliceOf(typ))
+			err := db.QueryAndScan(
+				builder.Select(cols).From(t, builder.Where(builder.Or(conds...)), builder.WithDeleted()),
+				rv.Interface(),
+			)
+			if err != nil {
+				return err
+			}
+			for i := 0; i < rv.Elem().Len(); i++ {
+				found[keyOf(rv.Elem().Index(i).Addr().Interface())] = true
+			}
+			return nil
+		}
+	})
+	for _, task := range tasks {
+		if err := task(db); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, m := range models {
+		existed[i] = found[keyOf(m)]
+	}
+	return existed, nil
+}
+
+// BatchDeleteByValues deletes rows of model whose field is in values, chunked
+// under MaxBatchParams in transaction. rows of table with soft delete policy
+// are soft deleted by setting the soft delete field to current time. rows are
+// not loaded, so delete hooks are not called
+func BatchDeleteByValues(db DBExecutor, m builder.Model, field string, values []interface{}) error {
+	t := db.T(m)
+	col := t.ColByFieldName(field)
+	if col == nil {
+		return errors.Errorf("field %s of %s is not defined", field, t.Name)
+	}
+
+	return NewTasks(db).With(
+		chunkTasks(len(values), 1, func(from, to int) Task {
+			return func(db DBExecutor) error {
+				cond := col.In(values[from:to]...)
+				if deleted := t.ColByFieldName(t.SoftDelete); deleted != nil {
+					_, err := db.Exec(builder.Update(t).Where(cond).Set(
+						deleted.ValueBy(types.AsTimestamp(time.Now())),
+					))
+					return err
+				}
+				_, err := db.Exec(builder.Delete().From(t, builder.Where(cond)))
+				return err
+			}
+		})...,
+	).Do()
+}
+
+type batchGroup struct {
+	cols *builder.Columns
+	rows [][]interface{}
+}
+
+// batchGroups groups models by none zero fields in order of first appearance
+func batchGroups(t *builder.Table, models []builder.Model) []*batchGroup {
+	groups := make([]*batchGroup, 0)
+	indexes := map[string]int{}
+
+	for _, m := range models {
+		fvs := FieldValuesFromModel(t, m)
+		names := make([]string, 0, len(fvs))
+		for fn := range fvs {
+			names = append(names, fn)
+		}
+		sort.Strings(names)
+
+		key := strings.Join(names, ",")
+		i, ok := indexes[key]
+		if !ok {
+			i = len(groups)
+			indexes[key] = i
+			groups = append(groups, &batchGroup{cols: t.MustColsByFieldNames(names...)})
+		}
+
+		g := groups[i]
+		row := make([]interface{}, 0, g.cols.Len())
+		g.cols.Range(func(col *builder.Column, _ int) {
+			row = append(row, fvs[col.FieldName])
+		})
+		g.rows = append(g.rows, row)
+	}
+	return groups
+}
+
+func chunkTasks(total, paramsPerRow int, task func(from, to int) Task) []Task {
+	if paramsPerRow < 1 {
+		paramsPerRow = 1
+	}
+	size := MaxBatchParams / paramsPerRow
+	if size < 1 {
+		size = 1
+	}
+	tasks := make([]Task, 0, total/size+1)
+	for from := 0; from < total; from += size {
+		to := from + size
+		if to > total {
+			to = total
+		}
+		tasks = append(tasks, task(from, to))
+	}
+	return tasks
+}
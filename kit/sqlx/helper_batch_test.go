@@ -0,0 +1,134 @@
+package sqlx_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/saitofun/qkit/base/types"
+	"github.com/saitofun/qkit/kit/sqlx"
+	"github.com/saitofun/qkit/kit/sqlx/builder"
+	"github.com/saitofun/qkit/kit/sqlx/driver/sqlite"
+	"github.com/saitofun/qkit/kit/sqlx/migration"
+)
+
+type Item struct {
+	ID    uint64 `db:"f_id,autoincrement"`
+	Name  string `db:"f_name,size=64,default=''"`
+	Price int64  `db:"f_price,default='0'"`
+	Stock int64  `db:"f_stock,default='0'"`
+	Rank  int64  `db:"f_rank,default='1'"`
+}
+
+func (*Item) TableName() string { return "t_item" }
+
+func (*Item) PrimaryKey() []string { return []string{"ID"} }
+
+func (*Item) UniqueIndexes() builder.Indexes { return builder.Indexes{"ui_name": {"Name"}} }
+
+type SoftItem struct {
+	ID        uint64          `db:"f_id,autoincrement"`
+	Name      string          `db:"f_name,size=64,default=''"`
+	DeletedAt types.Timestamp `db:"f_deleted_at,default='0'"`
+}
+
+func (*SoftItem) TableName() string { return "t_soft_item" }
+
+func (*SoftItem) PrimaryKey() []string { return []string{"ID"} }
+
+func (*SoftItem) SoftDeleteFieldName() string { return "DeletedAt" }
+
+func TestBatch(t *testing.T) {
+	d := sqlx.NewDatabase("test_batch")
+	db := d.OpenDB(&sqlite.Connector{})
+	tbl := d.Register(&Item{})
+	softTbl := d.Register(&SoftItem{})
+	NewWithT(t).Expect(migration.Migrate(db, nil)).To(BeNil())
+
+	maxBatchParams := sqlx.MaxBatchParams
+	sqlx.MaxBatchParams = 5 // 2 rows of 2 columns each statement
+	defer func() { sqlx.MaxBatchParams = maxBatchParams }()
+
+	list := func() []Item {
+		items := make([]Item, 0)
+		err := db.QueryAndScan(
+			builder.Select(nil).From(tbl, builder.OrderBy(builder.AscOrder(tbl.ColByFieldName("ID")))),
+			&items,
+		)
+		NewWithT(t).Expect(err).To(BeNil())
+		return items
+	}
+
+	t.Run("Insert", func(t *testing.T) {
+		models := []builder.Model{
+			&Item{Name: "a", Price: 1},
+			&Item{Name: "b", Price: 2, Rank: 2},
+			&Item{Name: "c"},
+		}
+		NewWithT(t).Expect(sqlx.BatchInsertToDB(db, models)).To(BeNil())
+
+		items := list()
+		NewWithT(t).Expect(items).To(HaveLen(3))
+		for i := range items {
+			items[i].ID = 0
+		}
+		// zero fields are filled by column default
+		NewWithT(t).Expect(items).To(ConsistOf(
+			Item{Name: "a", Price: 1, Rank: 1},
+			Item{Name: "b", Price: 2, Rank: 2},
+			Item{Name: "c", Rank: 1},
+		))
+	})
+
+	t.Run("Upsert", func(t *testing.T) {
+		models := []builder.Model{
+			&Item{Name: "a", Price: 10, Stock: 1},
+			&Item{Name: "d", Price: 4, Stock: 1},
+		}
+		NewWithT(t).Expect(sqlx.BatchUpsertToDB(db, models, "ui_name", "Stock")).To(BeNil())
+		items := list()
+		for i := range items {
+			items[i].ID = 0
+		}
+		NewWithT(t).Expect(items).To(ConsistOf(
+			Item{Name: "a", Price: 10, Rank: 1},
+			Item{Name: "b", Price: 2, Rank: 2},
+			Item{Name: "c", Rank: 1},
+			Item{Name: "d", Price: 4, Stock: 1, Rank: 1},
+		))
+	})
+
+	t.Run("Existed", func(t *testing.T) {
+		models := []builder.Model{&Item{Name: "a"}, &Item{Name: "e"}, &Item{Name: "d"}}
+		existed, err := sqlx.BatchExistedByIndex(db, models, "ui_name")
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(existed).To(Equal([]bool{true, false, true}))
+
+		_, err = sqlx.BatchExistedByIndex(db, models, "undefined")
+		NewWithT(t).Expect(err).NotTo(BeNil())
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		err := sqlx.BatchDeleteByValues(db, &Item{}, "ID", []interface{}{1, 2, 3, 4, 5, 6})
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(list()).To(BeEmpty())
+	})
+
+	t.Run("SoftDelete", func(t *testing.T) {
+		models := []builder.Model{&SoftItem{Name: "a"}, &SoftItem{Name: "b"}}
+		NewWithT(t).Expect(sqlx.BatchInsertToDB(db, models)).To(BeNil())
+
+		err := sqlx.BatchDeleteByValues(db, &SoftItem{}, "Name", []interface{}{"a"})
+		NewWithT(t).Expect(err).To(BeNil())
+
+		alive := make([]SoftItem, 0)
+		NewWithT(t).Expect(db.QueryAndScan(builder.Select(nil).From(softTbl), &alive)).To(BeNil())
+		NewWithT(t).Expect(alive).To(HaveLen(1))
+		NewWithT(t).Expect(alive[0].Name).To(Equal("b"))
+
+		all := make([]SoftItem, 0)
+		err = db.QueryAndScan(builder.Select(nil).From(softTbl, builder.WithDeleted()), &all)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(all).To(HaveLen(2))
+	})
+}
@@ -0,0 +1,87 @@
+package sqlx
+
+import (
+	"context"
+
+	"github.com/saitofun/qkit/kit/sqlx/builder"
+)
+
+// lifecycle hooks of model called by generated CRUD methods in the same
+// transaction with the write, the operation is aborted and rolled back if any
+// hook returns error. BatchCreate calls create hooks of each record, and
+// BatchUpsert calls them of records created only. methods operate on records
+// by condition or key values without loading them, which are SoftDelete,
+// Restore, Purge and BatchDeleteByXXXs, do not call hooks
+
+type BeforeCreateHook interface {
+	BeforeCreate(ctx context.Context, db DBExecutor) error
+}
+
+type AfterCreateHook interface {
+	AfterCreate(ctx context.Context, db DBExecutor) error
+}
+
+type BeforeUpdateHook interface {
+	// BeforeUpdate fvs are field values going to be updated
+	BeforeUpdate(ctx context.Context, db DBExecutor, fvs builder.FieldValues) error
+}
+
+type AfterUpdateHook interface {
+	AfterUpdate(ctx context.Context, db DBExecutor) error
+}
+
+type BeforeDeleteHook interface {
+	BeforeDelete(ctx context.Context, db DBExecutor) error
+}
+
+type AfterDeleteHook interface {
+	AfterDelete(ctx context.Context, db DBExecutor) error
+}
+
+// BeforeCreate calls BeforeCreateHook if m implemented
+func BeforeCreate(db DBExecutor, m builder.Model) error {
+	if hook, ok := m.(BeforeCreateHook); ok {
+		return hook.BeforeCreate(db.Context(), db)
+	}
+	return nil
+}
+
+// AfterCreate calls AfterCreateHook if m implemented
+func AfterCreate(db DBExecutor, m builder.Model) error {
+	if hook, ok := m.(AfterCreateHook); ok {
+		return hook.AfterCreate(db.Context(), db)
+	}
+	return nil
+}
+
+// BeforeUpdate calls BeforeUpdateHook if m implemented
+func BeforeUpdate(db DBExecutor, m builder.Model, fvs builder.FieldValues) error {
+	if hook, ok := m.(BeforeUpdateHook); ok {
+		return hook.BeforeUpdate(db.Context(), db, fvs)
+	}
+	return nil
+}
+
+// AfterUpdate calls AfterUpdateHook if m implemented
+func AfterUpdate(db DBExecutor, m builder.Model) error {
+	if hook, ok := m.(AfterUpdateHook); ok {
+		return hook.AfterUpdate(db.Context(), db)
+	}
+	return nil
+}
+
+// BeforeDelete calls BeforeDeleteHook if m implemented
+func BeforeDelete(db DBExecutor, m builder.Model) error {
+	if hook, ok := m.(BeforeDeleteHook); ok {
+		return hook.BeforeDelete(db.Context(), db)
+	}
+	return nil
+}
+
+// AfterDelete calls AfterDeleteHook if m implemented
+func AfterDelete(db DBExecutor, m builder.Model) error {
+	if hook, ok := m.(AfterDeleteHook); ok {
+		return hook.AfterDelete(db.Context(), db)
+	}
+	return nil
+}
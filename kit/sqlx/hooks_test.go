@@ -0,0 +1,58 @@
+package sqlx_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+
+	"github.com/saitofun/qkit/kit/sqlx"
+	"github.com/saitofun/qkit/kit/sqlx/builder"
+	"github.com/saitofun/qkit/kit/sqlx/driver/sqlite"
+)
+
+type HookedItem struct {
+	Name  string
+	calls []string
+}
+
+func (*HookedItem) TableName() string { return "t_hooked_item" }
+
+func (i *HookedItem) BeforeCreate(_ context.Context, _ sqlx.DBExecutor) error {
+	if i.Name == "" {
+		return errors.New("name required")
+	}
+	i.calls = append(i.calls, "BeforeCreate")
+	return nil
+}
+
+func (i *HookedItem) BeforeUpdate(_ context.Context, _ sqlx.DBExecutor, fvs builder.FieldValues) error {
+	fvs["Name"] = i.Name + "!"
+	i.calls = append(i.calls, "BeforeUpdate")
+	return nil
+}
+
+func (i *HookedItem) AfterDelete(_ context.Context, _ sqlx.DBExecutor) error {
+	i.calls = append(i.calls, "AfterDelete")
+	return nil
+}
+
+func TestHooks(t *testing.T) {
+	db := sqlx.NewDatabase("test_hooks").OpenDB(&sqlite.Connector{})
+
+	item := &HookedItem{}
+	NewWithT(t).Expect(sqlx.BeforeCreate(db, item)).NotTo(BeNil())
+
+	item.Name = "a"
+	fvs := builder.FieldValues{}
+	NewWithT(t).Expect(sqlx.BeforeCreate(db, item)).To(BeNil())
+	NewWithT(t).Expect(sqlx.AfterCreate(db, item)).To(BeNil())
+	NewWithT(t).Expect(sqlx.BeforeUpdate(db, item, fvs)).To(BeNil())
+	NewWithT(t).Expect(sqlx.AfterUpdate(db, item)).To(BeNil())
+	NewWithT(t).Expect(sqlx.BeforeDelete(db, item)).To(BeNil())
+	NewWithT(t).Expect(sqlx.AfterDelete(db, item)).To(BeNil())
+
+	NewWithT(t).Expect(fvs).To(Equal(builder.FieldValues{"Name": "a!"}))
+	NewWithT(t).Expect(item.calls).To(Equal([]string{"BeforeCreate", "BeforeUpdate", "AfterDelete"}))
+}
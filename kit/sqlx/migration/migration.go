@@ -19,18 +19,51 @@ func OutputFromContext(ctx context.Context) io.Writer {
 	return nil
 }
 
-func MustMigrate(db sqlx.DBExecutor, w io.Writer) {
-	if err := Migrate(db, w); err != nil {
+func MustMigrate(db sqlx.DBExecutor, w io.Writer, migrations ...*Migration) {
+	if err := Migrate(db, w, migrations...); err != nil {
 		panic(err)
 	}
 }
 
-func Migrate(db sqlx.DBExecutor, output io.Writer) error {
+// Migrate applies versioned migrations marked BeforeDiff, table diff of
+// dialect, then the other versioned migrations in order of version. if output
+// is not nil, sql is written to output instead of executing. if context of db
+// is set by RefuseDestructive, nothing is applied when table diff contains
+// destructive steps not allowed
+func Migrate(db sqlx.DBExecutor, output io.Writer, migrations ...*Migration) error {
+	if allowed, ok := allowedFromContext(db.Context()); ok {
+		p, err := Plan(db)
+		if err != nil {
+			return err
+		}
+		if err = p.Check(allowed...); err != nil {
+			return err
+		}
+	}
+
 	ctx := contextx.WithValue(db.Context(), key{}, output)
 
+	var v *versioned
+	if len(migrations) > 0 {
+		var err error
+		if v, err = newVersioned(db, output, migrations); err != nil {
+			return err
+		}
+		if err = v.up(func(m *Migration) bool { return m.BeforeDiff }); err != nil {
+			return err
+		}
+	}
+
 	if err := db.(sqlx.Migrator).Migrate(ctx, db); err != nil {
 		return err
 	}
+
+	if v != nil {
+		if err := v.up(func(m *Migration) bool { return !m.BeforeDiff }); err != nil {
+			return err
+		}
+	}
+
 	if output == nil {
 		if err := SyncEnum(db); err != nil {
 			return err
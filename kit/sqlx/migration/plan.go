@@ -0,0 +1,121 @@
+package migration
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/saitofun/qkit/kit/sqlx"
+	"github.com/saitofun/qkit/kit/sqlx/builder"
+	"github.com/saitofun/qkit/x/contextx"
+)
+
+var ErrDestructiveMigration = errors.New("destructive migration refused")
+
+// MigrationPlan steps of table diff, collected by Migrator
+type MigrationPlan struct {
+	Steps []*builder.MigrationStep
+}
+
+func (p *MigrationPlan) Add(steps ...*builder.MigrationStep) {
+	p.Steps = append(p.Steps, steps...)
+}
+
+func (p *MigrationPlan) Destructive() []*builder.MigrationStep {
+	return p.filter(func(s *builder.MigrationStep) bool { return s.Destructive })
+}
+
+func (p *MigrationPlan) LockHeavy() []*builder.MigrationStep {
+	return p.filter(func(s *builder.MigrationStep) bool { return s.LockHeavy })
+}
+
+func (p *MigrationPlan) filter(fn func(s *builder.MigrationStep) bool) []*builder.MigrationStep {
+	list := make([]*builder.MigrationStep, 0)
+	for _, s := range p.Steps {
+		if fn(s) {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// Check returns ErrDestructiveMigration if any destructive step is not
+// allowed. allowed is matched with subject of step as `table.target`, `table`
+// or `*` for all
+func (p *MigrationPlan) Check(allowed ...string) error {
+	refused := make([]string, 0)
+	for _, s := range p.Destructive() {
+		if !isAllowed(s, allowed) {
+			refused = append(refused, s.String())
+		}
+	}
+	if len(refused) > 0 {
+		return errors.Wrap(ErrDestructiveMigration, strings.Join(refused, "; "))
+	}
+	return nil
+}
+
+func isAllowed(s *builder.MigrationStep, allowed []string) bool {
+	for _, a := range allowed {
+		if a == "*" || a == s.Table || a == s.Subject() {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteTo writes steps and sql of plan
+func (p *MigrationPlan) WriteTo(w io.Writer) (int64, error) {
+	b := strings.Builder{}
+	for _, s := range p.Steps {
+		b.WriteString("-- ")
+		b.WriteString(s.String())
+		b.WriteString("\n")
+		b.WriteString(builder.ResolveExpr(s.Expr).Query())
+		b.WriteString("\n")
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+type keyPlan struct{}
+
+// ContextWithPlan sets plan to collect steps when migrate
+func ContextWithPlan(ctx context.Context, p *MigrationPlan) context.Context {
+	return contextx.WithValue(ctx, keyPlan{}, p)
+}
+
+func PlanFromContext(ctx context.Context) *MigrationPlan {
+	if p, ok := ctx.Value(keyPlan{}).(*MigrationPlan); ok {
+		return p
+	}
+	return nil
+}
+
+type keyAllowed struct{}
+
+// RefuseDestructive returns context makes Migrate refuse destructive steps of
+// table diff unless allowed. see MigrationPlan.Check
+func RefuseDestructive(ctx context.Context, allowed ...string) context.Context {
+	if allowed == nil {
+		allowed = []string{}
+	}
+	return contextx.WithValue(ctx, keyAllowed{}, allowed)
+}
+
+func allowedFromContext(ctx context.Context) ([]string, bool) {
+	allowed, ok := ctx.Value(keyAllowed{}).([]string)
+	return allowed, ok
+}
+
+// Plan returns steps of table diff without applying
+func Plan(db sqlx.DBExecutor) (*MigrationPlan, error) {
+	p := &MigrationPlan{}
+	ctx := contextx.WithValue(ContextWithPlan(db.Context(), p), key{}, io.Discard)
+	if err := db.(sqlx.Migrator).Migrate(ctx, db); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
@@ -0,0 +1,317 @@
+package migration
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/saitofun/qkit/kit/sqlx"
+	"github.com/saitofun/qkit/kit/sqlx/builder"
+)
+
+// Migration is a versioned migration, defined in go or loaded from sql files
+type Migration struct {
+	Version int64
+	Name    string
+	// BeforeDiff if migration should be applied before table diff, eg: column
+	// renaming. by default versioned migrations are applied after table diff,
+	// eg: data backfilling
+	BeforeDiff bool
+	Up         sqlx.Task
+	Down       sqlx.Task
+	// Checksum of migration, recorded in history and verified when migrate.
+	// it is computed from content of sql migration, and from version and name
+	// of go defined migration, whose changes should be tracked by WithChecksum
+	Checksum string
+
+	up, down string // raw sql
+}
+
+// NewMigration creates go defined migration
+func NewMigration(version int64, name string, up, down sqlx.Task) *Migration {
+	m := &Migration{Version: version, Name: name, Up: up, Down: down}
+	m.Checksum = Checksum(m.String())
+	return m
+}
+
+// WithChecksum sets checksum of go defined migration by contents identifying
+// it, eg: sql statements it executes or a revision bumped when it changed
+func (m *Migration) WithChecksum(contents ...string) *Migration {
+	m.Checksum = Checksum(strings.Join(append([]string{m.String()}, contents...), "\n"))
+	return m
+}
+
+// NewSqlMigration creates migration by sql statements
+func NewSqlMigration(version int64, name string, up, down string) *Migration {
+	m := &Migration{
+		Version:  version,
+		Name:     name,
+		Checksum: Checksum(up),
+		up:       up,
+		down:     down,
+	}
+	m.Up = execRaw(up)
+	if down != "" {
+		m.Down = execRaw(down)
+	}
+	return m
+}
+
+func (m *Migration) String() string { return fmt.Sprintf("%d_%s", m.Version, m.Name) }
+
+func Checksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// execRaw executes query verbatim, `?` in query is not treated as holder
+func execRaw(query string) sqlx.Task {
+	return func(db sqlx.DBExecutor) error {
+		_, err := db.ExecContext(db.Context(), query)
+		return err
+	}
+}
+
+// SqlMigrationHistory history of applied versioned migrations
+type SqlMigrationHistory struct {
+	Version   int64  `db:"f_version"`
+	Name      string `db:"f_name,size=255"`
+	Checksum  string `db:"f_checksum,size=64,default=''"`
+	AppliedAt int64  `db:"f_applied_at"`
+}
+
+func (*SqlMigrationHistory) TableName() string { return "t_sql_migration_history" }
+
+func (*SqlMigrationHistory) PrimaryKey() []string { return []string{"Version"} }
+
+// MigrationStatus status of versioned migration
+type MigrationStatus struct {
+	*Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+var (
+	ErrChecksumMismatch  = errors.New("migration checksum mismatch")
+	ErrDuplicateVersion  = errors.New("migration version duplicated")
+	ErrMissingMigration  = errors.New("applied migration missing")
+	ErrIrreversible      = errors.New("migration irreversible")
+	ErrInvalidMigrations = errors.New("invalid migration")
+	ErrInvalidSteps      = errors.New("invalid migration steps")
+)
+
+// Up applies all pending migrations ordered by version
+func Up(db sqlx.DBExecutor, output io.Writer, migrations ...*Migration) error {
+	v, err := newVersioned(db, output, migrations)
+	if err != nil {
+		return err
+	}
+	return v.up(func(*Migration) bool { return true })
+}
+
+// Down reverts the last `steps` applied migrations
+func Down(db sqlx.DBExecutor, steps int, migrations ...*Migration) error {
+	if steps < 0 {
+		return errors.Wrapf(ErrInvalidSteps, "%d", steps)
+	}
+	v, err := newVersioned(db, nil, migrations)
+	if err != nil {
+		return err
+	}
+	return v.down(steps)
+}
+
+// Status returns status of migrations ordered by version
+func Status(db sqlx.DBExecutor, migrations ...*Migration) ([]*MigrationStatus, error) {
+	v, err := newVersioned(db, nil, migrations)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*MigrationStatus, 0, len(v.migrations))
+	for _, m := range v.migrations {
+		s := &MigrationStatus{Migration: m}
+		if h, ok := v.applied[m.Version]; ok {
+			s.Applied, s.AppliedAt = true, time.Unix(h.AppliedAt, 0)
+		}
+		list = append(list, s)
+	}
+	return list, nil
+}
+
+func newVersioned(db sqlx.DBExecutor, output io.Writer, migrations []*Migration) (*versioned, error) {
+	list := make([]*Migration, len(migrations))
+	copy(list, migrations)
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+
+	for i, m := range list {
+		if m.Up == nil {
+			return nil, errors.Wrapf(ErrInvalidMigrations, "%s: missing up", m)
+		}
+		if i > 0 && list[i-1].Version == m.Version {
+			return nil, errors.Wrapf(ErrDuplicateVersion, "%s and %s", list[i-1], m)
+		}
+	}
+
+	v := &versioned{
+		db:         db,
+		output:     output,
+		tbl:        builder.TableFromModel(&SqlMigrationHistory{}).WithSchema(db.D().Schema),
+		migrations: list,
+		applied:    map[int64]*SqlMigrationHistory{},
+	}
+	if err := v.init(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+type versioned struct {
+	db         sqlx.DBExecutor
+	output     io.Writer
+	tbl        *builder.Table
+	migrations []*Migration
+	applied    map[int64]*SqlMigrationHistory
+}
+
+func (v *versioned) init() error {
+	if v.output == nil {
+		if schema := v.db.D().Schema; schema != "" {
+			if _, err := v.db.Exec(v.db.Dialect().CreateSchema(schema)); err != nil {
+				return err
+			}
+		}
+		for _, expr := range v.db.Dialect().CreateTableIsNotExists(v.tbl) {
+			if _, err := v.db.Exec(expr); err != nil {
+				return err
+			}
+		}
+	}
+
+	histories := make([]SqlMigrationHistory, 0)
+	err := v.db.QueryAndScan(builder.Select(nil).From(v.tbl), &histories)
+	if err != nil {
+		if v.output != nil {
+			// history table may be not created when dry run
+			return nil
+		}
+		return err
+	}
+
+	for i := range histories {
+		h := &histories[i]
+		v.applied[h.Version] = h
+	}
+	for _, m := range v.migrations {
+		h, ok := v.applied[m.Version]
+		// checksum of go defined migration applied by early versions is empty
+		if ok && h.Checksum != "" && h.Checksum != m.Checksum {
+			return errors.Wrapf(ErrChecksumMismatch, "%s: applied %s, current %s", m, h.Checksum, m.Checksum)
+		}
+	}
+	return nil
+}
+
+func (v *versioned) pending(filter func(*Migration) bool) []*Migration {
+	list := make([]*Migration, 0)
+	for _, m := range v.migrations {
+		if _, ok := v.applied[m.Version]; !ok && filter(m) {
+			list = append(list, m)
+		}
+	}
+	return list
+}
+
+func (v *versioned) up(filter func(*Migration) bool) error {
+	for _, m := range v.pending(filter) {
+		if v.output != nil {
+			v.print(m, m.up)
+			continue
+		}
+
+		h := &SqlMigrationHistory{
+			Version:   m.Version,
+			Name:      m.Name,
+			Checksum:  m.Checksum,
+			AppliedAt: time.Now().Unix(),
+		}
+		cols, vals := v.tbl.ColumnsAndValuesByFieldValues(
+			builder.FieldValueFromStructByNoneZero(h, "Checksum"),
+		)
+
+		err := sqlx.NewTasks(v.db).With(
+			m.Up,
+			func(db sqlx.DBExecutor) error {
+				_, err := db.Exec(builder.Insert().Into(v.tbl).Values(cols, vals...))
+				return err
+			},
+		).Do()
+		if err != nil {
+			return errors.Wrapf(err, "migrate up %s", m)
+		}
+		v.applied[m.Version] = h
+	}
+	return nil
+}
+
+func (v *versioned) down(steps int) error {
+	applied := make([]int64, 0, len(v.applied))
+	for version := range v.applied {
+		applied = append(applied, version)
+	}
+	sort.Slice(applied, func(i, j int) bool { return applied[i] > applied[j] })
+
+	if steps < 0 {
+		return errors.Wrapf(ErrInvalidSteps, "%d", steps)
+	}
+	if steps < len(applied) {
+		applied = applied[0:steps]
+	}
+
+	for _, version := range applied {
+		var m *Migration
+		for _, mi := range v.migrations {
+			if mi.Version == version {
+				m = mi
+				break
+			}
+		}
+		if m == nil {
+			return errors.Wrapf(ErrMissingMigration, "version %d", version)
+		}
+		if m.Down == nil {
+			return errors.Wrapf(ErrIrreversible, "%s", m)
+		}
+
+		err := sqlx.NewTasks(v.db).With(
+			m.Down,
+			func(db sqlx.DBExecutor) error {
+				_, err := db.Exec(builder.Delete().From(
+					v.tbl,
+					builder.Where(v.tbl.ColByFieldName("Version").Eq(m.Version)),
+				))
+				return err
+			},
+		).Do()
+		if err != nil {
+			return errors.Wrapf(err, "migrate down %s", m)
+		}
+		delete(v.applied, version)
+	}
+	return nil
+}
+
+func (v *versioned) print(m *Migration, query string) {
+	_, _ = fmt.Fprintf(v.output, "-- migration %s\n", m)
+	if query == "" {
+		_, _ = io.WriteString(v.output, "-- defined in go\n")
+		return
+	}
+	_, _ = io.WriteString(v.output, query)
+	_, _ = io.WriteString(v.output, "\n")
+}
@@ -0,0 +1,113 @@
+package migration
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/saitofun/qkit/x/stringsx"
+)
+
+// DirectiveBeforeDiff marks sql migration should be applied before table diff
+const DirectiveBeforeDiff = "-- +migrate BeforeDiff"
+
+// VersionLayout version of created migration formatted by time
+const VersionLayout = "20060102150405"
+
+var reSqlMigrationFile = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// LoadFS loads sql migrations from files in root of fsys, which named as
+// `{version}_{name}.up.sql` and `{version}_{name}.down.sql`. eg: embed.FS
+func LoadFS(fsys fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	type pair struct {
+		version  int64
+		name     string
+		up, down *string
+	}
+	pairs := map[int64]*pair{}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		matched := reSqlMigrationFile.FindStringSubmatch(entry.Name())
+		if matched == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(matched[1], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(ErrInvalidMigrations, "%s: %v", entry.Name(), err)
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		p, ok := pairs[version]
+		if !ok {
+			p = &pair{version: version, name: matched[2]}
+			pairs[version] = p
+		}
+		if p.name != matched[2] {
+			return nil, errors.Wrapf(ErrDuplicateVersion, "%d_%s and %s", version, p.name, entry.Name())
+		}
+		query := string(content)
+		if matched[3] == "up" {
+			p.up = &query
+		} else {
+			p.down = &query
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(pairs))
+	for _, p := range pairs {
+		if p.up == nil {
+			return nil, errors.Wrapf(ErrInvalidMigrations, "%d_%s: missing up file", p.version, p.name)
+		}
+		down := ""
+		if p.down != nil {
+			down = *p.down
+		}
+		m := NewSqlMigration(p.version, p.name, *p.up, down)
+		m.BeforeDiff = strings.Contains(*p.up, DirectiveBeforeDiff)
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Create creates up and down sql migration files in dir, version is formatted
+// from current time
+func Create(dir, name string) (up string, down string, err error) {
+	if err = os.MkdirAll(dir, os.ModePerm); err != nil {
+		return
+	}
+
+	prefix := filepath.Join(dir, fmt.Sprintf(
+		"%s_%s",
+		time.Now().UTC().Format(VersionLayout),
+		stringsx.LowerSnakeCase(name),
+	))
+	up, down = prefix+".up.sql", prefix+".down.sql"
+
+	if err = os.WriteFile(up, []byte("-- "+name+" up\n"), 0666); err != nil {
+		return
+	}
+	err = os.WriteFile(down, []byte("-- "+name+" down\n"), 0666)
+	return
+}
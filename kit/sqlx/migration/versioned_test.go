@@ -0,0 +1,60 @@
+package migration_test
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/saitofun/qkit/kit/sqlx/migration"
+)
+
+func TestLoadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"20221018000000_rename_name.up.sql": {
+			Data: []byte(migration.DirectiveBeforeDiff + "\nALTER TABLE t_user RENAME f_name TO f_nickname;"),
+		},
+		"20221018000000_rename_name.down.sql": {
+			Data: []byte("ALTER TABLE t_user RENAME f_nickname TO f_name;"),
+		},
+		"20221019000000_backfill.up.sql": {
+			Data: []byte("UPDATE t_user SET f_nickname = 'x';"),
+		},
+		"README.md": {},
+	}
+
+	migrations, err := migration.LoadFS(fsys)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(migrations).To(HaveLen(2))
+
+	NewWithT(t).Expect(migrations[0].String()).To(Equal("20221018000000_rename_name"))
+	NewWithT(t).Expect(migrations[0].BeforeDiff).To(BeTrue())
+	NewWithT(t).Expect(migrations[0].Down).NotTo(BeNil())
+	NewWithT(t).Expect(migrations[0].Checksum).To(Equal(migration.Checksum(string(fsys["20221018000000_rename_name.up.sql"].Data))))
+
+	NewWithT(t).Expect(migrations[1].BeforeDiff).To(BeFalse())
+	NewWithT(t).Expect(migrations[1].Down).To(BeNil())
+
+	t.Run("MissingUp", func(t *testing.T) {
+		_, err := migration.LoadFS(fstest.MapFS{
+			"1_x.down.sql": {Data: []byte("SELECT 1;")},
+		})
+		NewWithT(t).Expect(err).NotTo(BeNil())
+	})
+}
+
+func TestNewMigration(t *testing.T) {
+	m := migration.NewMigration(20221020000000, "backfill", nil, nil)
+	NewWithT(t).Expect(m.Checksum).To(Equal(migration.Checksum("20221020000000_backfill")))
+
+	checksum := m.Checksum
+	NewWithT(t).Expect(m.WithChecksum("v2").Checksum).NotTo(Equal(checksum))
+}
+
+func TestDown(t *testing.T) {
+	t.Run("NegativeSteps", func(t *testing.T) {
+		err := migration.Down(nil, -1)
+		NewWithT(t).Expect(errors.Is(err, migration.ErrInvalidSteps)).To(BeTrue())
+	})
+}
@@ -0,0 +1,61 @@
+package scanner
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Rows pull-based iterator scans rows one by one to T. the connection is held
+// until rows exhausted or Close called, so Close should always be deferred.
+type Rows[T any] struct {
+	ctx  context.Context
+	rows *sql.Rows
+	v    T
+	err  error
+}
+
+func NewRows[T any](ctx context.Context, rows *sql.Rows) *Rows[T] {
+	return &Rows[T]{ctx: ctx, rows: rows}
+}
+
+// Next scans next row, it returns false and releases connection when rows
+// exhausted, context canceled or failed to scan. Err should be checked after.
+func (r *Rows[T]) Next() bool {
+	if r.rows == nil || r.err != nil {
+		return false
+	}
+	if err := r.ctx.Err(); err != nil {
+		return r.fail(err)
+	}
+	if !r.rows.Next() {
+		return r.fail(r.rows.Err())
+	}
+
+	var v T
+	if err := ScanRows(r.ctx, r.rows, &v); err != nil {
+		return r.fail(err)
+	}
+	r.v = v
+	return true
+}
+
+func (r *Rows[T]) fail(err error) bool {
+	r.err = err
+	if closeErr := r.Close(); r.err == nil {
+		r.err = closeErr
+	}
+	return false
+}
+
+// Value returns value scanned by Next
+func (r *Rows[T]) Value() T { return r.v }
+
+func (r *Rows[T]) Err() error { return r.err }
+
+// Close releases connection, it is safe to be called multiple times
+func (r *Rows[T]) Close() error {
+	if r.rows == nil {
+		return nil
+	}
+	return r.rows.Close()
+}
@@ -211,3 +211,53 @@ func TestScan(t *testing.T) {
 		}))
 	})
 }
+
+func TestRows(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	t.Run("Iterate", func(t *testing.T) {
+		mockRows := mock.NewRows([]string{"f_i", "f_s"})
+		mockRows.AddRow(2, "2")
+		mockRows.AddRow(3, "3")
+
+		_ = mock.ExpectQuery("SELECT .+ from t").WillReturnRows(mockRows)
+
+		rows, err := db.Query("SELECT f_i,f_s from t")
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+
+		iter := NewRows[T](context.Background(), rows)
+		defer iter.Close()
+
+		list := make([]T, 0)
+		for iter.Next() {
+			list = append(list, iter.Value())
+		}
+
+		gomega.NewWithT(t).Expect(iter.Err()).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(list).To(gomega.Equal([]T{
+			{I: 2, S: "2"},
+			{I: 3, S: "3"},
+		}))
+	})
+
+	t.Run("ContextCanceled", func(t *testing.T) {
+		mockRows := mock.NewRows([]string{"f_i", "f_s"})
+		mockRows.AddRow(2, "2")
+		mockRows.AddRow(3, "3")
+
+		_ = mock.ExpectQuery("SELECT .+ from t").WillReturnRows(mockRows)
+
+		rows, err := db.Query("SELECT f_i,f_s from t")
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		iter := NewRows[T](ctx, rows)
+		defer iter.Close()
+
+		gomega.NewWithT(t).Expect(iter.Next()).To(gomega.BeTrue())
+		cancel()
+		gomega.NewWithT(t).Expect(iter.Next()).To(gomega.BeFalse())
+		gomega.NewWithT(t).Expect(iter.Err()).To(gomega.Equal(context.Canceled))
+	})
+}
@@ -1,12 +1,15 @@
 package sqlx
 
 import (
+	"database/sql"
 	"fmt"
 	"runtime/debug"
+	"time"
 
 	"github.com/pkg/errors"
 
 	"github.com/saitofun/qkit/conf/log"
+	"github.com/saitofun/qkit/x/misc/retry"
 )
 
 type Task func(db DBExecutor) error
@@ -29,6 +32,8 @@ func NewTasks(db DBExecutor) *Tasks {
 type Tasks struct {
 	db    DBExecutor
 	tasks []Task
+	opts  *sql.TxOptions
+	retry *retry.Retry
 }
 
 func (tasks Tasks) With(task ...Task) *Tasks {
@@ -36,11 +41,47 @@ func (tasks Tasks) With(task ...Task) *Tasks {
 	return &tasks
 }
 
+// WithTxOptions sets options of transaction started by tasks, eg: isolation
+// level
+func (tasks Tasks) WithTxOptions(opts *sql.TxOptions) *Tasks {
+	tasks.opts = opts
+	return &tasks
+}
+
+// WithRetry sets retry policy of transaction started by tasks. the whole
+// transaction is re-run when failed by error classified retryable by
+// Dialect.IsErrorRetryable. tasks nested in outer transaction are not retried
+func (tasks Tasks) WithRetry(r *retry.Retry) *Tasks {
+	tasks.retry = r
+	return &tasks
+}
+
 func (tasks *Tasks) Do() (err error) {
 	if len(tasks.tasks) == 0 {
 		return nil
 	}
 
+	maybeTx, ok := tasks.db.(TxExecutor)
+	if tasks.retry == nil || tasks.retry.Repeats <= 1 || !ok || maybeTx.IsTx() {
+		return tasks.do()
+	}
+
+	l := log.FromContext(tasks.db.Context())
+
+	for i := 0; ; i++ {
+		err = tasks.do()
+		if err == nil || i+1 >= tasks.retry.Repeats ||
+			!tasks.db.Dialect().IsErrorRetryable(err) {
+			return err
+		}
+		wait := tasks.retry.Wait(i)
+		l.WithValues("attempt", i+1, "wait", wait).
+			Warn(errors.Wrap(err, "TRANSACTION RETRY"))
+		time.Sleep(wait)
+	}
+}
+
+func (tasks *Tasks) do() (err error) {
 	db := tasks.db
 
 	l := log.FromContext(db.Context())
@@ -49,19 +90,21 @@ func (tasks *Tasks) Do() (err error) {
 		inTxScope := false
 
 		if !maybeTx.IsTx() {
-			db, err = maybeTx.Begin()
+			db, err = maybeTx.BeginTx(tasks.opts)
 			if err != nil {
 				return err
 			}
 			maybeTx = db.(TxExecutor)
 			inTxScope = true
+		} else if sp, ok := db.(SavepointExecutor); ok {
+			return tasks.doInSavepoint(db, sp)
 		}
 
 		for _, task := range tasks.tasks {
 			if runErr := task.Run(db); runErr != nil {
 				if inTxScope {
 					// err will bubble up，just handle and rollback in outermost layer
-					l.Error(errors.Wrap(err, "SQL FAILED"))
+					l.Error(errors.Wrap(runErr, "SQL FAILED"))
 					if rollBackErr := maybeTx.Rollback(); rollBackErr != nil {
 						l.Warn(errors.Wrap(rollBackErr, "ROLLBACK FAILED"))
 						err = rollBackErr
@@ -82,3 +125,39 @@ func (tasks *Tasks) Do() (err error) {
 	}
 	return nil
 }
+
+// doInSavepoint runs tasks nested in outer transaction, if any task failed,
+// only changes made by tasks are rolled back and the outer transaction is
+// still available.
+func (tasks *Tasks) doInSavepoint(db DBExecutor, sp SavepointExecutor) error {
+	l := log.FromContext(db.Context())
+
+	name, err := sp.Savepoint()
+	if err != nil {
+		return err
+	}
+
+	for _, task := range tasks.tasks {
+		if runErr := task.Run(db); runErr != nil {
+			l.Error(errors.Wrap(runErr, "SQL FAILED"))
+			if rollBackErr := sp.RollbackToSavepoint(name); rollBackErr != nil {
+				l.Warn(errors.Wrap(rollBackErr, "ROLLBACK TO SAVEPOINT FAILED"))
+				return rollBackErr
+			}
+			return runErr
+		}
+	}
+
+	return sp.ReleaseSavepoint(name)
+}
+
+// AfterCommit registers hook called after the outermost transaction of db
+// committed, eg: publishing events. the hook is called immediately if db is not
+// in transaction, and discarded if transaction or savepoint rolled back.
+func AfterCommit(db DBExecutor, hook func()) {
+	if committer, ok := db.(interface{ AfterCommit(func()) }); ok {
+		committer.AfterCommit(hook)
+		return
+	}
+	hook()
+}
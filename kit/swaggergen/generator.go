@@ -1,3 +1,135 @@
 package swaggergen
 
-// TODO generate from swagger/openapi3.0 spec from httptransport
+import (
+	"encoding/json"
+	"go/types"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/saitofun/qkit/kit/httptransport"
+	"github.com/saitofun/qkit/kit/httptransport/httpx"
+	"github.com/saitofun/qkit/kit/kit"
+	"github.com/saitofun/qkit/kit/oas"
+	"github.com/saitofun/qkit/x/pkgx"
+)
+
+var (
+	PkgKit           = reflect.TypeOf(kit.Router{}).PkgPath()
+	PkgHttpTransport = reflect.TypeOf(httptransport.MetaOperator{}).PkgPath()
+	PkgHttpx         = reflect.TypeOf(httpx.MethodGet{}).PkgPath()
+)
+
+// Filename of generated openapi document, served by httptransport/swagger
+const Filename = "openapi.json"
+
+func New(pkg *pkgx.Pkg) *Generator {
+	doc := oas.NewOpenAPI()
+	doc.Info.Title = filepath.Base(pkg.PkgPath)
+	doc.Info.Version = "0.0.0"
+
+	return &Generator{
+		pkg:       pkg,
+		doc:       doc,
+		routers:   NewRouterScanner(pkg),
+		operators: NewOperatorScanner(pkg, doc),
+	}
+}
+
+type Generator struct {
+	pkg       *pkgx.Pkg
+	doc       *oas.OpenAPI
+	routers   *RouterScanner
+	operators *OperatorScanner
+}
+
+// Scan scans routes registered on root routers named by names, if no name
+// is given `RootRouter` is used
+func (g *Generator) Scan(names ...string) {
+	if len(names) == 0 {
+		names = []string{"RootRouter"}
+	}
+	for _, name := range names {
+		router := g.routers.Router(name)
+		if router == nil {
+			log.Printf("router `%s` not found in %s", name, g.pkg.PkgPath)
+			continue
+		}
+		for _, route := range router.Routes() {
+			g.scanRoute(route)
+		}
+	}
+}
+
+func (g *Generator) scanRoute(route []*RouterOperator) {
+	var (
+		ops      = make([]*Operator, 0, len(route))
+		method   = ""
+		basePath = "/"
+		path     = ""
+	)
+
+	for _, ro := range route {
+		op := g.operators.Operator(ro.Type)
+		if op == nil {
+			continue
+		}
+		if ro.BasePath != "" {
+			basePath = ro.BasePath
+		}
+		if op.BasePath != "" {
+			basePath = op.BasePath
+		}
+		path += ro.Path + op.Path
+		if op.Method != "" {
+			method = op.Method
+		}
+		ops = append(ops, op)
+	}
+
+	if method == "" || len(ops) == 0 {
+		return
+	}
+
+	last := ops[len(ops)-1]
+	operation := &oas.Operation{
+		OperationID: last.ID,
+		Summary:     last.Summary,
+		Description: last.Description,
+		Deprecated:  last.Deprecated,
+	}
+	for _, op := range ops {
+		op.BindParameters(operation)
+	}
+	last.BindResponses(method, operation)
+
+	g.doc.AddOperation(
+		method,
+		oas.PathFromHttpRouter(httprouter.CleanPath(basePath+path)),
+		operation,
+	)
+}
+
+// Doc returns the scanned openapi document
+func (g *Generator) Doc() *oas.OpenAPI { return g.doc }
+
+func (g *Generator) Output(cwd string) {
+	data, err := json.MarshalIndent(g.doc, "", "  ")
+	if err != nil {
+		log.Printf("%s generate failed: %v", Filename, err)
+		return
+	}
+	filename := filepath.Join(cwd, Filename)
+	if err = os.WriteFile(filename, data, 0666); err != nil {
+		log.Printf("%s generate failed: %v", filename, err)
+	}
+}
+
+func methodOf(t types.Type, name string) *types.Func {
+	obj, _, _ := types.LookupFieldOrMethod(t, true, nil, name)
+	fn, _ := obj.(*types.Func)
+	return fn
+}
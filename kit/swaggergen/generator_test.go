@@ -0,0 +1,70 @@
+package swaggergen_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/saitofun/qkit/kit/oas"
+	"github.com/saitofun/qkit/kit/swaggergen"
+	"github.com/saitofun/qkit/x/pkgx"
+)
+
+func TestGenerator(t *testing.T) {
+	cwd, _ := os.Getwd()
+	dir := filepath.Join(cwd, "../../testutil/httptransporttestutil/server/cmd/app")
+
+	pkg, err := pkgx.LoadFrom(dir)
+	NewWithT(t).Expect(err).To(BeNil())
+
+	g := swaggergen.New(pkg)
+	g.Scan("RootRouter")
+
+	doc := g.Doc()
+
+	t.Run("Paths", func(t *testing.T) {
+		NewWithT(t).Expect(doc.Paths).To(HaveKey("/demo/restful/{id}"))
+		item := doc.Paths["/demo/restful/{id}"]
+		NewWithT(t).Expect(item).To(HaveKey("get"))
+		NewWithT(t).Expect(item["get"].OperationID).To(Equal("GetByID"))
+	})
+
+	t.Run("Parameters", func(t *testing.T) {
+		op := doc.Paths["/demo/restful/{id}"]["get"]
+		NewWithT(t).Expect(op.Parameters[0].Name).To(Equal("id"))
+		NewWithT(t).Expect(op.Parameters[0].In).To(Equal("path"))
+		NewWithT(t).Expect(op.Parameters[0].Required).To(BeTrue())
+		NewWithT(t).Expect(op.Parameters[0].Schema.Validate).To(Equal("@string[6,]"))
+	})
+
+	t.Run("Responses", func(t *testing.T) {
+		op := doc.Paths["/demo/restful/{id}"]["get"]
+		NewWithT(t).Expect(op.Responses).To(HaveKey("200"))
+		NewWithT(t).Expect(doc.Components.Schemas).To(HaveKey("Data"))
+	})
+
+	t.Run("StatusErrors", func(t *testing.T) {
+		op := doc.Paths["/demo/restful/{id}"]["delete"]
+		NewWithT(t).Expect(op.Responses).To(HaveKey("500"))
+		NewWithT(t).Expect(op.Responses).To(HaveKey("401"))
+	})
+
+	t.Run("Output", func(t *testing.T) {
+		tmp := t.TempDir()
+		g.Output(tmp)
+
+		data, err := os.ReadFile(filepath.Join(tmp, swaggergen.Filename))
+		NewWithT(t).Expect(err).To(BeNil())
+
+		expect, err := json.MarshalIndent(doc, "", "  ")
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(data)).To(Equal(string(expect)))
+
+		output := oas.NewOpenAPI()
+		NewWithT(t).Expect(json.Unmarshal(data, output)).To(BeNil())
+		NewWithT(t).Expect(output.Paths).To(HaveKey("/demo/restful/{id}"))
+	})
+}
@@ -0,0 +1,333 @@
+package swaggergen
+
+import (
+	"context"
+	"go/constant"
+	"go/types"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/saitofun/qkit/kit/enumgen"
+	"github.com/saitofun/qkit/kit/httptransport/httpx"
+	"github.com/saitofun/qkit/kit/httptransport/transformer"
+	"github.com/saitofun/qkit/kit/oas"
+	"github.com/saitofun/qkit/kit/statusx"
+	"github.com/saitofun/qkit/x/pkgx"
+	"github.com/saitofun/qkit/x/typesx"
+)
+
+func NewOperatorScanner(pkg *pkgx.Pkg, doc *oas.OpenAPI) *OperatorScanner {
+	s := &OperatorScanner{
+		pkg:       pkg,
+		builder:   oas.NewBuilder(doc),
+		enums:     enumgen.NewScanner(pkg),
+		errs:      NewStatusErrScanner(pkg),
+		operators: map[types.Type]*Operator{},
+	}
+	s.builder.Enum = s.enum
+	s.builder.Describe = s.describe
+	return s
+}
+
+type OperatorScanner struct {
+	pkg       *pkgx.Pkg
+	builder   *oas.Builder
+	enums     *enumgen.Scanner
+	errs      *StatusErrScanner
+	operators map[types.Type]*Operator
+}
+
+// Builder returns schema builder registering components into scanned doc
+func (s *OperatorScanner) Builder() *oas.Builder { return s.builder }
+
+// Operator is the static mirror of httptransport.RouteMeta
+type Operator struct {
+	s *OperatorScanner
+
+	Type        types.Type
+	ID          string
+	Method      string
+	Path        string
+	BasePath    string
+	Summary     string
+	Description string
+	Deprecated  bool
+}
+
+func (s *OperatorScanner) Operator(t types.Type) *Operator {
+	if t == nil {
+		return nil
+	}
+	if op, ok := s.operators[t]; ok {
+		return op
+	}
+
+	typ := t
+	if p, ok := typ.(*types.Pointer); ok {
+		typ = p.Elem()
+	}
+	named, ok := typ.(*types.Named)
+	if !ok {
+		return nil
+	}
+
+	op := &Operator{s: s, Type: typ, ID: named.Obj().Name()}
+	s.operators[t] = op
+
+	op.Method = s.constString(methodOf(t, "Method"))
+	op.BasePath = s.constString(methodOf(t, "BasePath"))
+	op.Path = s.constString(methodOf(t, "Path"))
+
+	if st, ok := named.Underlying().(*types.Struct); ok {
+		for i := 0; i < st.NumFields(); i++ {
+			f := st.Field(i)
+			ft := f.Type()
+			n, ok := ft.(*types.Named)
+			if !f.Anonymous() || !ok || n.Obj().Pkg() == nil ||
+				n.Obj().Pkg().Path() != PkgHttpx ||
+				!strings.HasPrefix(f.Name(), "Method") {
+				continue
+			}
+			tag := reflect.StructTag(st.Tag(i))
+			if path, ok := tag.Lookup("path"); ok {
+				vs := strings.Split(path, ",")
+				op.Path = vs[0]
+				for _, flag := range vs[1:] {
+					if flag == "deprecated" {
+						op.Deprecated = true
+					}
+				}
+			}
+			if basePath, ok := tag.Lookup("basePath"); ok {
+				op.BasePath = basePath
+			}
+			if summary, ok := tag.Lookup("summary"); ok {
+				op.Summary = summary
+			}
+			break
+		}
+	}
+
+	if obj := named.Obj(); s.pkg.FileOf(obj) != nil {
+		lines := strings.Split(
+			strings.TrimSpace(s.pkg.CommentsOf(s.pkg.IdentOf(obj))), "\n",
+		)
+		if op.Summary == "" {
+			op.Summary = lines[0]
+		}
+		if len(lines) > 1 {
+			op.Description = strings.TrimSpace(strings.Join(lines[1:], "\n"))
+		}
+	}
+
+	return op
+}
+
+// BindParameters binds parameters and request body of operator to operation
+func (op *Operator) BindParameters(operation *oas.Operation) {
+	ctx := context.Background()
+	t := typesx.FromGoType(op.Type)
+	if t.Kind() != reflect.Struct {
+		return
+	}
+
+	transformer.EachParameter(ctx, t, func(p *transformer.Param) bool {
+		switch p.In {
+		case "":
+		case "body":
+			operation.RequestBody = op.s.builder.RequestBody(ctx, p)
+		default:
+			operation.Parameters = append(operation.Parameters, op.s.builder.Parameter(t, p))
+		}
+		return true
+	})
+}
+
+// BindResponses binds success response from the return types of `Output`,
+// and error responses from status errors may be returned by `Output`
+func (op *Operator) BindResponses(method string, operation *oas.Operation) {
+	output := methodOf(op.Type, "Output")
+	if output == nil {
+		output = methodOf(types.NewPointer(op.Type), "Output")
+	}
+	if output == nil {
+		return
+	}
+
+	results, n := op.s.pkg.FuncResultsOf(output)
+	untyped, responded := false, false
+	if n > 0 {
+		for _, tve := range results[0] {
+			if tve.Type == nil || tve.IsNil() {
+				continue
+			}
+			// unresolved interface or response composed by httpx wrappers
+			if _, ok := tve.Type.Underlying().(*types.Interface); ok || isHttpxResponse(tve.Type) {
+				untyped = true
+				continue
+			}
+			code, rsp := op.s.response(method, tve.Type)
+			operation.AddResponse(code, rsp)
+			responded = true
+		}
+	}
+	if !responded {
+		if untyped {
+			operation.AddResponse(
+				oas.DefaultStatusCode(method, true),
+				&oas.Response{Description: "untyped response"},
+			)
+		} else {
+			operation.AddResponse(oas.DefaultStatusCode(method, false), &oas.Response{})
+		}
+	}
+
+	errs := map[int][]*statusx.StatusErr{}
+	for _, e := range op.s.errs.StatusErrorsOf(output) {
+		code := statusx.StatusCodeFromCode(e.Code)
+		errs[code] = append(errs[code], e)
+	}
+	for code, list := range errs {
+		summaries := make([]string, 0, len(list))
+		for _, e := range list {
+			summaries = append(summaries, e.Summary())
+		}
+		operation.AddResponse(code, &oas.Response{
+			Description: strings.Join(summaries, "\n"),
+			Content: map[string]*oas.MediaType{
+				httpx.MIME_JSON: {Schema: op.s.statusErr()},
+			},
+		})
+	}
+}
+
+func (s *OperatorScanner) response(method string, t types.Type) (int, *oas.Response) {
+	code := s.constInt(methodOf(t, "StatusCode"))
+
+	if methodOf(t, "Location") != nil {
+		if code == 0 {
+			code = http.StatusFound
+		}
+		return code, &oas.Response{}
+	}
+
+	if code == 0 {
+		code = oas.DefaultStatusCode(method, true)
+	}
+
+	if fn := methodOf(t, "ContentType"); fn != nil {
+		contentType := s.constString(fn)
+		if contentType == "" {
+			contentType = httpx.MIME_OCTET_STREAM
+		}
+		return code, &oas.Response{
+			Content: map[string]*oas.MediaType{
+				contentType: {Schema: oas.String("binary")},
+			},
+		}
+	}
+
+	return code, &oas.Response{
+		Content: map[string]*oas.MediaType{
+			httpx.MIME_JSON: {Schema: s.builder.Schema(typesx.FromGoType(t))},
+		},
+	}
+}
+
+func isHttpxResponse(t types.Type) bool {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	n, ok := t.(*types.Named)
+	return ok && n.Obj().Pkg() != nil &&
+		n.Obj().Pkg().Path() == PkgHttpx && n.Obj().Name() == "Response"
+}
+
+func (s *OperatorScanner) statusErr() *oas.Schema {
+	return s.builder.Schema(typesx.FromReflectType(reflect.TypeOf(statusx.StatusErr{})))
+}
+
+// enum resolves enum values from constants declared with the named type which
+// implements ConstValues, as generated by enumgen
+func (s *OperatorScanner) enum(t typesx.Type) ([]interface{}, []string, bool) {
+	gt, ok := t.(*typesx.GoType)
+	if !ok {
+		return nil, nil, false
+	}
+	named, ok := gt.Type.(*types.Named)
+	if !ok || methodOf(named, "ConstValues") == nil {
+		return nil, nil, false
+	}
+	options, ok := s.enums.Options(named.Obj())
+	if !ok {
+		return nil, nil, false
+	}
+	values, labels := make([]interface{}, 0, len(options)), make([]string, 0, len(options))
+	for _, o := range options {
+		values = append(values, o.Value())
+		labels = append(labels, o.Label)
+	}
+	return values, labels, true
+}
+
+// describe returns doc comments of named type or struct field
+func (s *OperatorScanner) describe(t typesx.Type, field string) string {
+	gt, ok := t.(*typesx.GoType)
+	if !ok {
+		return ""
+	}
+	named, ok := gt.Type.(*types.Named)
+	if !ok {
+		return ""
+	}
+	var obj types.Object = named.Obj()
+	if field != "" {
+		st, ok := named.Underlying().(*types.Struct)
+		if !ok {
+			return ""
+		}
+		obj = nil
+		for i := 0; i < st.NumFields(); i++ {
+			if st.Field(i).Name() == field {
+				obj = st.Field(i)
+				break
+			}
+		}
+	}
+	if obj == nil || obj.Pkg() == nil || s.pkg.FileOf(obj) == nil {
+		return ""
+	}
+	if id := s.pkg.IdentOf(obj); id != nil {
+		return strings.TrimSpace(s.pkg.CommentsOf(id))
+	}
+	return ""
+}
+
+func (s *OperatorScanner) constString(fn *types.Func) string {
+	if v := s.constValue(fn); v != nil && v.Kind() == constant.String {
+		return constant.StringVal(v)
+	}
+	return ""
+}
+
+func (s *OperatorScanner) constInt(fn *types.Func) int {
+	if v := s.constValue(fn); v != nil && v.Kind() == constant.Int {
+		i, _ := strconv.Atoi(v.ExactString())
+		return i
+	}
+	return 0
+}
+
+// constValue returns the constant value if fn always returns one
+func (s *OperatorScanner) constValue(fn *types.Func) constant.Value {
+	if fn == nil || s.pkg.FileOf(fn) == nil {
+		return nil
+	}
+	results, n := s.pkg.FuncResultsOf(fn)
+	if n != 1 || len(results[0]) != 1 || results[0][0].Value == nil {
+		return nil
+	}
+	return results[0][0].Value
+}
@@ -0,0 +1,224 @@
+package swaggergen
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"strconv"
+
+	"github.com/saitofun/qkit/x/pkgx"
+)
+
+// Router is the static mirror of kit.Router built from source
+type Router struct {
+	parent    *Router
+	Operators []*RouterOperator
+	children  map[*Router]bool
+}
+
+// RouterOperator operator registered by kit.NewRouter
+type RouterOperator struct {
+	Type types.Type
+	// Path and BasePath are set when operator is created by
+	// httptransport.Group or httptransport.BasePath
+	Path     string
+	BasePath string
+}
+
+func (r *Router) Register(child *Router) {
+	if r.children == nil {
+		r.children = map[*Router]bool{}
+	}
+	child.parent = r
+	r.children[child] = true
+}
+
+// Routes returns operator chains from root to each leaf router
+func (r *Router) Routes() (routes [][]*RouterOperator) {
+	if len(r.children) == 0 {
+		ops := r.Operators
+		for p := r.parent; p != nil; p = p.parent {
+			ops = append(append([]*RouterOperator{}, p.Operators...), ops...)
+		}
+		if len(ops) > 0 {
+			routes = append(routes, ops)
+		}
+		return
+	}
+	for child := range r.children {
+		routes = append(routes, child.Routes()...)
+	}
+	return
+}
+
+func NewRouterScanner(pkg *pkgx.Pkg) *RouterScanner {
+	s := &RouterScanner{
+		pkg:     pkg,
+		routers: map[types.Object]*Router{},
+	}
+	s.init()
+	return s
+}
+
+type RouterScanner struct {
+	pkg     *pkgx.Pkg
+	routers map[types.Object]*Router
+}
+
+// Router returns the router declared as variable named `name`, variables in
+// the scanned package take precedence over the imported ones
+func (s *RouterScanner) Router(name string) *Router {
+	if v := s.pkg.Var(name); v != nil {
+		if r, ok := s.routers[v]; ok {
+			return r
+		}
+	}
+	for obj, r := range s.routers {
+		if _, ok := obj.(*types.Var); ok && obj.Name() == name &&
+			obj.Parent() == obj.Pkg().Scope() {
+			return r
+		}
+	}
+	return nil
+}
+
+func (s *RouterScanner) init() {
+	// declarations first, registrations may refer routers declared in other
+	// packages or files
+	for _, pkg := range s.pkg.Imports() {
+		for _, file := range pkg.Syntax {
+			ast.Inspect(file, func(node ast.Node) bool {
+				switch n := node.(type) {
+				case *ast.ValueSpec:
+					for i := range n.Names {
+						if i < len(n.Values) {
+							s.declare(pkg.TypesInfo, n.Names[i], n.Values[i])
+						}
+					}
+				case *ast.AssignStmt:
+					if len(n.Lhs) != len(n.Rhs) {
+						return true
+					}
+					for i := range n.Lhs {
+						if id, ok := n.Lhs[i].(*ast.Ident); ok {
+							s.declare(pkg.TypesInfo, id, n.Rhs[i])
+						}
+					}
+				}
+				return true
+			})
+		}
+	}
+
+	for _, pkg := range s.pkg.Imports() {
+		for _, file := range pkg.Syntax {
+			ast.Inspect(file, func(node ast.Node) bool {
+				call, ok := node.(*ast.CallExpr)
+				if !ok || len(call.Args) != 1 {
+					return true
+				}
+				sel, ok := call.Fun.(*ast.SelectorExpr)
+				if !ok || sel.Sel.Name != "Register" || !isRouter(pkg.TypesInfo.TypeOf(sel.X)) {
+					return true
+				}
+				parent := s.routerOf(pkg.TypesInfo, sel.X)
+				child := s.routerOf(pkg.TypesInfo, call.Args[0])
+				if parent != nil && child != nil {
+					parent.Register(child)
+				}
+				return true
+			})
+		}
+	}
+}
+
+func (s *RouterScanner) declare(info *types.Info, id *ast.Ident, expr ast.Expr) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || !isNewRouter(info, call) {
+		return
+	}
+	obj := info.ObjectOf(id)
+	if obj == nil {
+		return
+	}
+	r := s.router(obj)
+	r.Operators = s.operators(info, call)
+}
+
+func (s *RouterScanner) router(obj types.Object) *Router {
+	if r, ok := s.routers[obj]; ok {
+		return r
+	}
+	r := &Router{}
+	s.routers[obj] = r
+	return r
+}
+
+func (s *RouterScanner) routerOf(info *types.Info, expr ast.Expr) *Router {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if obj := info.ObjectOf(e); obj != nil {
+			return s.router(obj)
+		}
+	case *ast.SelectorExpr:
+		if obj := info.ObjectOf(e.Sel); obj != nil {
+			return s.router(obj)
+		}
+	case *ast.CallExpr:
+		if isNewRouter(info, e) {
+			return &Router{Operators: s.operators(info, e)}
+		}
+	}
+	return nil
+}
+
+func (s *RouterScanner) operators(info *types.Info, call *ast.CallExpr) []*RouterOperator {
+	ops := make([]*RouterOperator, 0, len(call.Args))
+	for _, arg := range call.Args {
+		op := &RouterOperator{Type: info.TypeOf(arg)}
+		if c, ok := arg.(*ast.CallExpr); ok && len(c.Args) == 1 {
+			if fn := funcOf(info, c.Fun); fn != nil && fn.Pkg() != nil &&
+				fn.Pkg().Path() == PkgHttpTransport {
+				v := info.Types[c.Args[0]].Value
+				if v != nil && v.Kind() == constant.String {
+					str, _ := strconv.Unquote(v.ExactString())
+					switch fn.Name() {
+					case "Group":
+						op.Path = str
+					case "BasePath":
+						op.BasePath = str
+					}
+				}
+			}
+		}
+		ops = append(ops, op)
+	}
+	return ops
+}
+
+func funcOf(info *types.Info, expr ast.Expr) *types.Func {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		fn, _ := info.ObjectOf(e).(*types.Func)
+		return fn
+	case *ast.SelectorExpr:
+		fn, _ := info.ObjectOf(e.Sel).(*types.Func)
+		return fn
+	}
+	return nil
+}
+
+func isNewRouter(info *types.Info, call *ast.CallExpr) bool {
+	fn := funcOf(info, call.Fun)
+	return fn != nil && fn.Pkg() != nil &&
+		fn.Pkg().Path() == PkgKit && fn.Name() == "NewRouter"
+}
+
+func isRouter(t types.Type) bool {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	n, ok := t.(*types.Named)
+	return ok && n.Obj().Pkg() != nil &&
+		n.Obj().Pkg().Path() == PkgKit && n.Obj().Name() == "Router"
+}
@@ -0,0 +1,128 @@
+package swaggergen
+
+import (
+	"go/ast"
+	"go/types"
+	"sort"
+	"strings"
+
+	"github.com/saitofun/qkit/kit/statusx"
+	"github.com/saitofun/qkit/kit/statusxgen"
+	"github.com/saitofun/qkit/x/pkgx"
+)
+
+func NewStatusErrScanner(pkg *pkgx.Pkg) *StatusErrScanner {
+	return &StatusErrScanner{
+		pkg:     pkg,
+		scanner: statusxgen.NewScanner(pkg),
+		errs:    map[*types.Func][]*statusx.StatusErr{},
+	}
+}
+
+// StatusErrScanner collects status errors may be returned by function
+type StatusErrScanner struct {
+	pkg     *pkgx.Pkg
+	scanner *statusxgen.Scanner
+	errs    map[*types.Func][]*statusx.StatusErr
+}
+
+// StatusErrorsOf returns status errors referenced in fn's body and the
+// functions it calls, and declared by `@StatusErr[Key][Code][Msg]` comments
+func (s *StatusErrScanner) StatusErrorsOf(fn *types.Func) []*statusx.StatusErr {
+	if fn == nil {
+		return nil
+	}
+	if errs, ok := s.errs[fn]; ok {
+		return errs
+	}
+	// avoid recursion
+	s.errs[fn] = nil
+
+	errs := map[string]*statusx.StatusErr{}
+	add := func(list ...*statusx.StatusErr) {
+		for _, e := range list {
+			errs[e.Summary()] = e
+		}
+	}
+
+	add(s.fromComments(fn)...)
+
+	if s.traceable(fn.Pkg().Path()) {
+		if decl := s.pkg.FuncDeclOf(fn); decl != nil && decl.Body != nil {
+			info := s.pkg.PkgInfoOf(decl)
+			ast.Inspect(decl.Body, func(node ast.Node) bool {
+				switch n := node.(type) {
+				case *ast.Ident:
+					if c, ok := info.ObjectOf(n).(*types.Const); ok {
+						add(s.fromConst(c)...)
+					}
+				case *ast.CallExpr:
+					if callee := funcOf(info, n.Fun); callee != nil && callee.Pkg() != nil {
+						add(s.StatusErrorsOf(callee)...)
+					}
+				}
+				return true
+			})
+		}
+	}
+
+	list := make([]*statusx.StatusErr, 0, len(errs))
+	for _, e := range errs {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Code < list[j].Code
+	})
+	s.errs[fn] = list
+	return list
+}
+
+// traceable if function declared in package of path should be traced, std
+// packages are skipped
+func (s *StatusErrScanner) traceable(path string) bool {
+	if s.pkg.PkgByPath(path) == nil {
+		return false
+	}
+	return strings.Contains(strings.Split(path, "/")[0], ".")
+}
+
+func (s *StatusErrScanner) fromComments(fn *types.Func) []*statusx.StatusErr {
+	if s.pkg.FileOf(fn) == nil {
+		return nil
+	}
+	decl := s.pkg.FuncDeclOf(fn)
+	if decl == nil {
+		return nil
+	}
+	list := make([]*statusx.StatusErr, 0)
+	for _, line := range strings.Split(s.pkg.CommentsOf(decl), "\n") {
+		if e, err := statusx.ParseStatusErrSummary(line); err == nil {
+			list = append(list, e)
+		}
+	}
+	return list
+}
+
+func (s *StatusErrScanner) fromConst(c *types.Const) []*statusx.StatusErr {
+	named, ok := c.Type().(*types.Named)
+	if !ok || !isStatusError(named) {
+		return nil
+	}
+	for _, e := range s.scanner.StatusError(named.Obj()) {
+		if e.Key == c.Name() {
+			return []*statusx.StatusErr{e}
+		}
+	}
+	return nil
+}
+
+// isStatusError if t implements statusx.Error
+func isStatusError(t types.Type) bool {
+	for _, name := range []string{"StatusErr", "Error"} {
+		obj, _, _ := types.LookupFieldOrMethod(t, true, nil, name)
+		if _, ok := obj.(*types.Func); !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -2,6 +2,8 @@ package retry
 
 import (
 	"log"
+	"math"
+	"math/rand"
 	"time"
 
 	"github.com/saitofun/qkit/base/types"
@@ -10,6 +12,13 @@ import (
 type Retry struct {
 	Repeats  int
 	Interval types.Duration
+	// Multiplier of interval for each next attempt, constant interval if
+	// Multiplier <= 1
+	Multiplier float64
+	// MaxInterval limits interval grown by Multiplier
+	MaxInterval types.Duration
+	// Jitter randomizes interval in [interval*(1-Jitter), interval*(1+Jitter)]
+	Jitter float64
 }
 
 func (r *Retry) SetDefault() {
@@ -21,14 +30,32 @@ func (r *Retry) SetDefault() {
 	}
 }
 
+// Wait returns interval before next attempt after attempt failed, attempt
+// starts from 0
+func (r Retry) Wait(attempt int) time.Duration {
+	d := float64(r.Interval)
+	if r.Multiplier > 1 {
+		d *= math.Pow(r.Multiplier, float64(attempt))
+	}
+	if r.MaxInterval > 0 && d > float64(r.MaxInterval) {
+		d = float64(r.MaxInterval)
+	}
+	if r.Jitter > 0 {
+		j := math.Min(r.Jitter, 1)
+		d *= 1 - j + 2*j*rand.Float64()
+	}
+	return time.Duration(d)
+}
+
 func (r Retry) Do(exec func() error) (err error) {
 	if r.Repeats <= 0 {
 		return exec()
 	}
 	for i := 0; i < r.Repeats; i++ {
 		if err = exec(); err != nil {
-			log.Printf("retry in seconds %s [err: %v]", r.Interval.Duration(), err)
-			time.Sleep(time.Duration(r.Interval))
+			wait := r.Wait(i)
+			log.Printf("retry in seconds %s [err: %v]", wait, err)
+			time.Sleep(wait)
 			continue
 		}
 		break
@@ -36,7 +63,7 @@ func (r Retry) Do(exec func() error) (err error) {
 	return
 }
 
-var Default = &Retry{3, types.Duration(3 * time.Second)}
+var Default = &Retry{Repeats: 3, Interval: types.Duration(3 * time.Second)}
 
 func Do(retry *Retry, exec func() error) error {
 	return retry.Do(exec)
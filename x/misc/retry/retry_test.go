@@ -0,0 +1,33 @@
+package retry_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/saitofun/qkit/base/types"
+	"github.com/saitofun/qkit/x/misc/retry"
+)
+
+func TestRetry_Wait(t *testing.T) {
+	r := retry.Retry{
+		Repeats:     5,
+		Interval:    types.Duration(10 * time.Millisecond),
+		Multiplier:  2,
+		MaxInterval: types.Duration(50 * time.Millisecond),
+	}
+
+	NewWithT(t).Expect(r.Wait(0)).To(Equal(10 * time.Millisecond))
+	NewWithT(t).Expect(r.Wait(1)).To(Equal(20 * time.Millisecond))
+	NewWithT(t).Expect(r.Wait(2)).To(Equal(40 * time.Millisecond))
+	NewWithT(t).Expect(r.Wait(3)).To(Equal(50 * time.Millisecond))
+
+	r.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		NewWithT(t).Expect(r.Wait(0)).To(And(
+			BeNumerically(">=", 5*time.Millisecond),
+			BeNumerically("<=", 15*time.Millisecond),
+		))
+	}
+}
@@ -1,6 +1,7 @@
 package pkgx
 
 import (
+	"fmt"
 	"go/ast"
 	"go/token"
 	"go/types"
@@ -35,6 +36,32 @@ func LoadFrom(pattern string) (*Pkg, error) {
 	return New(lst[0]), nil
 }
 
+// LoadAll loads packages matched by patterns in one type checking universe,
+// the first package is the root and all packages are visible as imports
+func LoadAll(patterns ...string) (*Pkg, error) {
+	lst, err := Load(
+		&Config{Mode: LoadMode(0b111111111111)},
+		patterns...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(lst) == 0 {
+		return nil, fmt.Errorf("no package matched %v", patterns)
+	}
+	imports := &Set{}
+	for _, pkg := range lst {
+		if len(pkg.Errors) > 0 {
+			return nil, pkg.Errors[0]
+		}
+		imports.Append(pkg)
+	}
+	return &Pkg{
+		Package: lst[0],
+		imports: imports.List(),
+	}, nil
+}
+
 func New(pkg *Package) *Pkg {
 	imports := &Set{}
 	imports.Append(pkg)